@@ -0,0 +1,137 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	errorCodeInvalidHeaderValue = "InvalidHeaderValue"
+	errorCodeInvalidHeader      = "MissingOrInvalidRequiredHeader"
+)
+
+// apiVersionState holds the x-ms-version sent by a client. It is shared by
+// all copies of a Client and is safe for concurrent use.
+type apiVersionState struct {
+	fallbacks []string
+
+	mu      sync.RWMutex
+	current string
+
+	// overridden is set when the version was chosen by the user or
+	// negotiated with the service, rather than defaulted, so that it takes
+	// precedence over the versions required by operations.
+	overridden bool
+}
+
+func newAPIVersionState(version string, fallbacks []string) *apiVersionState {
+	overridden := version != ""
+	if version == "" {
+		version = msVersionHeaderValue
+	}
+
+	return &apiVersionState{
+		fallbacks:  append([]string(nil), fallbacks...),
+		current:    version,
+		overridden: overridden,
+	}
+}
+
+//get returns the version to send for an operation that requires the given
+//version, which may be empty.
+func (state *apiVersionState) get(required string) string {
+	if state == nil {
+		if required != "" {
+			return required
+		}
+		return msVersionHeaderValue
+	}
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	if required != "" && !state.overridden {
+		return required
+	}
+	return state.current
+}
+
+func (state *apiVersionState) set(version string) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.current = version
+	state.overridden = true
+}
+
+// EffectiveAPIVersion returns the x-ms-version the client sends. It is the
+// configured APIVersion, unless the service rejected it and a fallback
+// version was negotiated, or it was changed with SetAPIVersion. Without any
+// of those, it is the version required with WithAPIVersion, if any, or the
+// version the package was written against.
+func (client *Client) EffectiveAPIVersion() string {
+	return client.apiVersion.get(client.requiredAPIVersion)
+}
+
+// WithAPIVersion returns a copy of the client whose requests send version as
+// x-ms-version, unless the user chose a version for the client with
+// ClientConfig.APIVersion or SetAPIVersion. The service sub-packages use it
+// to declare the version their operations are written against, and single
+// operations needing a newer version can use it too:
+//
+//	client := self.client.WithAPIVersion("2014-10-01")
+//	response, err := client.SendAzureGetRequest(requestURL)
+func (client Client) WithAPIVersion(version string) Client {
+	client.requiredAPIVersion = version
+	return client
+}
+
+// SetAPIVersion changes the x-ms-version sent by the client and all its
+// copies, replacing any negotiated version and the versions required by
+// operations.
+func (client *Client) SetAPIVersion(version string) {
+	if client.apiVersion == nil || version == "" {
+		return
+	}
+
+	client.apiVersion.set(version)
+}
+
+// IsVersionNotSupported reports whether err, or any error it wraps, is an
+// AzureError saying that the x-ms-version sent with the request is not
+// supported by the service.
+func IsVersionNotSupported(err error) bool {
+	if !hasErrorCode(err, errorCodeInvalidHeaderValue) && !hasErrorCode(err, errorCodeInvalidHeader) {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(err.Error()), msVersionHeader)
+}
+
+// negotiateAPIVersion resends a request whose API version was rejected with
+// each of the configured fallback versions, newest first. The first version
+// the service accepts is cached for the subsequent requests of the client.
+// If there are no fallbacks, or the service rejects all of them, rejectedErr
+// is returned.
+func (client *Client) negotiateAPIVersion(httpClient *http.Client, url string, requestType string, contentType string, data []byte, stream bool, rejected string, rejectedErr error) (*AzureResponse, error) {
+	if client.apiVersion == nil {
+		return nil, rejectedErr
+	}
+
+	for _, version := range client.apiVersion.fallbacks {
+		if version == rejected {
+			continue
+		}
+
+		response, err := client.sendRequest(httpClient, url, requestType, contentType, version, data, stream)
+		if IsVersionNotSupported(err) {
+			continue
+		}
+
+		client.apiVersion.set(version)
+		client.log().Warn("API version rejected by the service, switched to a fallback version",
+			"rejected", rejected, "version", version)
+		return response, err
+	}
+
+	return nil, rejectedErr
+}
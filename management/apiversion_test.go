@@ -0,0 +1,142 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordingLogger struct {
+	nopLogger
+
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprint(append([]interface{}{msg}, keyvals...)...))
+}
+
+// newVersionServer accepts only the given API version and records the
+// versions of the requests it receives.
+func newVersionServer(accepted string, versions *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := r.Header.Get("x-ms-version")
+		mu.Lock()
+		*versions = append(*versions, version)
+		mu.Unlock()
+
+		body := "<Locations/>"
+		status := http.StatusOK
+		if version != accepted {
+			body = `<Error><Code>InvalidHeaderValue</Code><Message>The value for the header x-ms-version is not supported.</Message></Error>`
+			status = http.StatusBadRequest
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestAPIVersionFallbackIsNegotiatedAndCached(t *testing.T) {
+	var versions []string
+	server := newVersionServer("2015-04-01", &versions)
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := newTestClient(t, server.URL, ClientConfig{
+		FallbackAPIVersions: []string{"2015-04-01", "2014-10-01"},
+		Logger:              logger,
+	})
+
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0] != msVersionHeaderValue || versions[1] != "2015-04-01" {
+		t.Fatalf("Expected exactly two attempts with %s then 2015-04-01, got %v", msVersionHeaderValue, versions)
+	}
+	if version := client.EffectiveAPIVersion(); version != "2015-04-01" {
+		t.Errorf("Expected the negotiated version to be cached, got %s", version)
+	}
+	if len(logger.warnings) != 1 {
+		t.Errorf("Expected one warning, got %v", logger.warnings)
+	}
+
+	copied := client
+	if _, err := copied.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[2] != "2015-04-01" {
+		t.Errorf("Expected the next request to use the cached version, got %v", versions)
+	}
+}
+
+func TestAPIVersionRejectedWithoutFallback(t *testing.T) {
+	var versions []string
+	server := newVersionServer("2015-04-01", &versions)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+
+	_, err := client.SendAzureGetRequest("locations")
+	if !IsVersionNotSupported(err) {
+		t.Fatalf("Expected a version not supported error, got %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("Expected a rejected version not to be retried, got %v", versions)
+	}
+}
+
+func TestSetAPIVersionOverridesNegotiatedVersion(t *testing.T) {
+	var versions []string
+	server := newVersionServer("2016-01-01", &versions)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{APIVersion: "2015-04-01"})
+	if version := client.EffectiveAPIVersion(); version != "2015-04-01" {
+		t.Fatalf("Expected the configured version, got %s", version)
+	}
+
+	client.SetAPIVersion("2016-01-01")
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if versions[0] != "2016-01-01" {
+		t.Errorf("Expected the overridden version to be sent, got %v", versions)
+	}
+}
+
+func TestWithAPIVersionIsOverriddenByClientVersion(t *testing.T) {
+	var versions []string
+	server := newVersionServer("2014-10-01", &versions)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	operation := client.WithAPIVersion("2014-10-01")
+	if _, err := operation.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if client.EffectiveAPIVersion() != msVersionHeaderValue {
+		t.Errorf("Expected WithAPIVersion to leave the original client alone, got %s", client.EffectiveAPIVersion())
+	}
+
+	configured := newTestClient(t, server.URL, ClientConfig{APIVersion: "2015-04-01"})
+	configured = configured.WithAPIVersion("2014-10-01")
+	if version := configured.EffectiveAPIVersion(); version != "2015-04-01" {
+		t.Errorf("Expected the configured version to take precedence, got %s", version)
+	}
+
+	client.SetAPIVersion("2015-04-01")
+	if version := operation.EffectiveAPIVersion(); version != "2015-04-01" {
+		t.Errorf("Expected SetAPIVersion to take precedence, got %s", version)
+	}
+
+	if len(versions) != 1 || versions[0] != "2014-10-01" {
+		t.Errorf("Expected one request with the required version, got %v", versions)
+	}
+}
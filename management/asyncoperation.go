@@ -0,0 +1,198 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrOperationInProgress is returned by Operation.Result while the operation
+// has not completed.
+var ErrOperationInProgress = errors.New("azure: operation is still in progress")
+
+// Operation is a handle on an asynchronous operation, so that several
+// operations can be started and joined on later:
+//
+//	first, err := client.StartOperation(url1, "POST", "", data1)
+//	...
+//	second, err := client.StartOperation(url2, "POST", "", data2)
+//	...
+//	err = first.Wait(ctx)
+//	...
+//	err = second.Wait(ctx)
+//
+// The operation is waited on in the background, with WaitAsyncOperation, as
+// soon as Done, Wait or Result is first called. An Operation is safe for
+// concurrent use.
+type Operation struct {
+	ID string
+
+	client  Client
+	options []WaitOption
+
+	start sync.Once
+	done  chan struct{}
+	err   error
+}
+
+// NewOperation returns a handle on the operation with the given ID, for
+// example a request ID returned by SendAzurePostRequest. The options control
+// the wait for the operation, as in WaitAsyncOperation.
+func (client *Client) NewOperation(operationID string, options ...WaitOption) *Operation {
+	return &Operation{
+		ID:      operationID,
+		client:  *client,
+		options: options,
+		done:    make(chan struct{}),
+	}
+}
+
+// StartOperation sends a request to the management API that starts an
+// asynchronous operation, and returns a handle on it. An operation the
+// service completed synchronously, answering with another status than 202
+// Accepted, is returned already done.
+func (client *Client) StartOperation(url string, requestType string, contentType string, data []byte, options ...WaitOption) (*Operation, error) {
+	if url == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "url")
+	}
+
+	response, err := client.SendAzureRequest(url, requestType, contentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := client.NewOperation(response.RequestID, options...)
+	if response.StatusCode != http.StatusAccepted {
+		operation.start.Do(func() { close(operation.done) })
+	} else if response.RequestID == "" {
+		return nil, fmt.Errorf(errNoRequestID, requestType, url)
+	}
+
+	return operation, nil
+}
+
+// Status returns the current status of the operation, one of InProgress,
+// Succeeded or Failed, checking it with the service unless the operation is
+// known to have completed.
+func (operation *Operation) Status() (string, error) {
+	select {
+	case <-operation.done:
+		var abortedErr *AbortedOperationError
+		if operation.err == nil {
+			return "Succeeded", nil
+		} else if !errors.As(operation.err, &abortedErr) {
+			return "Failed", nil
+		}
+	default:
+	}
+
+	status, err := operation.client.getOperationStatus(operation.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return status.Status, nil
+}
+
+// Done returns a channel that is closed when the operation completes, or its
+// wait is aborted.
+func (operation *Operation) Done() <-chan struct{} {
+	operation.start.Do(func() {
+		go func() {
+			operation.err = operation.client.WaitAsyncOperation(operation.ID, operation.options...)
+			close(operation.done)
+		}()
+	})
+
+	return operation.done
+}
+
+// Wait blocks until the operation completes and returns its result. If ctx
+// is done first, an *AbortedOperationError is returned, and the operation
+// keeps being waited on in the background.
+func (operation *Operation) Wait(ctx context.Context) error {
+	select {
+	case <-operation.Done():
+		return operation.err
+	case <-ctx.Done():
+		return &AbortedOperationError{OperationID: operation.ID, Err: ctx.Err()}
+	}
+}
+
+// Result returns the result of the operation once it completed: nil if it
+// succeeded, or the error of WaitAsyncOperation. Before that, it returns
+// ErrOperationInProgress.
+func (operation *Operation) Result() error {
+	select {
+	case <-operation.Done():
+		return operation.err
+	default:
+		return ErrOperationInProgress
+	}
+}
+
+// OperationUpdate is a status of an operation sent by StreamOperationStatus.
+type OperationUpdate struct {
+	OperationProgress
+
+	// Err is the error of WaitAsyncOperation, set on the last update if the
+	// operation failed or its wait was aborted.
+	Err error
+}
+
+// StreamOperationStatus waits for the operation with the given ID in the
+// background, as WaitAsyncOperation does with the given options, and sends an
+// update on the returned channel after every status check finding the
+// operation in progress. The last update has the terminal status of the
+// operation and the result of the wait, after which the channel is closed:
+//
+//	for update := range client.StreamOperationStatus(requestID) {
+//		if update.Err != nil {
+//			// handle the failure
+//		}
+//		log.Printf("%s: %s after %s", update.OperationID, update.Status, update.Elapsed)
+//	}
+//
+// The channel must be received from until it is closed, unless the wait is
+// ended with WithContext, which also ends the updates.
+func (client *Client) StreamOperationStatus(operationID string, options ...WaitOption) <-chan OperationUpdate {
+	updates := make(chan OperationUpdate, 1)
+	resolved := client.waitOptions(options...)
+	var done <-chan struct{}
+	if resolved.ctx != nil {
+		done = resolved.ctx.Done()
+	}
+	send := func(update OperationUpdate) {
+		select {
+		case updates <- update:
+		case <-done:
+		}
+	}
+
+	go func() {
+		defer close(updates)
+
+		last := OperationProgress{OperationID: operationID, Status: "InProgress"}
+		err := client.WaitAsyncOperation(operationID, append(options[:len(options):len(options)], WithProgress(func(progress OperationProgress) {
+			if resolved.progress != nil {
+				resolved.progress(progress)
+			}
+			last = progress
+			if progress.Status == "InProgress" {
+				send(OperationUpdate{OperationProgress: progress})
+			}
+		}))...)
+
+		update := OperationUpdate{OperationProgress: last, Err: err}
+		if err == nil {
+			update.Status = "Succeeded"
+		} else if errors.As(err, new(*FailedOperationError)) {
+			update.Status = "Failed"
+		}
+		send(update)
+	}()
+
+	return updates
+}
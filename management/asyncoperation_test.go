@@ -0,0 +1,169 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAsyncServer accepts every POST as an asynchronous operation named after
+// the last segment of its path. Operations named "fail" fail, the others
+// succeed once release is closed.
+func newAsyncServer(release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if r.Method == "POST" {
+			w.Header().Set("x-ms-request-id", id)
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		status := "InProgress"
+		select {
+		case <-release:
+			status = "Succeeded"
+		default:
+		}
+		body := fmt.Sprintf("<Operation><ID>%s</ID><Status>%s</Status></Operation>", id, status)
+		if id == "fail" {
+			body = "<Operation><ID>fail</ID><Status>Failed</Status><Error><Code>Conflict</Code><Message>No way</Message></Error></Operation>"
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+}
+
+func TestOperationsCanBeJoinedLater(t *testing.T) {
+	release := make(chan struct{})
+	server := newAsyncServer(release)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 5 * time.Millisecond})
+	first, err := client.StartOperation("services/first", "POST", "", []byte("<Input/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.StartOperation("services/fail", "POST", "", []byte("<Input/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := first.Status(); err != nil || status != "InProgress" {
+		t.Errorf("Expected the first operation to be in progress, got %s, %v", status, err)
+	}
+	if err := first.Result(); err != ErrOperationInProgress {
+		t.Errorf("Expected ErrOperationInProgress, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var abortedErr *AbortedOperationError
+	if err := first.Wait(ctx); !errors.As(err, &abortedErr) || abortedErr.OperationID != "first" {
+		t.Errorf("Expected the join to be aborted by its context, got %v", err)
+	}
+
+	close(release)
+	if err := first.Wait(context.Background()); err != nil {
+		t.Errorf("Expected the first operation to succeed, got %v", err)
+	}
+	<-second.Done()
+	if err := second.Result(); err == nil || !strings.Contains(err.Error(), "No way") {
+		t.Errorf("Expected the second operation to fail, got %v", err)
+	}
+	if status, _ := second.Status(); status != "Failed" {
+		t.Errorf("Expected the second operation to be reported failed, got %s", status)
+	}
+}
+
+func TestStartOperationCompletedSynchronously(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	operation, err := client.StartOperation("services/sync", "PUT", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := operation.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := operation.Status(); err != nil || status != "Succeeded" {
+		t.Errorf("Expected the operation to have succeeded, got %s, %v", status, err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected no status checks, got %d requests", requests)
+	}
+}
+
+func TestStreamOperationStatus(t *testing.T) {
+	server := newOperationServer(func(polls int) string {
+		if polls < 3 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: time.Millisecond})
+	var statuses []string
+	for update := range client.StreamOperationStatus("operation") {
+		if update.Err != nil || update.OperationID != "operation" {
+			t.Fatalf("Unexpected update %+v", update)
+		}
+		statuses = append(statuses, fmt.Sprintf("%s/%d", update.Status, update.Polls))
+	}
+
+	if expected := "InProgress/1,InProgress/2,Succeeded/3"; strings.Join(statuses, ",") != expected {
+		t.Errorf("Expected updates %s, got %v", expected, statuses)
+	}
+}
+
+func TestStreamOperationStatusReportsFailure(t *testing.T) {
+	server := newAsyncServer(make(chan struct{}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: time.Millisecond})
+	var last OperationUpdate
+	for update := range client.StreamOperationStatus("fail") {
+		last = update
+	}
+
+	var failedErr *FailedOperationError
+	if last.Status != "Failed" || !errors.As(last.Err, &failedErr) {
+		t.Errorf("Expected the last update to carry the failure, got %+v", last)
+	}
+}
+
+func TestStreamOperationStatusEndsWithContext(t *testing.T) {
+	server := newOperationServer(func(int) string { return "InProgress" })
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: time.Millisecond})
+	updates := client.StreamOperationStatus("operation", WithContext(ctx))
+
+	<-updates
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-updates:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected the updates to end with the context")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package management
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxConcurrentWaits is the number of operations WaitForOperations
+// polls at the same time when the call does not set it with
+// WithMaxConcurrentWaits.
+const DefaultMaxConcurrentWaits = 8
+
+// OperationFailure is an operation that failed, or whose wait was aborted,
+// in a call to WaitForOperations.
+type OperationFailure struct {
+	OperationID string
+	Err         error
+}
+
+// BatchOperationError is returned by WaitForOperations when some of the
+// operations failed. Failures are in the order of the operation IDs given.
+type BatchOperationError struct {
+	Operations int
+	Failures   []OperationFailure
+}
+
+// Error implements the error interface for the BatchOperationError type.
+func (e *BatchOperationError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		failures[i] = fmt.Sprintf("%s: %s", failure.OperationID, failure.Err)
+	}
+
+	return fmt.Sprintf("%d of %d Azure operations failed: %s", len(e.Failures), e.Operations, strings.Join(failures, "; "))
+}
+
+// Unwrap returns the errors of the failed operations, so that errors.Is and
+// errors.As look into each of them.
+func (e *BatchOperationError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+
+	return errs
+}
+
+// WithMaxConcurrentWaits sets the number of operations WaitForOperations
+// polls at the same time. Values below 1 keep DefaultMaxConcurrentWaits.
+func WithMaxConcurrentWaits(n int) WaitOption {
+	return func(options *waitOptions) {
+		if n > 0 {
+			options.maxConcurrentWaits = n
+		}
+	}
+}
+
+// WaitForOperations waits for all the given operations, polling several of
+// them at the same time with WaitAsyncOperation. It waits for every operation
+// even if some fail, and then returns a *BatchOperationError describing the
+// ones that failed, or nil. The options apply to the wait of every operation.
+func (client *Client) WaitForOperations(operationIDs []string, options ...WaitOption) error {
+	if len(operationIDs) == 0 {
+		return nil
+	}
+
+	concurrency := client.waitOptions(options...).maxConcurrentWaits
+	if concurrency <= 0 {
+		concurrency = DefaultMaxConcurrentWaits
+	}
+
+	errs := make([]error, len(operationIDs))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, operationID := range operationIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, operationID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = client.WaitAsyncOperation(operationID, options...)
+		}(i, operationID)
+	}
+	wg.Wait()
+
+	batchErr := &BatchOperationError{Operations: len(operationIDs)}
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Failures = append(batchErr.Failures, OperationFailure{OperationID: operationIDs[i], Err: err})
+		}
+	}
+	if len(batchErr.Failures) == 0 {
+		return nil
+	}
+
+	return batchErr
+}
@@ -0,0 +1,58 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForOperationsAggregatesFailures(t *testing.T) {
+	var active, maxActive int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&active, 1)
+		defer atomic.AddInt64(&active, -1)
+		for {
+			max := atomic.LoadInt64(&maxActive)
+			if n <= max || atomic.CompareAndSwapInt64(&maxActive, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		body := fmt.Sprintf("<Operation><ID>%s</ID><Status>Succeeded</Status></Operation>", id)
+		if strings.HasPrefix(id, "bad") {
+			body = fmt.Sprintf("<Operation><ID>%s</ID><Status>Failed</Status><Error><Code>Conflict</Code><Message>%s broke</Message></Error></Operation>", id, id)
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: time.Millisecond})
+	ids := []string{"good-1", "bad-1", "good-2", "good-3", "bad-2", "good-4"}
+	err := client.WaitForOperations(ids, WithMaxConcurrentWaits(2))
+
+	var batchErr *BatchOperationError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchOperationError, got %v", err)
+	}
+	if batchErr.Operations != 6 || len(batchErr.Failures) != 2 || batchErr.Failures[0].OperationID != "bad-1" || batchErr.Failures[1].OperationID != "bad-2" {
+		t.Errorf("Expected bad-1 and bad-2 to fail, got %+v", batchErr)
+	}
+	if !strings.Contains(err.Error(), "2 of 6 Azure operations failed") || !strings.Contains(err.Error(), "bad-2 broke") {
+		t.Errorf("Expected the error to describe the failures, got %v", err)
+	}
+	if maxActive > 2 {
+		t.Errorf("Expected at most 2 concurrent polls, got %d", maxActive)
+	}
+
+	if err := client.WaitForOperations([]string{"good-1", "good-2"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
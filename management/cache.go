@@ -0,0 +1,147 @@
+package management
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache caches the responses to GET requests for URLs that rarely
+// change, such as the lists of locations, role sizes and OS images, so that
+// repeated lookups do not reach the API. It is safe for concurrent use, so a
+// single ResponseCache can be shared through ClientConfig.ResponseCache by
+// several clients; responses are cached per subscription and API version.
+type ResponseCache struct {
+	ttls map[string]time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	path     string
+	response AzureResponse
+	expires  time.Time
+}
+
+// NewResponseCache returns a ResponseCache keeping the responses to the URLs
+// of ttls, relative to the subscription as in the Send methods of Client, for
+// the given time. Every page and query of a URL is cached separately. Other
+// URLs are not cached:
+//
+//	cache := management.NewResponseCache(map[string]time.Duration{
+//		"locations":       time.Hour,
+//		"rolesizes":       time.Hour,
+//		"services/images": 6 * time.Hour,
+//	})
+func NewResponseCache(ttls map[string]time.Duration) *ResponseCache {
+	cache := &ResponseCache{
+		ttls:    make(map[string]time.Duration, len(ttls)),
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+	for url, ttl := range ttls {
+		cache.ttls[cachePath(url)] = ttl
+	}
+
+	return cache
+}
+
+// Invalidate removes the cached responses to url, relative to the
+// subscription, including all its pages and queries, for every subscription.
+func (cache *ResponseCache) Invalidate(url string) {
+	if cache == nil {
+		return
+	}
+
+	path := cachePath(url)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, entry := range cache.entries {
+		if entry.path == path {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// InvalidateAll removes every cached response.
+func (cache *ResponseCache) InvalidateAll() {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries = make(map[string]cacheEntry)
+}
+
+// get returns a copy of the cached response for key, if there is one that has
+// not expired.
+func (cache *ResponseCache) get(key string) (*AzureResponse, bool) {
+	if cache == nil {
+		return nil, false
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !cache.now().Before(entry.expires) {
+		delete(cache.entries, key)
+		return nil, false
+	}
+
+	return copyResponse(&entry.response), true
+}
+
+// put caches response for key if url, relative to the subscription, is
+// cached.
+func (cache *ResponseCache) put(key string, url string, response *AzureResponse) {
+	if cache == nil {
+		return
+	}
+
+	path := cachePath(url)
+	ttl, ok := cache.ttls[path]
+	if !ok || ttl <= 0 {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries[key] = cacheEntry{path: path, response: *copyResponse(response), expires: cache.now().Add(ttl)}
+}
+
+// cachePath returns url, relative to the subscription, without its query and
+// surrounding slashes.
+func cachePath(url string) string {
+	if i := strings.Index(url, "?"); i >= 0 {
+		url = url[:i]
+	}
+	return strings.Trim(url, "/")
+}
+
+// copyResponse returns a copy of response that does not share its body or
+// header.
+func copyResponse(response *AzureResponse) *AzureResponse {
+	return &AzureResponse{
+		StatusCode:  response.StatusCode,
+		Header:      response.Header.Clone(),
+		Body:        append([]byte(nil), response.Body...),
+		RequestID:   response.RequestID,
+		Diagnostics: response.Diagnostics,
+	}
+}
+
+// cacheKey returns the key of the response to a GET request to url, relative
+// to the subscription, sent with apiVersion.
+func (client *Client) cacheKey(url string, apiVersion string) string {
+	return strings.Join([]string{client.managementURL, client.publishSettings.SubscriptionID, apiVersion, client.addQuery(url)}, " ")
+}
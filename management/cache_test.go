@@ -0,0 +1,83 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cache := NewResponseCache(map[string]time.Duration{"locations": time.Minute})
+	cache.now = func() time.Time { return now }
+	client := newTestClient(t, server.URL, ClientConfig{ResponseCache: cache})
+
+	get := func(url string, expectedRequests int64) {
+		t.Helper()
+		body, err := client.SendAzureGetRequest(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "<Locations/>" {
+			t.Fatalf("Unexpected body %q", body)
+		}
+		if actual := atomic.LoadInt64(&requests); actual != expectedRequests {
+			t.Fatalf("Expected %d requests after GET %s, got %d", expectedRequests, url, actual)
+		}
+	}
+
+	get("locations", 1)
+	get("locations", 1)
+	get("locations?ContinuationToken=page2", 2)
+	get("locations?ContinuationToken=page2", 2)
+	get("rolesizes", 3)
+	get("rolesizes", 4)
+
+	cache.Invalidate("locations")
+	get("locations", 5)
+	get("locations?ContinuationToken=page2", 6)
+
+	now = now.Add(time.Minute)
+	get("locations", 7)
+
+	cache.InvalidateAll()
+	get("locations", 8)
+	if _, err := client.SendAzureDeleteRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	get("locations", 9)
+}
+
+func TestResponseCacheIsPerSubscription(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(map[string]time.Duration{"locations": time.Minute})
+	for _, subscriptionID := range []string{"subscription-a", "subscription-b", "subscription-a"} {
+		client, err := NewClientFromConfig(subscriptionID, testManagementCert(t), ClientConfig{ManagementURL: server.URL, ResponseCache: cache})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.SendAzureGetRequest("locations"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if actual := atomic.LoadInt64(&requests); actual != 2 {
+		t.Errorf("Expected one request per subscription, got %d", actual)
+	}
+}
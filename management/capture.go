@@ -0,0 +1,86 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ResponseInfo describes a response received from the management API.
+type ResponseInfo struct {
+	Method     string
+	URL        string
+	StatusCode int
+
+	// RequestID is the x-ms-request-id header of the response, which
+	// Microsoft support asks for. For requests starting an asynchronous
+	// operation, it is also the ID of the operation.
+	RequestID string
+	Header    http.Header
+}
+
+// ResponseCapture collects the responses received for the requests sent with
+// a context returned by WithResponseCapture. It is safe for concurrent use.
+type ResponseCapture struct {
+	mu        sync.Mutex
+	responses []ResponseInfo
+}
+
+type responseCaptureKey struct{}
+
+// WithResponseCapture returns a copy of ctx that makes the clients bound to it
+// with WithContext report every response they receive, including the ones of
+// retried requests and status checks, to the returned ResponseCapture. It
+// gives access to the request IDs and headers of the responses of the service
+// sub-packages, whose results do not carry them:
+//
+//	ctx, capture := management.WithResponseCapture(context.Background())
+//	list, err := storageservice.NewClient(client.WithContext(ctx)).GetStorageServiceList()
+//	log.Printf("listed by request %s", capture.LastRequestID())
+func WithResponseCapture(ctx context.Context) (context.Context, *ResponseCapture) {
+	capture := new(ResponseCapture)
+	return context.WithValue(ctx, responseCaptureKey{}, capture), capture
+}
+
+// Responses returns the responses captured so far, in the order they were
+// received.
+func (capture *ResponseCapture) Responses() []ResponseInfo {
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	return append([]ResponseInfo(nil), capture.responses...)
+}
+
+// LastRequestID returns the request ID of the last response captured that has
+// one, or an empty string.
+func (capture *ResponseCapture) LastRequestID() string {
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	for i := len(capture.responses) - 1; i >= 0; i-- {
+		if requestID := capture.responses[i].RequestID; requestID != "" {
+			return requestID
+		}
+	}
+
+	return ""
+}
+
+// captureResponse reports a response to the ResponseCapture of ctx, if any.
+func captureResponse(ctx context.Context, request *http.Request, response *http.Response) {
+	capture, ok := ctx.Value(responseCaptureKey{}).(*ResponseCapture)
+	if !ok {
+		return
+	}
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	capture.responses = append(capture.responses, ResponseInfo{
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get(requestIdHeader),
+		Header:     response.Header,
+	})
+}
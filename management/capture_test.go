@@ -0,0 +1,70 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestWithResponseCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-request-id", "request-"+r.Method)
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	ctx, capture := WithResponseCapture(context.Background())
+	if capture.LastRequestID() != "" {
+		t.Errorf("Expected no request ID before any request")
+	}
+
+	bound := client.WithContext(ctx)
+	if _, err := bound.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SendAzureDeleteRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := capture.Responses()
+	if len(responses) != 1 {
+		t.Fatalf("Expected only the request bound to the context to be captured, got %+v", responses)
+	}
+	if responses[0].Method != "GET" || responses[0].StatusCode != http.StatusOK || !strings.HasSuffix(responses[0].URL, "/subscriptionID/locations") {
+		t.Errorf("Wrong response info: %+v", responses[0])
+	}
+	if requestID := capture.LastRequestID(); requestID != "request-GET" {
+		t.Errorf("Expected request ID 'request-GET', got '%s'", requestID)
+	}
+}
+
+func TestFailedOperationErrorCarriesOperationID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "<Operation><ID>operation</ID><Status>Failed</Status><HttpStatusCode>409</HttpStatusCode>" +
+			"<Error><Code>ConflictError</Code><Message>Taken</Message></Error></Operation>"
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: time.Millisecond})
+	err := client.WaitAsyncOperation("operation")
+	var failedErr *FailedOperationError
+	if !errors.As(err, &failedErr) || failedErr.OperationID != "operation" {
+		t.Fatalf("Expected a FailedOperationError, got %v", err)
+	}
+	if expected := "Azure operation operation failed. Code: ConflictError, Message: Taken"; err.Error() != expected {
+		t.Errorf("Wrong message. Expected: '%s', got: '%s'", expected, err.Error())
+	}
+	if !IsConflict(err) || RequestID(err) != "operation" || HTTPStatusCode(err) != http.StatusConflict {
+		t.Errorf("Expected a conflict carrying the operation ID, got %v", err)
+	}
+}
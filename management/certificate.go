@@ -0,0 +1,181 @@
+package management
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+const (
+	pkcs12PasswordVariable = "AZURE_SDK_PKCS12_PASSWORD"
+
+	errNoCertificate  = "No certificate found in the management certificate data"
+	errNoPrivateKey   = "No private key found in the management certificate data"
+	errOpenSSLMissing = "Decoding PKCS#12 certificates requires the openssl command: %v"
+)
+
+// LoadManagementCertificate converts a management certificate to the PEM form
+// expected by NewClient and NewClientFromConfig. data is either PEM holding
+// the certificate and its private key, or a PKCS#12 (.pfx) file, such as the
+// ones exported from the portal, protected by password. PKCS#12 files are
+// decoded with the openssl command, which must be installed.
+func LoadManagementCertificate(data []byte, password string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf(errParamNotSpecified, "data")
+	}
+
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		var err error
+		data, err = pkcs12ToPEM(data, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewManagementCertificate(data, data)
+}
+
+// NewManagementCertificate combines a certificate and its private key, each
+// given either PEM or DER encoded, into the PEM form expected by NewClient and
+// NewClientFromConfig. The key may be in PKCS#1, PKCS#8 or SEC 1 (EC) form.
+func NewManagementCertificate(cert []byte, key []byte) ([]byte, error) {
+	var out bytes.Buffer
+
+	certBlocks, keyBlocks := pemBlocks(cert)
+	if len(certBlocks) == 0 && !bytes.Contains(cert, []byte("-----BEGIN")) {
+		if _, err := x509.ParseCertificate(cert); err != nil {
+			return nil, err
+		}
+		certBlocks = []*pem.Block{{Type: "CERTIFICATE", Bytes: cert}}
+	}
+	if len(certBlocks) == 0 {
+		return nil, errors.New(errNoCertificate)
+	}
+
+	if !bytes.Equal(cert, key) {
+		_, keyBlocks = pemBlocks(key)
+		if len(keyBlocks) == 0 && !bytes.Contains(key, []byte("-----BEGIN")) {
+			keyBlock, err := derKeyBlock(key)
+			if err != nil {
+				return nil, err
+			}
+			keyBlocks = []*pem.Block{keyBlock}
+		}
+	}
+	if len(keyBlocks) == 0 {
+		return nil, errors.New(errNoPrivateKey)
+	}
+
+	for _, block := range append(certBlocks, keyBlocks[0]) {
+		pem.Encode(&out, &pem.Block{Type: block.Type, Bytes: block.Bytes})
+	}
+
+	if _, err := tls.X509KeyPair(out.Bytes(), out.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// ManagementTLSConfig returns a TLS configuration presenting managementCert,
+// in the PEM form returned by NewManagementCertificate, for the transport of
+// a ClientConfig.HTTPClient.
+func ManagementTLSConfig(managementCert []byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(managementCert, managementCert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// NewClientFromPKCS12File creates a new Client for the given subscription,
+// using the management certificate stored in the PKCS#12 (.pfx) file at
+// pfxPath, protected by password. See LoadManagementCertificate.
+func NewClientFromPKCS12File(subscriptionID string, pfxPath string, password string, config ClientConfig) (Client, error) {
+	if subscriptionID == "" {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "subscriptionID")
+	}
+	if pfxPath == "" {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "pfxPath")
+	}
+
+	pfx, err := ioutil.ReadFile(pfxPath)
+	if err != nil {
+		return Client{}, err
+	}
+
+	cert, err := LoadManagementCertificate(pfx, password)
+	if err != nil {
+		return Client{}, err
+	}
+
+	return makeClient(subscriptionID, cert, nil, config)
+}
+
+// pemBlocks returns the certificate and private key blocks of the PEM data,
+// skipping anything else, such as the bag attributes written by openssl.
+func pemBlocks(data []byte) (certs []*pem.Block, keys []*pem.Block) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, keys
+		}
+
+		switch {
+		case block.Type == "CERTIFICATE":
+			certs = append(certs, block)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			keys = append(keys, block)
+		}
+	}
+}
+
+// derKeyBlock returns the PEM block of a DER encoded private key.
+func derKeyBlock(der []byte) (*pem.Block, error) {
+	if _, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}, nil
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+	}
+	if _, err := x509.ParseECPrivateKey(der); err == nil {
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+	}
+
+	return nil, errors.New(errNoPrivateKey)
+}
+
+// pkcs12ToPEM decodes a PKCS#12 file with openssl. The password is passed
+// through the environment of the command rather than its arguments, so that
+// it does not show in the process list. Files using the legacy RC2
+// encryption of older Windows exports need the -legacy flag of OpenSSL 3,
+// which is tried second since older versions reject it.
+func pkcs12ToPEM(pfx []byte, password string) ([]byte, error) {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		return nil, fmt.Errorf(errOpenSSLMissing, err)
+	}
+
+	env := []string{pkcs12PasswordVariable + "=" + password}
+	command := "openssl pkcs12 -nodes -passin env:" + pkcs12PasswordVariable
+	out, err := executeCommandWithEnv(command, env, pfx)
+	if err != nil {
+		out, err = executeCommandWithEnv(command+" -legacy", env, pfx)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) != 0 {
+			return nil, fmt.Errorf("openssl could not decode the PKCS#12 certificate: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
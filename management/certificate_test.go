@@ -0,0 +1,264 @@
+package management
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a self-signed DER certificate and its PKCS#1 DER
+// private key.
+func newTestCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "management"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, x509.MarshalPKCS1PrivateKey(key)
+}
+
+var (
+	testManagementCertOnce sync.Once
+	testManagementCertPEM  []byte
+)
+
+// testManagementCert returns a management certificate in the PEM form of
+// NewManagementCertificate, generated once for all the tests of the package.
+func testManagementCert(t *testing.T) []byte {
+	testManagementCertOnce.Do(func() {
+		certDER, keyDER := newTestCertificate(t)
+		managementCert, err := NewManagementCertificate(certDER, keyDER)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testManagementCertPEM = managementCert
+	})
+
+	return testManagementCertPEM
+}
+
+// newTestPKCS12 exports the certificate and key to a PKCS#12 file protected by
+// password, skipping the test if openssl is not installed.
+func newTestPKCS12(t *testing.T, certDER, keyDER []byte, password string) []byte {
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl is not installed")
+	}
+
+	var input bytes.Buffer
+	pem.Encode(&input, &pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	pem.Encode(&input, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: keyDER})
+
+	// openssl reads the PEM input of -export twice, so it cannot come from
+	// standard input.
+	inputFile, err := ioutil.TempFile("", "pkcs12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(inputFile.Name())
+	inputFile.Write(input.Bytes())
+	inputFile.Close()
+
+	command := "openssl pkcs12 -export -in " + inputFile.Name() + " -passout env:" + pkcs12PasswordVariable
+	pfx, err := executeCommandWithEnv(command, []string{pkcs12PasswordVariable + "=" + password}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pfx
+}
+
+func TestNewManagementCertificateAcceptsDERAndPEM(t *testing.T) {
+	certDER, keyDER := newTestCertificate(t)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: keyDER})
+
+	tests := []struct {
+		name      string
+		cert, key []byte
+	}{
+		{"DER", certDER, keyDER},
+		{"PEM", certPEM, keyPEM},
+		{"combined PEM", append(certPEM, keyPEM...), append(certPEM, keyPEM...)},
+	}
+
+	for _, test := range tests {
+		combined, err := NewManagementCertificate(test.cert, test.key)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if _, err := tls.X509KeyPair(combined, combined); err != nil {
+			t.Errorf("%s: the combined certificate is unusable: %v", test.name, err)
+		}
+	}
+
+	if _, err := NewManagementCertificate(certPEM, certPEM); err == nil || err.Error() != errNoPrivateKey {
+		t.Errorf("Expected a missing private key to be reported, got %v", err)
+	}
+}
+
+func TestLoadManagementCertificateFromPKCS12(t *testing.T) {
+	certDER, keyDER := newTestCertificate(t)
+	pfx := newTestPKCS12(t, certDER, keyDER, "pass word")
+
+	combined, err := LoadManagementCertificate(pfx, "pass word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(combined, combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cert.Certificate[0], certDER) {
+		t.Errorf("Expected the certificate of the PKCS#12 file")
+	}
+
+	if _, err := LoadManagementCertificate(pfx, "wrong"); err == nil {
+		t.Errorf("Expected a wrong password to be rejected")
+	}
+}
+
+func TestNewClientFromPKCS12File(t *testing.T) {
+	certDER, keyDER := newTestCertificate(t)
+	pfx := newTestPKCS12(t, certDER, keyDER, "secret")
+
+	dir, err := ioutil.TempDir("", "pkcs12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pfxPath := filepath.Join(dir, "management.pfx")
+	if err := ioutil.WriteFile(pfxPath, pfx, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientFromPKCS12File("subscriptionID", pfxPath, "secret", ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tls.X509KeyPair(client.publishSettings.SubscriptionCert, client.publishSettings.SubscriptionKey); err != nil {
+		t.Errorf("Expected the client to hold the decoded certificate: %v", err)
+	}
+}
+
+func TestNewClientRejectsInvalidCertificate(t *testing.T) {
+	if _, err := NewClientFromConfig("subscriptionID", []byte("cert"), ClientConfig{}); err == nil {
+		t.Fatal("Expected a certificate without a key pair to be rejected")
+	}
+
+	if _, err := NewClientFromConfig("subscriptionID", []byte("cert"), ClientConfig{HTTPClient: &http.Client{}}); err != nil {
+		t.Fatalf("Expected the certificate not to be parsed with an HTTPClient, got %v", err)
+	}
+}
+
+func TestManagementTLSConfigPresentsCertificate(t *testing.T) {
+	certDER, keyDER := newTestCertificate(t)
+	managementCert, err := NewManagementCertificate(certDER, keyDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commonName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			commonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		w.Header().Set("Content-Length", "0")
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	ssl, err := ManagementTLSConfig(managementCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssl.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	client := newTestClient(t, server.URL, ClientConfig{HTTPClient: &http.Client{Transport: &http.Transport{TLSClientConfig: ssl}}})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if commonName != "management" {
+		t.Errorf("Expected the management certificate to be presented, got '%s'", commonName)
+	}
+}
+
+func TestClientUsesTLSConfig(t *testing.T) {
+	certDER, keyDER := newTestCertificate(t)
+	managementCert, err := NewManagementCertificate(certDER, keyDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commonName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			commonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		w.Header().Set("Content-Length", "0")
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MaxVersion: tls.VersionTLS12}
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	noRetry := &RetryPolicy{MaxAttempts: 1}
+	send := func(tlsConfig *tls.Config) error {
+		client, err := NewClientFromConfig("subscriptionID", managementCert, ClientConfig{ManagementURL: server.URL, TLSConfig: tlsConfig, RetryPolicy: noRetry})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.SendAzureGetRequest("locations")
+		return err
+	}
+
+	if err := send(nil); err == nil {
+		t.Fatal("Expected the certificate of the test server not to be trusted by default")
+	}
+
+	tlsConfig := &tls.Config{RootCAs: rootCAs}
+	if err := send(tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+	if commonName != "management" {
+		t.Errorf("Expected the management certificate to be presented, got '%s'", commonName)
+	}
+	if len(tlsConfig.Certificates) != 0 {
+		t.Errorf("Expected the TLSConfig given to be left unchanged")
+	}
+
+	if err := send(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS13}); err == nil {
+		t.Error("Expected MinVersion to be enforced")
+	}
+	if err := send(&tls.Config{InsecureSkipVerify: true}); err != nil {
+		t.Errorf("Expected InsecureSkipVerify to accept the test server, got %v", err)
+	}
+}
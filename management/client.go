@@ -1,77 +1,382 @@
 package management
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const (
-	defaultAzureManagementURL             = "https://management.core.windows.net"
 	errPublishSettingsConfiguration       = "PublishSettingsFilePath is set. Consequently ManagementCertificatePath and SubscriptionId must not be set."
 	errManagementCertificateConfiguration = "Both ManagementCertificatePath and SubscriptionId should be set, and PublishSettingsFilePath must not be set."
 	errParamNotSpecified                  = "Parameter %s is not specified."
 )
 
 // AzureError represents an error returned by the management API. It has an error
-// code (for example, ResourceNotFound) and a descriptive message.
+// code (for example, ResourceNotFound) and a descriptive message, decoded from
+// the body of the response, and describes the request and response it came
+// from.
 type AzureError struct {
 	XMLName xml.Name `xml:"Error"`
 	Code    string
 	Message string
+
+	// StatusCode is the HTTP status of the response and RequestID its
+	// x-ms-request-id header, which Microsoft support asks for.
+	StatusCode int    `xml:"-"`
+	RequestID  string `xml:"-"`
+
+	// Method and URL identify the request that failed.
+	Method string `xml:"-"`
+	URL    string `xml:"-"`
+
+	// Body is the raw body of the response.
+	Body []byte `xml:"-"`
 }
 
 //Error implements the error interface for the AzureError type.
 func (e *AzureError) Error() string {
-	return fmt.Sprintf("Error response from Azure. Code: %s, Message: %s", e.Code, e.Message)
+	message := fmt.Sprintf("Error response from Azure. Code: %s, Message: %s", e.Code, e.Message)
+	if e.StatusCode == 0 {
+		return message
+	}
+
+	return fmt.Sprintf("%s (HTTP %d from %s %s, request ID %s)", message, e.StatusCode, e.Method, e.URL, e.RequestID)
 }
 
 // Client provides a client to the Azure API.
+//
+// A Client is safe for concurrent use by multiple goroutines, and so are its
+// copies and the service sub-package clients made from it. Its configuration
+// is fixed when it is created; the state shared by its copies, such as the
+// connection pool, the negotiated API version and the quota usage, is
+// synchronized. The only exception is AddToUserAgent, which modifies the
+// Client it is called on and must be called before the client is shared.
 type Client struct {
-	managementURL   string
-	publishSettings publishSettings
+	managementURL        string
+	environment          Environment
+	publishSettings      publishSettings
+	diagnosticsCollector DiagnosticsCollector
+	metrics              Metrics
+	pollInterval         time.Duration
+	pollBackoff          float64
+	maxPollInterval      time.Duration
+	pollJitter           float64
+	operationTimeout     time.Duration
+	progress             ProgressFunc
+	routes               RouteTable
+	quota                *quotaTracker
+	apiVersion           *apiVersionState
+	requiredAPIVersion   string
+	ifMatch              string
+	headers              http.Header
+	query                url.Values
+	disableCompression   bool
+	logger               Logger
+	errorHandler         ErrorHandler
+	lifecycle            *lifecycle
+	ctx                  context.Context
+	retryPolicy          RetryPolicy
+	httpClient           *http.Client
+	requestHooks         []RequestHook
+	responseHooks        []ResponseHook
+	tracer               *tracer
+	tokens               TokenSource
+	userAgent            string
+	rateLimiter          *RateLimiter
+	quotaThreshold       int64
+	responseCache        *ResponseCache
 }
 
 // ClientConfig provides a configuration for use by a Client
 type ClientConfig struct {
+	// Environment is the Azure cloud the client talks to. If not set, it is
+	// the predefined environment matching ManagementURL, or PublicCloud.
+	Environment Environment
+
+	// ManagementURL overrides the management endpoint of the Environment.
 	ManagementURL string
+
+	// Subscription selects, by ID or name, the subscription of publish
+	// settings that hold several; see Subscriptions. If empty, the first
+	// subscription is used.
+	Subscription string
+
+	// DiagnosticsCollector, if set, is given the RequestDiagnostics of
+	// every request sent by the client.
+	DiagnosticsCollector DiagnosticsCollector
+
+	// Metrics, if set, is told of every request sent and every status check
+	// made by the client, its copies and the service sub-packages using
+	// them.
+	Metrics Metrics
+
+	// DefaultPollInterval and DefaultOperationTimeout are inherited by
+	// every WaitAsyncOperation call made through the client, including the
+	// ones made by the service sub-packages, unless the call overrides them
+	// with WaitOptions. Zero values select the package defaults
+	// DefaultPollInterval and DefaultOperationTimeout.
+	DefaultPollInterval     time.Duration
+	DefaultOperationTimeout time.Duration
+
+	// PollBackoff and MaxPollInterval make every wait start with frequent
+	// status checks and space them out as the operation runs, and
+	// PollJitter randomizes the time between two checks, so that many
+	// concurrent waits do not poll in synchronized bursts. See
+	// WithPollBackoff and WithPollJitter, which override them for a call.
+	// Zero values keep the interval constant and unrandomized.
+	PollBackoff     float64
+	MaxPollInterval time.Duration
+	PollJitter      float64
+
+	// OperationProgress, if set, is called after every status check of
+	// WaitAsyncOperation, unless the call sets its own with WithProgress.
+	OperationProgress ProgressFunc
+
+	// Routes overrides the URL templates of individual routes, for
+	// environments that serve them under a different path. Routes that
+	// are not overridden keep their DefaultRoutes template.
+	Routes RouteTable
+
+	// APIVersion is the x-ms-version sent with every request. If empty,
+	// each operation sends the version it was written against.
+	APIVersion string
+
+	// FallbackAPIVersions, newest first, opts in to API version fallback:
+	// when the service rejects APIVersion, the request is resent once with
+	// each of them until one is accepted, and that version is used for the
+	// subsequent requests of the client. See EffectiveAPIVersion.
+	FallbackAPIVersions []string
+
+	// MaxIdleConnsPerHost is the number of idle connections to the
+	// management endpoint kept for reuse by the client and its copies. Zero
+	// selects the default of the http package, which is 2; raise it when
+	// making many concurrent requests, for example to create VMs in
+	// parallel.
+	MaxIdleConnsPerHost int
+
+	// DisableKeepAlives makes the client open a new connection for every
+	// request.
+	DisableKeepAlives bool
+
+	// DisableCompression stops the client from asking for gzip compressed
+	// responses, which it otherwise decompresses transparently. Compression
+	// shrinks large lists, such as the list of OS images, several times.
+	DisableCompression bool
+
+	// DialTimeout, TLSHandshakeTimeout and ResponseHeaderTimeout bound the
+	// time the client waits for a connection, its TLS handshake and the
+	// headers of a response, so that a hung connection fails the request,
+	// which can then be retried, instead of stalling it forever. Zero values
+	// select DefaultDialTimeout, DefaultTLSHandshakeTimeout and
+	// DefaultResponseHeaderTimeout; NoTimeout disables them. They are
+	// distinct from DefaultOperationTimeout, which bounds the wait for an
+	// asynchronous operation.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// RequestTimeout, if set, bounds the time of every attempt of a request,
+	// from connecting to reading the whole response body.
+	RequestTimeout time.Duration
+
+	// TLSConfig, if set, is the TLS configuration of the connections of the
+	// client, for example to raise MinVersion, restrict CipherSuites, trust
+	// the RootCAs of Azure Stack or of a proxy inspecting TLS, or, in test
+	// environments only, set InsecureSkipVerify. It is cloned, and the
+	// management certificate is added to the Certificates of the clone.
+	TLSConfig *tls.Config
+
+	// Proxy returns the proxy to send a request through, or nil to send it
+	// directly. If nil, ProxyFromEnvironment is used; http.ProxyURL selects
+	// a fixed proxy.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// HTTPClient, if set, sends the requests of the client instead of one
+	// it creates, for example to use a custom transport. It is used as is,
+	// except that the client follows redirects itself: MaxIdleConnsPerHost,
+	// DisableKeepAlives, the timeouts, TLSConfig, Proxy and WrapTransport
+	// are ignored, and with certificate authentication its transport must
+	// present the management certificate itself; see ManagementTLSConfig.
+	HTTPClient *http.Client
+
+	// WrapTransport, if set, is given the transport of the client and
+	// returns the one to use instead, which usually delegates to it. See
+	// Recorder.Wrap.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// RequestHooks and ResponseHooks are called, in order, for every
+	// request sent and response received by the client, its copies and the
+	// service sub-packages using them.
+	RequestHooks  []RequestHook
+	ResponseHooks []ResponseHook
+
+	// TraceWriter, if set, receives a dump of every request and response
+	// of the client, with certificates, keys and passwords redacted, for
+	// attaching to support tickets. If nil, setting the AZURE_SDK_DEBUG
+	// environment variable traces to standard error.
+	TraceWriter io.Writer
+
+	// UserAgent, if set, is appended to DefaultUserAgent to identify the
+	// tool using the SDK. See AddToUserAgent.
+	UserAgent string
+
+	// RateLimiter, if set, limits the rate of the requests of the client,
+	// its copies and the service sub-packages using them, including
+	// retries and the status checks of WaitAsyncOperation. Share one
+	// RateLimiter between the clients of a subscription to keep them all
+	// within its request budget.
+	RateLimiter *RateLimiter
+
+	// QuotaThreshold, if positive, slows down RateLimiter while the
+	// remaining subscription reads or writes reported by the service are
+	// below it, in proportion to how far below, down to a tenth of its
+	// rate, to adapt before requests are throttled. It has no effect
+	// without a RateLimiter. See RemainingQuota.
+	QuotaThreshold int64
+
+	// ResponseCache, if set, answers the GET requests of the client, its
+	// copies and the service sub-packages using them for the URLs it caches
+	// while its responses are fresh. See NewResponseCache.
+	ResponseCache *ResponseCache
+
+	// RetryPolicy controls how failed requests are retried. If nil,
+	// DefaultRetryPolicy is used.
+	RetryPolicy *RetryPolicy
+
+	// Logger, if set, receives the log events of the client, such as the
+	// warning emitted when a fallback API version is negotiated.
+	Logger Logger
+
+	// ErrorHandler, if set, is told of the errors of the client, its copies
+	// and the service sub-packages using them, for callers wanting to handle
+	// them at the process level. See ErrorHandler.
+	ErrorHandler ErrorHandler
 }
 
 // NewAnonymousClient creates a new azure.Client with no credentials set.
 func NewAnonymousClient() Client {
-	return Client{}
+	return Client{
+		managementURL: PublicCloud.ManagementURL,
+		environment:   PublicCloud,
+		quota:         newQuotaTracker(),
+		apiVersion:    newAPIVersionState("", nil),
+		lifecycle:     newLifecycle(),
+		retryPolicy:   DefaultRetryPolicy(),
+	}
 }
 
 // NewClient creates a new Client using the given subscription ID and
 // management certificate
 func NewClient(subscriptionID string, managementCert []byte) (Client, error) {
-	config := ClientConfig{ManagementURL: defaultAzureManagementURL}
+	config := ClientConfig{Environment: PublicCloud}
 	return NewClientFromConfig(subscriptionID, managementCert, config)
 }
 
 // NewClientFromConfig creates a new Client using a given ClientConfig
 func NewClientFromConfig(subscriptionID string, managementCert []byte, config ClientConfig) (Client, error) {
-	return makeClient(subscriptionID, managementCert, config.ManagementURL)
+	return makeClient(subscriptionID, managementCert, nil, config)
 }
 
-func makeClient(subscriptionID string, managementCert []byte, managementURL string) (Client, error) {
+//makeClient creates a client authenticated either by managementCert or, if it
+//is nil, by tokens.
+func makeClient(subscriptionID string, managementCert []byte, tokens TokenSource, config ClientConfig) (Client, error) {
 	var client Client
 	if subscriptionID == "" {
 		return client, errors.New("azure: subscription ID required")
-	} else if len(managementCert) == 0 {
+	} else if len(managementCert) == 0 && tokens == nil {
 		return client, errors.New("azure: management certificate required")
-	} else if managementURL == "" {
+	}
+
+	environment := resolveEnvironment(config)
+	if config.ManagementURL == "" {
+		config.ManagementURL = environment.ManagementURL
+	}
+	if config.ManagementURL == "" {
 		return client, errors.New("azure: base URL required")
 	}
 
+	retryPolicy := DefaultRetryPolicy()
+	if config.RetryPolicy != nil {
+		retryPolicy = *config.RetryPolicy
+	}
+
+	userAgent := DefaultUserAgent
+	if extension := strings.TrimSpace(config.UserAgent); extension != "" {
+		userAgent += " " + extension
+	}
+
 	publishSettings := publishSettings{
 		SubscriptionID:   subscriptionID,
 		SubscriptionCert: managementCert,
 		SubscriptionKey:  managementCert,
 	}
+	httpClient, err := newHttpClient(publishSettings, config)
+	if err != nil {
+		return client, fmt.Errorf("azure: invalid management certificate: %v", err)
+	}
 
 	return Client{
-		managementURL:   managementURL,
-		publishSettings: publishSettings,
+		managementURL:        strings.TrimSuffix(config.ManagementURL, "/"),
+		environment:          environment,
+		publishSettings:      publishSettings,
+		diagnosticsCollector: config.DiagnosticsCollector,
+		metrics:              config.Metrics,
+		pollInterval:         config.DefaultPollInterval,
+		operationTimeout:     config.DefaultOperationTimeout,
+		progress:             config.OperationProgress,
+		pollBackoff:          config.PollBackoff,
+		maxPollInterval:      config.MaxPollInterval,
+		pollJitter:           config.PollJitter,
+		routes:               mergeRoutes(config.Routes),
+		quota:                newQuotaTracker(),
+		apiVersion:           newAPIVersionState(config.APIVersion, config.FallbackAPIVersions),
+		logger:               config.Logger,
+		errorHandler:         config.ErrorHandler,
+		lifecycle:            newLifecycle(),
+		retryPolicy:          retryPolicy,
+		httpClient:           httpClient,
+		requestHooks:         append([]RequestHook(nil), config.RequestHooks...),
+		responseHooks:        append([]ResponseHook(nil), config.ResponseHooks...),
+		tracer:               newTracer(config.TraceWriter),
+		tokens:               tokens,
+		userAgent:            userAgent,
+		rateLimiter:          config.RateLimiter,
+		quotaThreshold:       config.QuotaThreshold,
+		responseCache:        config.ResponseCache,
+		disableCompression:   config.DisableCompression,
 	}, nil
 }
+
+// WithContext returns a copy of the client whose requests and waits are bound
+// to ctx: they fail with ctx.Err() once ctx is cancelled or its deadline
+// passes, and requests in flight are aborted. The copy shares everything else
+// with the client, so it can be handed to the NewClient function of any
+// service sub-package to make its calls cancellable:
+//
+//	vmClient := virtualmachine.NewClient(client.WithContext(ctx))
+func (client Client) WithContext(ctx context.Context) Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	client.ctx = ctx
+	return client
+}
+
+// Context returns the context the requests of the client are bound to. It is
+// context.Background() unless the client was created with WithContext.
+func (client *Client) Context() context.Context {
+	if client.ctx == nil {
+		return context.Background()
+	}
+
+	return client.ctx
+}
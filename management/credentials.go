@@ -0,0 +1,91 @@
+package management
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	subscriptionIDVariable         = "AZURE_SUBSCRIPTION_ID"
+	publishSettingsPathVariable    = "AZURE_PUBLISHSETTINGS_PATH"
+	managementCertVariable         = "AZURE_MANAGEMENT_CERT"
+	managementCertPasswordVariable = "AZURE_MANAGEMENT_CERT_PASSWORD"
+
+	// azureConfigDirectory is the directory of the home directory searched
+	// for publish settings, as used by the Azure command line tools.
+	azureConfigDirectory = ".azure"
+
+	errNoCredentials = "No Azure credentials found. Set %s, or %s and %s, or put a .publishsettings file in %s"
+)
+
+// NewClientFromEnvironment creates a new Client from the credentials found in
+// the environment, so that CI pipelines and containers can authenticate
+// without code changes. They are looked for in order:
+//
+//   - the publish settings file at AZURE_PUBLISHSETTINGS_PATH. If
+//     AZURE_SUBSCRIPTION_ID is set too, it selects the subscription of the
+//     file, unless the options select one with ClientConfig.Subscription;
+//   - the subscription AZURE_SUBSCRIPTION_ID with the management certificate
+//     AZURE_MANAGEMENT_CERT, which is either the PEM certificate and private
+//     key themselves or the path of a file holding them, as PEM or as
+//     PKCS#12 protected by AZURE_MANAGEMENT_CERT_PASSWORD;
+//   - the first publish settings file, by name, of the .azure directory of
+//     the home directory, selected as for AZURE_PUBLISHSETTINGS_PATH.
+func NewClientFromEnvironment(options ...ClientOption) (Client, error) {
+	config := NewClientConfig(options...)
+	subscriptionID := os.Getenv(subscriptionIDVariable)
+	if config.Subscription == "" {
+		config.Subscription = subscriptionID
+	}
+
+	if path := os.Getenv(publishSettingsPathVariable); path != "" {
+		return NewClientFromPublishSettingsFile(path, config)
+	}
+
+	if cert := os.Getenv(managementCertVariable); cert != "" {
+		if subscriptionID == "" {
+			return Client{}, fmt.Errorf(errParamNotSpecified, subscriptionIDVariable)
+		}
+
+		managementCert, err := environmentManagementCert(cert, os.Getenv(managementCertPasswordVariable))
+		if err != nil {
+			return Client{}, err
+		}
+		return NewClientFromConfig(subscriptionID, managementCert, config)
+	}
+
+	directory := azureConfigDirectory
+	if home, err := os.UserHomeDir(); err == nil {
+		directory = filepath.Join(home, azureConfigDirectory)
+		paths, _ := filepath.Glob(filepath.Join(directory, "*.publishsettings"))
+		if len(paths) != 0 {
+			sort.Strings(paths)
+			return NewClientFromPublishSettingsFile(paths[0], config)
+		}
+	}
+
+	return Client{}, fmt.Errorf(errNoCredentials, publishSettingsPathVariable, subscriptionIDVariable, managementCertVariable, directory)
+}
+
+// environmentManagementCert returns the management certificate given by the
+// value of AZURE_MANAGEMENT_CERT: PEM data, or the path of a PEM or PKCS#12
+// file.
+func environmentManagementCert(value string, password string) ([]byte, error) {
+	if bytes.Contains([]byte(value), []byte("-----BEGIN")) {
+		return []byte(value), nil
+	}
+
+	data, err := ioutil.ReadFile(value)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return data, nil
+	}
+
+	return LoadManagementCertificate(data, password)
+}
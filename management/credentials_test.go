@@ -0,0 +1,101 @@
+package management
+
+import (
+	"bytes"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// clearCredentialVariables unsets the variables read by
+// NewClientFromEnvironment and points the home directory at an empty one.
+func clearCredentialVariables(t *testing.T) string {
+	for _, name := range []string{subscriptionIDVariable, publishSettingsPathVariable, managementCertVariable, managementCertPasswordVariable} {
+		t.Setenv(name, "")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	return home
+}
+
+func TestNewClientFromEnvironmentPublishSettingsPath(t *testing.T) {
+	clearCredentialVariables(t)
+	path := filepath.Join(t.TempDir(), "credentials.publishsettings")
+	publishSettings := newTestPublishSettings(t, "https://management.core.windows.net/", "subscription-a:Production", "subscription-b:Test")
+	if err := ioutil.WriteFile(path, publishSettings, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(publishSettingsPathVariable, path)
+	t.Setenv(subscriptionIDVariable, "subscription-b")
+
+	client, err := NewClientFromEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.publishSettings.SubscriptionID != "subscription-b" {
+		t.Errorf("Expected the subscription of %s, got %s", subscriptionIDVariable, client.publishSettings.SubscriptionID)
+	}
+}
+
+func TestNewClientFromEnvironmentManagementCert(t *testing.T) {
+	clearCredentialVariables(t)
+	certDER, keyDER := newTestCertificate(t)
+	var managementCert bytes.Buffer
+	pem.Encode(&managementCert, &pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	pem.Encode(&managementCert, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: keyDER})
+
+	certPath := filepath.Join(t.TempDir(), "management.pem")
+	if err := ioutil.WriteFile(certPath, managementCert.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(managementCertVariable, certPath)
+	if _, err := NewClientFromEnvironment(); err == nil || !strings.Contains(err.Error(), subscriptionIDVariable) {
+		t.Fatalf("Expected an error for the missing %s, got %v", subscriptionIDVariable, err)
+	}
+
+	t.Setenv(subscriptionIDVariable, "subscriptionID")
+	for _, value := range []string{certPath, managementCert.String()} {
+		t.Setenv(managementCertVariable, value)
+		client, err := NewClientFromEnvironment(WithEnvironment(ChinaCloud))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.publishSettings.SubscriptionID != "subscriptionID" || !bytes.Equal(client.publishSettings.SubscriptionCert, managementCert.Bytes()) {
+			t.Errorf("Unexpected credentials %+v", client.publishSettings)
+		}
+		if client.managementURL != ChinaCloud.ManagementURL {
+			t.Errorf("Expected the options to apply, got %s", client.managementURL)
+		}
+	}
+}
+
+func TestNewClientFromEnvironmentHomeDirectory(t *testing.T) {
+	home := clearCredentialVariables(t)
+
+	_, err := NewClientFromEnvironment()
+	if err == nil || !strings.Contains(err.Error(), publishSettingsPathVariable) {
+		t.Fatalf("Expected an error listing the variables, got %v", err)
+	}
+
+	directory := filepath.Join(home, azureConfigDirectory)
+	if err := os.Mkdir(directory, 0700); err != nil {
+		t.Fatal(err)
+	}
+	publishSettings := newTestPublishSettings(t, "https://management.core.windows.net/", "subscription-a:Production")
+	if err := ioutil.WriteFile(filepath.Join(directory, "a.publishsettings"), publishSettings, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientFromEnvironment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.publishSettings.SubscriptionID != "subscription-a" {
+		t.Errorf("Expected the subscription of the home directory, got %s", client.publishSettings.SubscriptionID)
+	}
+}
@@ -0,0 +1,155 @@
+package management
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestDiagnostics describes a single round trip to the management API.
+// A value is produced for every attempt, including retried ones.
+type RequestDiagnostics struct {
+	// Operation names the route the request was sent to; see
+	// RequestMetric.
+	Operation  string
+	Method     string
+	URL        string
+	StatusCode int
+	RequestID  string
+
+	// ServedByRegion is the value of the x-ms-servedbyregion header, which
+	// names the management cluster that handled the request. It is empty
+	// when the service did not send the header.
+	ServedByRegion string
+
+	// Start is the time the request was handed to the transport.
+	Start time.Time
+
+	// TimeToFirstByte is the time from Start until the response headers
+	// were received.
+	TimeToFirstByte time.Duration
+
+	// Duration is the time from Start until the response body was fully
+	// read, or until the transport failed.
+	Duration time.Duration
+
+	// DNS, Connect and TLSHandshake are the times spent resolving the host
+	// name, establishing the TCP connection and performing the TLS
+	// handshake of the connection the request was sent on. They are zero
+	// when the request reused an idle connection, and are only measured
+	// when a DiagnosticsCollector or Metrics is configured.
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+}
+
+// DiagnosticsCollector is implemented by types that want to observe the
+// RequestDiagnostics of every request sent by a Client, for example to
+// record latency percentiles per region.
+type DiagnosticsCollector interface {
+	CollectRequestDiagnostics(diagnostics RequestDiagnostics)
+}
+
+// AzureResponse is a response from the management API together with the
+// diagnostics collected while it was being retrieved.
+type AzureResponse struct {
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	RequestID   string
+	Diagnostics RequestDiagnostics
+
+	// stream is the unread body of a streamed response.
+	stream *responseStream
+}
+
+// responseStream is the body of a streamed response. Closing it releases the
+// resources held for the request.
+type responseStream struct {
+	io.ReadCloser
+	release []func()
+	once    sync.Once
+}
+
+func (stream *responseStream) Close() error {
+	err := stream.ReadCloser.Close()
+	stream.once.Do(func() {
+		for _, release := range stream.release {
+			release()
+		}
+	})
+	return err
+}
+
+// connectionTimings measures the phases of establishing the connection of a
+// request with an httptrace.ClientTrace. Its callbacks may be called from
+// the goroutines of the transport.
+type connectionTimings struct {
+	mu                               sync.Mutex
+	dnsStart, connectStart, tlsStart time.Time
+	dns, connect, tlsHandshake       time.Duration
+}
+
+// traceConnection returns ctx with a trace measuring the connection of the
+// requests sent with it if the client has a DiagnosticsCollector or Metrics,
+// so that requests are not traced when nothing observes the timings.
+func (client *Client) traceConnection(ctx context.Context) (context.Context, *connectionTimings) {
+	if client.diagnosticsCollector == nil && client.metrics == nil {
+		return ctx, nil
+	}
+
+	timings := &connectionTimings{}
+	return httptrace.WithClientTrace(ctx, timings.clientTrace()), timings
+}
+
+func (timings *connectionTimings) clientTrace() *httptrace.ClientTrace {
+	start := func(t *time.Time) {
+		timings.mu.Lock()
+		defer timings.mu.Unlock()
+		*t = time.Now()
+	}
+	done := func(start *time.Time, d *time.Duration) {
+		timings.mu.Lock()
+		defer timings.mu.Unlock()
+		if !start.IsZero() {
+			*d = time.Since(*start)
+		}
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { start(&timings.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { done(&timings.dnsStart, &timings.dns) },
+		ConnectStart:      func(string, string) { start(&timings.connectStart) },
+		ConnectDone:       func(string, string, error) { done(&timings.connectStart, &timings.connect) },
+		TLSHandshakeStart: func() { start(&timings.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { done(&timings.tlsStart, &timings.tlsHandshake) },
+	}
+}
+
+// apply copies the measured timings into diagnostics. It does nothing if
+// timings is nil.
+func (timings *connectionTimings) apply(diagnostics *RequestDiagnostics) {
+	if timings == nil {
+		return
+	}
+
+	timings.mu.Lock()
+	defer timings.mu.Unlock()
+	diagnostics.DNS = timings.dns
+	diagnostics.Connect = timings.connect
+	diagnostics.TLSHandshake = timings.tlsHandshake
+}
+
+
+func (client *Client) collectDiagnostics(diagnostics RequestDiagnostics) {
+	client.recordRequest(diagnostics)
+	if client.diagnosticsCollector == nil {
+		return
+	}
+
+	client.diagnosticsCollector.CollectRequestDiagnostics(diagnostics)
+}
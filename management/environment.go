@@ -0,0 +1,95 @@
+package management
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Environment describes an Azure cloud: the endpoint of its management API and
+// the DNS suffix of its storage services. The public cloud and the sovereign
+// clouds are predefined; other environments, such as Azure Stack, can be
+// described with a literal.
+type Environment struct {
+	Name string
+
+	// ManagementURL is the endpoint of the service management API, without
+	// a trailing slash.
+	ManagementURL string
+
+	// StorageEndpointSuffix is the DNS suffix of the storage services, as in
+	// account.blob.<suffix>. It is also the base URL expected by the
+	// storage package.
+	StorageEndpointSuffix string
+
+	// ActiveDirectoryEndpoint is the Azure Active Directory endpoint that
+	// issues tokens for the management API, without a trailing slash.
+	ActiveDirectoryEndpoint string
+}
+
+var (
+	PublicCloud = Environment{
+		Name:                    "AzureCloud",
+		ManagementURL:           "https://management.core.windows.net",
+		StorageEndpointSuffix:   "core.windows.net",
+		ActiveDirectoryEndpoint: "https://login.microsoftonline.com",
+	}
+	ChinaCloud = Environment{
+		Name:                    "AzureChinaCloud",
+		ManagementURL:           "https://management.core.chinacloudapi.cn",
+		StorageEndpointSuffix:   "core.chinacloudapi.cn",
+		ActiveDirectoryEndpoint: "https://login.chinacloudapi.cn",
+	}
+	GermanCloud = Environment{
+		Name:                    "AzureGermanCloud",
+		ManagementURL:           "https://management.core.cloudapi.de",
+		StorageEndpointSuffix:   "core.cloudapi.de",
+		ActiveDirectoryEndpoint: "https://login.microsoftonline.de",
+	}
+	USGovernmentCloud = Environment{
+		Name:                    "AzureUSGovernment",
+		ManagementURL:           "https://management.core.usgovcloudapi.net",
+		StorageEndpointSuffix:   "core.usgovcloudapi.net",
+		ActiveDirectoryEndpoint: "https://login.microsoftonline.us",
+	}
+)
+
+// Environments lists the predefined environments.
+var Environments = []Environment{PublicCloud, ChinaCloud, GermanCloud, USGovernmentCloud}
+
+// StorageEndpoint returns the https endpoint of the given storage service
+// (blob, queue, table or file) of a storage account, with a trailing slash.
+func (env Environment) StorageEndpoint(accountName, service string) string {
+	return fmt.Sprintf("https://%s.%s.%s/", accountName, service, env.StorageEndpointSuffix)
+}
+
+// BlobEndpoint returns the https endpoint of the blob service of a storage
+// account, with a trailing slash.
+func (env Environment) BlobEndpoint(accountName string) string {
+	return env.StorageEndpoint(accountName, "blob")
+}
+
+// Environment returns the environment the client was configured for.
+func (client *Client) Environment() Environment {
+	return client.environment
+}
+
+// resolveEnvironment returns the environment of config: config.Environment if
+// set, otherwise the predefined environment whose management endpoint has the
+// host of config.ManagementURL, or the public cloud.
+func resolveEnvironment(config ClientConfig) Environment {
+	if config.Environment != (Environment{}) {
+		return config.Environment
+	}
+
+	if managementURL, err := url.Parse(config.ManagementURL); err == nil {
+		for _, env := range Environments {
+			envURL, _ := url.Parse(env.ManagementURL)
+			if strings.EqualFold(managementURL.Host, envURL.Host) {
+				return env
+			}
+		}
+	}
+
+	return PublicCloud
+}
@@ -0,0 +1,60 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveEnvironment(t *testing.T) {
+	tests := []struct {
+		config   ClientConfig
+		expected string
+	}{
+		{ClientConfig{}, "AzureCloud"},
+		{ClientConfig{ManagementURL: "https://management.core.chinacloudapi.cn/"}, "AzureChinaCloud"},
+		{ClientConfig{ManagementURL: "https://MANAGEMENT.core.cloudapi.de"}, "AzureGermanCloud"},
+		{ClientConfig{ManagementURL: "http://127.0.0.1:8080"}, "AzureCloud"},
+		{ClientConfig{Environment: USGovernmentCloud, ManagementURL: "http://127.0.0.1:8080"}, "AzureUSGovernment"},
+	}
+
+	for _, test := range tests {
+		if actual := resolveEnvironment(test.config).Name; actual != test.expected {
+			t.Errorf("%+v: expected environment %s, got %s", test.config, test.expected, actual)
+		}
+	}
+}
+
+func TestClientUsesEnvironmentManagementURL(t *testing.T) {
+	client, err := NewClientFromConfig("subscriptionID", testManagementCert(t), ClientConfig{Environment: ChinaCloud})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := client.createAzureRequest("services/storageservices", "GET", "", "2014-05-01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "https://management.core.chinacloudapi.cn/subscriptionID/services/storageservices"; request.URL.String() != expected {
+		t.Errorf("Expected request to %s, got %s", expected, request.URL)
+	}
+	if expected := "https://account.blob.core.chinacloudapi.cn/"; client.Environment().BlobEndpoint("account") != expected {
+		t.Errorf("Expected blob endpoint %s, got %s", expected, client.Environment().BlobEndpoint("account"))
+	}
+}
+
+func TestManagementURLOverridesEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{Environment: GermanCloud})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if client.Environment().Name != "AzureGermanCloud" {
+		t.Errorf("Expected the German cloud environment, got %+v", client.Environment())
+	}
+}
@@ -0,0 +1,218 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	errorCodeResourceNotFound = "ResourceNotFound"
+	errorCodeConflict         = "ConflictError"
+)
+
+// ErrorHandler is called with every error of a request that failed after
+// its retries, and of every asynchronous operation that failed or timed out
+// while being waited on, for example to report them to an error tracker. The
+// errors are returned to the callers as well: the client never terminates
+// the process, so that it can be used inside long-running services.
+type ErrorHandler func(err error)
+
+// reportError calls the ErrorHandler of the client, if any, with err.
+func (client *Client) reportError(err error) {
+	if client.errorHandler != nil {
+		client.errorHandler(err)
+	}
+}
+
+// IsNotFound reports whether err, or any error it wraps, is an AzureError
+// saying that the requested resource does not exist.
+func IsNotFound(err error) bool {
+	return hasErrorCode(err, errorCodeResourceNotFound) || HTTPStatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err, or any error it wraps, is an AzureError
+// saying that the request conflicts with the current state of a resource,
+// for example because a name is already taken.
+func IsConflict(err error) bool {
+	return hasErrorCode(err, errorCodeConflict) || HTTPStatusCode(err) == http.StatusConflict
+}
+
+// IsPreconditionFailed reports whether err, or any error it wraps, is an
+// AzureError saying that a conditional request, see Client.WithIfMatch, was
+// rejected because the resource changed.
+func IsPreconditionFailed(err error) bool {
+	return HTTPStatusCode(err) == http.StatusPreconditionFailed
+}
+
+// IsTemporary reports whether err, or any error it wraps, is a failure that
+// may not happen again, so that the request that failed can be retried
+// later: a throttled request, see IsThrottled, a server error or timeout
+// reported by the service, or a transient network error, see
+// IsTransientNetworkError.
+func IsTemporary(err error) bool {
+	if IsThrottled(err) || IsTransientNetworkError(err) {
+		return true
+	}
+
+	switch HTTPStatusCode(err) {
+	case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// statusCodeError is implemented by the errors of the other packages of the
+// SDK that describe an error response, such as storage.StorageServiceError,
+// so that the helpers of this package classify them too.
+type statusCodeError interface {
+	error
+	HTTPStatusCode() int
+}
+
+// HTTPStatusCode returns the HTTP status of the response that err, or any
+// error it wraps, was decoded from, or zero if err does not describe an error
+// response.
+func HTTPStatusCode(err error) int {
+	var azureErr *AzureError
+	if errors.As(err, &azureErr) {
+		return azureErr.StatusCode
+	}
+
+	var statusErr statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+// RequestID returns the x-ms-request-id of the response that err, or any
+// error it wraps, was decoded from, or an empty string if err is not an
+// AzureError.
+func RequestID(err error) string {
+	var azureErr *AzureError
+	if !errors.As(err, &azureErr) {
+		return ""
+	}
+
+	return azureErr.RequestID
+}
+
+func hasErrorCode(err error, code string) bool {
+	var azureErr *AzureError
+	if !errors.As(err, &azureErr) {
+		return false
+	}
+
+	return azureErr.Code == code
+}
+
+// OperationError annotates an error returned by a service sub-package with
+// the operation that failed and the resource it was applied to. It wraps the
+// original error, so IsNotFound, IsConflict and errors.As see through it.
+type OperationError struct {
+	Service   string
+	Operation string
+	Resource  string
+	Err       error
+}
+
+// Error implements the error interface for the OperationError type. The
+// message reads like service.Operation(resource): original error.
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s.%s(%s): %s", e.Service, e.Operation, e.Resource, e.Err)
+}
+
+// Unwrap returns the error that caused the operation to fail.
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError wraps err in an *OperationError describing the failed operation.
+// It returns nil if err is nil, and returns err unchanged if it already is an
+// *OperationError, so that the innermost, most specific context is kept when
+// one sub-client operation is implemented in terms of another.
+func WrapError(service, operation, resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*OperationError); ok {
+		return err
+	}
+
+	return &OperationError{
+		Service:   service,
+		Operation: operation,
+		Resource:  resource,
+		Err:       err,
+	}
+}
+
+// AbortedOperationError is returned when a wait for an asynchronous operation
+// ends before the operation reached a terminal state, because the wait timed
+// out or was cancelled. The operation keeps running on the server, so its ID
+// should be kept to resume waiting for it later. Resource names the resource
+// the operation was creating or modifying, when the caller knows it.
+type AbortedOperationError struct {
+	OperationID string
+	Resource    string
+	Err         error
+}
+
+// Error implements the error interface for the AbortedOperationError type.
+func (e *AbortedOperationError) Error() string {
+	if e.Resource == "" {
+		return fmt.Sprintf("Wait for Azure operation %s was aborted: %s", e.OperationID, e.Err)
+	}
+	return fmt.Sprintf("Wait for Azure operation %s on %s was aborted: %s", e.OperationID, e.Resource, e.Err)
+}
+
+// Unwrap returns the reason the wait was aborted, such as context.Canceled.
+func (e *AbortedOperationError) Unwrap() error {
+	return e.Err
+}
+
+// FailedOperationError is returned when an asynchronous operation ends in the
+// Failed state. It wraps the error reported by the operation as an AzureError
+// whose RequestID is the ID of the operation, so IsConflict, RequestID and
+// errors.As see through it.
+type FailedOperationError struct {
+	OperationID string
+	Err         *AzureError
+}
+
+// Error implements the error interface for the FailedOperationError type.
+func (e *FailedOperationError) Error() string {
+	return fmt.Sprintf("Azure operation %s failed. Code: %s, Message: %s", e.OperationID, e.Err.Code, e.Err.Message)
+}
+
+// Unwrap returns the error reported by the operation.
+func (e *FailedOperationError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is the reason of the AbortedOperationError returned when a wait
+// for an operation exceeds its timeout. It describes the last known state of
+// the operation.
+type TimeoutError struct {
+	OperationID string
+	Timeout     time.Duration
+
+	// LastStatus is the status of the operation at the last status check,
+	// InProgress unless the operation was never checked, and Polls the
+	// number of status checks made.
+	LastStatus string
+	Polls      int
+}
+
+// Error implements the error interface for the TimeoutError type.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf(errOperationTimeout, e.OperationID, e.Timeout, e.LastStatus)
+}
+
+// IsTimeout reports whether err, or any error it wraps, is a TimeoutError.
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
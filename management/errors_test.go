@@ -0,0 +1,211 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestOperationErrorMessage(t *testing.T) {
+	err := WrapError("storageservice", "CreateStorageService", "myaccount", errors.New("ConflictError: The specified DNS name is already taken."))
+
+	expected := "storageservice.CreateStorageService(myaccount): ConflictError: The specified DNS name is already taken."
+	if err.Error() != expected {
+		t.Fatalf("Wrong error message. Expected: '%s', got: '%s'", expected, err.Error())
+	}
+}
+
+func TestWrapErrorNil(t *testing.T) {
+	if err := WrapError("storageservice", "GetStorageServiceList", "", nil); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+}
+
+func TestWrapErrorKeepsInnermostOperation(t *testing.T) {
+	inner := WrapError("storageservice", "GetStorageServiceByName", "myaccount", errors.New("failure"))
+	outer := WrapError("storageservice", "EnsureStorageService", "myaccount", inner)
+
+	if outer != inner {
+		t.Fatalf("Expected the inner operation error to be kept, got %v", outer)
+	}
+}
+
+func TestPredicatesSeeThroughOperationError(t *testing.T) {
+	notFound := WrapError("storageservice", "GetStorageServiceByName", "myaccount", &AzureError{Code: "ResourceNotFound"})
+	conflict := WrapError("storageservice", "CreateStorageService", "myaccount", &AzureError{Code: "ConflictError"})
+
+	if !IsNotFound(notFound) || IsConflict(notFound) {
+		t.Fatalf("Wrong classification of %v", notFound)
+	}
+	if !IsConflict(conflict) || IsNotFound(conflict) {
+		t.Fatalf("Wrong classification of %v", conflict)
+	}
+
+	var azureErr *AzureError
+	if !errors.As(conflict, &azureErr) || azureErr.Code != "ConflictError" {
+		t.Fatalf("Expected errors.As to find the AzureError in %v", conflict)
+	}
+
+	var operationErr *OperationError
+	if !errors.As(conflict, &operationErr) || operationErr.Operation != "CreateStorageService" || operationErr.Resource != "myaccount" {
+		t.Fatalf("Expected errors.As to find the OperationError in %v", conflict)
+	}
+}
+
+func TestAzureErrorDescribesResponse(t *testing.T) {
+	body := "<Error><Code>ResourceNotFound</Code><Message>The hosted service does not exist.</Message></Error>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-request-id", "request-1")
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+	_, err := client.SendAzureDeleteRequest("services/hostedservices/missing")
+
+	var azureErr *AzureError
+	if !errors.As(err, &azureErr) {
+		t.Fatalf("Expected an AzureError, got %v", err)
+	}
+	expected := AzureError{
+		XMLName:    azureErr.XMLName,
+		Code:       "ResourceNotFound",
+		Message:    "The hosted service does not exist.",
+		StatusCode: http.StatusNotFound,
+		RequestID:  "request-1",
+		Method:     "DELETE",
+		URL:        server.URL + "/subscriptionID/services/hostedservices/missing",
+		Body:       []byte(body),
+	}
+	if fmt.Sprint(*azureErr) != fmt.Sprint(expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *azureErr)
+	}
+
+	wrapped := WrapError("hostedservice", "DeleteHostedService", "missing", err)
+	if !IsNotFound(wrapped) || HTTPStatusCode(wrapped) != http.StatusNotFound || RequestID(wrapped) != "request-1" {
+		t.Errorf("Expected the helpers to see through %v", wrapped)
+	}
+	if !strings.Contains(err.Error(), "request ID request-1") {
+		t.Errorf("Expected the message to contain the request ID, got %q", err.Error())
+	}
+}
+
+func TestIsConflictFromStatusCode(t *testing.T) {
+	err := &AzureError{Code: "SomethingElse", StatusCode: http.StatusConflict}
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Errorf("Wrong classification of %v", err)
+	}
+}
+
+func TestAzureErrorFromNonXMLBody(t *testing.T) {
+	bodies := map[string]string{
+		"<html>\n<body><h1>502 Bad Gateway</h1></body>\n</html>": "<html> <body><h1>502 Bad Gateway</h1></body> </html>",
+		"":                       errEmptyErrorBody,
+		strings.Repeat("x", 300): strings.Repeat("x", maxErrorSnippet) + "...",
+	}
+
+	for body, message := range bodies {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(body))
+		}))
+
+		client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+		_, err := client.SendAzureGetRequest("services/hostedservices")
+		server.Close()
+
+		var azureErr *AzureError
+		if !errors.As(err, &azureErr) {
+			t.Fatalf("Expected an AzureError for body %q, got %v", body, err)
+		}
+		if azureErr.Code != "Bad Gateway" || azureErr.Message != message || azureErr.StatusCode != http.StatusBadGateway {
+			t.Errorf("Unexpected error for body %q: %+v", body, azureErr)
+		}
+		if string(azureErr.Body) != body {
+			t.Errorf("Expected the raw body to be kept, got %q", azureErr.Body)
+		}
+	}
+}
+
+// storageError stands for the errors of other packages describing an error
+// response, such as storage.StorageServiceError.
+type storageError struct {
+	statusCode int
+}
+
+func (e storageError) Error() string       { return fmt.Sprintf("storage error %d", e.statusCode) }
+func (e storageError) HTTPStatusCode() int { return e.statusCode }
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		err                                      error
+		temporary, throttled, conflict, notFound bool
+	}{
+		{&AzureError{Code: "ResourceNotFound"}, false, false, false, true},
+		{&AzureError{StatusCode: http.StatusConflict}, false, false, true, false},
+		{&AzureError{StatusCode: http.StatusServiceUnavailable}, true, true, false, false},
+		{&AzureError{StatusCode: http.StatusInternalServerError}, true, false, false, false},
+		{&AzureError{StatusCode: http.StatusBadRequest}, false, false, false, false},
+		{&ThrottledError{StatusCode: 429}, true, true, false, false},
+		{&url.Error{Op: "Get", URL: "https://management.core.windows.net", Err: syscall.ECONNRESET}, true, false, false, false},
+		{WrapError("storageservice", "GetStorageServiceByName", "account", storageError{http.StatusNotFound}), false, false, false, true},
+		{fmt.Errorf("listing blobs: %w", storageError{http.StatusGatewayTimeout}), true, false, false, false},
+		{errors.New("ConflictError"), false, false, false, false},
+	}
+
+	for _, test := range tests {
+		if IsTemporary(test.err) != test.temporary || IsThrottled(test.err) != test.throttled ||
+			IsConflict(test.err) != test.conflict || IsNotFound(test.err) != test.notFound {
+			t.Errorf("Wrong classification of %v", test.err)
+		}
+	}
+}
+
+func TestErrorHandlerIsToldOfFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "<Operation><ID>operation</ID><Status>Failed</Status><Error><Code>Conflict</Code><Message>No way</Message></Error></Operation>"
+		status := http.StatusOK
+		if !strings.Contains(r.URL.Path, "/operations/") {
+			body = "<Error><Code>ResourceNotFound</Code><Message>Missing</Message></Error>"
+			status = http.StatusNotFound
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var reported []error
+	client := newTestClient(t, server.URL, ClientConfig{
+		RetryPolicy:         &RetryPolicy{MaxAttempts: 1},
+		DefaultPollInterval: time.Millisecond,
+		ErrorHandler: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+
+	_, getErr := client.SendAzureGetRequest("services/hostedservices/missing")
+	waitErr := client.WaitAsyncOperation("operation")
+	if getErr == nil || waitErr == nil {
+		t.Fatalf("Expected both calls to fail, got %v and %v", getErr, waitErr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 2 || reported[0] != getErr || reported[1] != waitErr {
+		t.Errorf("Expected the handler to be told of %v and %v, got %v", getErr, waitErr, reported)
+	}
+}
@@ -0,0 +1,40 @@
+package management
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// ETag returns the entity tag of the resource the response describes, or an
+// empty string if the service sent none.
+func (response *AzureResponse) ETag() string {
+	return response.Header.Get(etagHeader)
+}
+
+// WithIfMatch returns a copy of the client whose requests are conditional on
+// the resource they address still having the entity tag etag, as returned
+// with the resource by an earlier GET. If the resource was changed since, the
+// service rejects the request, and IsPreconditionFailed holds for the error,
+// instead of overwriting the change. An empty etag makes the requests
+// unconditional again.
+//
+//	storageService, err := storageClient.GetStorageServiceByName(name)
+//	...
+//	conditional := client.WithIfMatch(storageService.ETag)
+func (client Client) WithIfMatch(etag string) Client {
+	client.ifMatch = etag
+	return client
+}
+
+// IfMatch returns client with WithIfMatch applied, if client is a *Client.
+// Other implementations are returned unchanged. The service sub-packages use
+// it for the updates of resources carrying an ETag.
+func IfMatch(client ManagementClient, etag string) ManagementClient {
+	azureClient, ok := client.(*Client)
+	if !ok || azureClient == nil || etag == "" {
+		return client
+	}
+
+	conditional := azureClient.WithIfMatch(etag)
+	return &conditional
+}
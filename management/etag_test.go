@@ -0,0 +1,51 @@
+package management
+
+import (
+	"fmt"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestWithIfMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"2"`)
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != `"2"` {
+			body := "<Error><Code>PreconditionFailed</Code><Message>Changed</Message></Error>"
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+	response, err := client.SendAzureRequest("services/networking/media", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag := response.ETag(); etag != `"2"` {
+		t.Fatalf(`Expected ETag "2", got %s`, etag)
+	}
+
+	current := client.WithIfMatch(response.ETag())
+	if _, err := current.SendAzurePutRequest("services/networking/media", "text/plain", []byte("<x/>")); err != nil {
+		t.Errorf("Expected the update with the current ETag to succeed, got %v", err)
+	}
+
+	stale := client.WithIfMatch(`"1"`)
+	if _, err := stale.SendAzurePutRequest("services/networking/media", "text/plain", []byte("<x/>")); !IsPreconditionFailed(err) {
+		t.Errorf("Expected the update with a stale ETag to fail its precondition, got %v", err)
+	}
+
+	if _, err := client.SendAzurePutRequest("services/networking/media", "text/plain", []byte("<x/>")); err != nil {
+		t.Errorf("Expected WithIfMatch to leave the original client unconditional, got %v", err)
+	}
+	if IfMatch(&client, "").(*Client) != &client {
+		t.Errorf("Expected IfMatch with an empty ETag to return the client unchanged")
+	}
+}
@@ -0,0 +1,250 @@
+// Package fake provides an in-memory implementation of
+// management.ManagementClient, serving canned XML responses, for unit testing
+// code built on the service sub-packages without Azure credentials.
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+// Request is a request received by a fake Client.
+type Request struct {
+	Method      string
+	URL         string
+	ContentType string
+	Body        []byte
+}
+
+// Response is a canned response of a fake Client. Body is returned by GET
+// requests and RequestID by the other methods; if Err is set, it is returned
+// instead. ETag is the ETag header of the response returned by
+// SendAzureRequest.
+type Response struct {
+	Body      []byte
+	RequestID string
+	ETag      string
+	Err       error
+}
+
+// Client is a fake management client. Responses are registered by method and
+// URL, the URL being relative to the subscription as in the sub-packages, for
+// example "services/storageservices". Requests without a registered response
+// fail with a ResourceNotFound error, so management.IsNotFound holds for
+// them. A Client is safe for concurrent use.
+type Client struct {
+	// Routes overrides the routes of the client, as in
+	// management.ClientConfig.
+	Routes management.RouteTable
+
+	// Env is the environment reported by the client. If not set, it is
+	// management.PublicCloud.
+	Env management.Environment
+
+	mu         sync.Mutex
+	responses  map[string][]Response
+	operations map[string]error
+	requests   []Request
+	requestIDs int
+}
+
+// NewClient returns a fake client without any responses.
+func NewClient() *Client {
+	return &Client{
+		responses:  make(map[string][]Response),
+		operations: make(map[string]error),
+	}
+}
+
+// AddResponse registers the XML body returned by the next GET request to url,
+// or the request ID returned by the next request with another method. Several
+// responses registered for the same request are returned in order, and the
+// last one is repeated.
+func (client *Client) AddResponse(method string, url string, body string) {
+	response := Response{Body: []byte(body)}
+	if method != "GET" {
+		response = Response{RequestID: body}
+	}
+
+	client.Add(method, url, response)
+}
+
+// AddError registers err as the result of the next request with the given
+// method and url. See management.AzureError for errors like the service
+// returns.
+func (client *Client) AddError(method string, url string, err error) {
+	client.Add(method, url, Response{Err: err})
+}
+
+// Add registers response for the next request with the given method and url.
+func (client *Client) Add(method string, url string, response Response) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	key := requestKey(method, url)
+	client.responses[key] = append(client.responses[key], response)
+}
+
+// SetOperationResult sets the result of waiting for the operation with the
+// given ID. Operations succeed by default.
+func (client *Client) SetOperationResult(operationID string, err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	client.operations[operationID] = err
+}
+
+// Requests returns the requests received so far, in order.
+func (client *Client) Requests() []Request {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	return append([]Request(nil), client.requests...)
+}
+
+// SendAzureRequest implements management.ManagementClient.
+func (client *Client) SendAzureRequest(url string, requestType string, contentType string, data []byte) (*management.AzureResponse, error) {
+	response := client.respond(Request{Method: strings.ToUpper(requestType), URL: url, ContentType: contentType, Body: data})
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	statusCode := http.StatusOK
+	if response.RequestID != "" && strings.ToUpper(requestType) != "GET" {
+		statusCode = http.StatusAccepted
+	}
+	header := http.Header{}
+	if response.RequestID != "" {
+		header.Set("x-ms-request-id", response.RequestID)
+	}
+	if response.ETag != "" {
+		header.Set("ETag", response.ETag)
+	}
+
+	return &management.AzureResponse{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       response.Body,
+		RequestID:  response.RequestID,
+	}, nil
+}
+
+// SendAzureGetRequest implements management.ManagementClient.
+func (client *Client) SendAzureGetRequest(url string) ([]byte, error) {
+	response := client.respond(Request{Method: "GET", URL: url})
+	return response.Body, response.Err
+}
+
+// SendAzurePostRequest implements management.ManagementClient.
+func (client *Client) SendAzurePostRequest(url string, data []byte) (string, error) {
+	response := client.respond(Request{Method: "POST", URL: url, Body: data})
+	return response.RequestID, response.Err
+}
+
+// SendAzurePutRequest implements management.ManagementClient.
+func (client *Client) SendAzurePutRequest(url string, contentType string, data []byte) (string, error) {
+	response := client.respond(Request{Method: "PUT", URL: url, ContentType: contentType, Body: data})
+	return response.RequestID, response.Err
+}
+
+// SendAzureDeleteRequest implements management.ManagementClient.
+func (client *Client) SendAzureDeleteRequest(url string) (string, error) {
+	response := client.respond(Request{Method: "DELETE", URL: url})
+	return response.RequestID, response.Err
+}
+
+// SendAzurePutRequestAndWait implements management.ManagementClient.
+func (client *Client) SendAzurePutRequestAndWait(url string, contentType string, data []byte, options ...management.WaitOption) error {
+	requestID, err := client.SendAzurePutRequest(url, contentType, data)
+	if err != nil {
+		return err
+	}
+
+	return client.WaitAsyncOperation(requestID, options...)
+}
+
+// SendAzureDeleteRequestAndWait implements management.ManagementClient.
+func (client *Client) SendAzureDeleteRequestAndWait(url string, options ...management.WaitOption) error {
+	requestID, err := client.SendAzureDeleteRequest(url)
+	if err != nil {
+		return err
+	}
+
+	return client.WaitAsyncOperation(requestID, options...)
+}
+
+// WaitAsyncOperation implements management.ManagementClient. It returns
+// immediately, with the result set with SetOperationResult.
+func (client *Client) WaitAsyncOperation(operationId string, options ...management.WaitOption) error {
+	if operationId == "" {
+		return fmt.Errorf("Parameter %s is not specified.", "operationId")
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.operations[operationId]
+}
+
+// Route implements management.ManagementClient.
+func (client *Client) Route(name string, args ...interface{}) string {
+	template, ok := client.Routes[name]
+	if !ok {
+		template = management.DefaultRoutes()[name]
+	}
+	if template == "" {
+		return ""
+	}
+
+	return management.FormatRoute(template, args...)
+}
+
+// Environment implements management.ManagementClient.
+func (client *Client) Environment() management.Environment {
+	if client.Env == (management.Environment{}) {
+		return management.PublicCloud
+	}
+
+	return client.Env
+}
+
+// respond records request and returns its canned response. Requests other
+// than GET without a canned request ID get a generated one.
+func (client *Client) respond(request Request) Response {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	client.requests = append(client.requests, request)
+
+	key := requestKey(request.Method, request.URL)
+	queue := client.responses[key]
+	if len(queue) == 0 {
+		return Response{Err: &management.AzureError{
+			Code:       "ResourceNotFound",
+			Message:    fmt.Sprintf("No fake response for %s.", key),
+			StatusCode: http.StatusNotFound,
+			Method:     request.Method,
+			URL:        request.URL,
+		}}
+	}
+
+	response := queue[0]
+	if len(queue) > 1 {
+		client.responses[key] = queue[1:]
+	}
+	if request.Method != "GET" && response.Err == nil && response.RequestID == "" {
+		client.requestIDs++
+		response.RequestID = fmt.Sprintf("fake-request-%d", client.requestIDs)
+	}
+
+	return response
+}
+
+func requestKey(method string, url string) string {
+	return strings.ToUpper(method) + " " + url
+}
+
+var _ management.ManagementClient = (*Client)(nil)
@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+func TestClientServesCannedResponsesInOrder(t *testing.T) {
+	client := NewClient()
+	client.AddResponse("GET", "services/disks", "<Disks/>")
+	client.AddResponse("GET", "services/disks", "<Disks><Disk/></Disks>")
+	client.AddResponse("POST", "services/hostedservices", "request-1")
+
+	for _, expected := range []string{"<Disks/>", "<Disks><Disk/></Disks>", "<Disks><Disk/></Disks>"} {
+		body, err := client.SendAzureGetRequest("services/disks")
+		if err != nil || string(body) != expected {
+			t.Errorf("Expected %s, got %s, %v", expected, body, err)
+		}
+	}
+
+	if requestID, err := client.SendAzurePostRequest("services/hostedservices", []byte("<Input/>")); err != nil || requestID != "request-1" {
+		t.Errorf("Expected request-1, got %s, %v", requestID, err)
+	}
+
+	requests := client.Requests()
+	if len(requests) != 4 || requests[3].Method != "POST" || string(requests[3].Body) != "<Input/>" {
+		t.Errorf("Unexpected requests %+v", requests)
+	}
+}
+
+func TestClientFailsUnknownRequestsAsNotFound(t *testing.T) {
+	client := NewClient()
+	if _, err := client.SendAzureGetRequest("services/disks/missing"); !management.IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	failure := errors.New("boom")
+	client.AddError("DELETE", "services/disks/disk", failure)
+	if err := client.SendAzureDeleteRequestAndWait("services/disks/disk"); err != failure {
+		t.Errorf("Expected the canned error, got %v", err)
+	}
+}
+
+func TestClientOperationResults(t *testing.T) {
+	client := NewClient()
+	client.AddResponse("DELETE", "services/disks/disk", "")
+	client.AddResponse("PUT", "services/networking/media", "network-update")
+
+	if err := client.SendAzureDeleteRequestAndWait("services/disks/disk"); err != nil {
+		t.Errorf("Expected operations to succeed by default, got %v", err)
+	}
+
+	failure := errors.New("operation failed")
+	client.SetOperationResult("network-update", failure)
+	if err := client.SendAzurePutRequestAndWait("services/networking/media", "text/plain", nil); err != failure {
+		t.Errorf("Expected the operation result, got %v", err)
+	}
+
+	if route := client.Route(management.RouteStorageService, "account"); route != "services/storageservices/account" {
+		t.Errorf("Expected the default route, got %s", route)
+	}
+}
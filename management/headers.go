@@ -0,0 +1,60 @@
+package management
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithHeader returns a copy of the client that sends the header name with
+// value on every request, for the operations needing headers the client does
+// not set itself. It replaces any value the client would set for the header,
+// except for the authorization of the request. The returned client can be
+// given to the service sub-packages:
+//
+//	client = client.WithHeader("x-ms-continuation-token", token)
+//	list, err := storageservice.NewClient(client).GetStorageServiceList()
+func (client Client) WithHeader(name, value string) Client {
+	headers := make(http.Header, len(client.headers)+1)
+	for key, values := range client.headers {
+		headers[key] = append([]string(nil), values...)
+	}
+	headers.Set(name, value)
+
+	client.headers = headers
+	return client
+}
+
+// WithQueryParameter returns a copy of the client that adds the query
+// parameter name with value to the URL of every request, after the ones of
+// the URL itself.
+func (client Client) WithQueryParameter(name, value string) Client {
+	query := make(url.Values, len(client.query)+1)
+	for key, values := range client.query {
+		query[key] = append([]string(nil), values...)
+	}
+	query.Add(name, value)
+
+	client.query = query
+	return client
+}
+
+// addQuery returns url with the query parameters of the client appended.
+func (client *Client) addQuery(url string) string {
+	if len(client.query) == 0 {
+		return url
+	}
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return url + separator + client.query.Encode()
+}
+
+// setHeaders sets the headers of the client on request.
+func (client *Client) setHeaders(request *http.Request) {
+	for name, values := range client.headers {
+		request.Header[name] = append([]string(nil), values...)
+	}
+}
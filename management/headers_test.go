@@ -0,0 +1,49 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClientSendsCustomHeadersAndQueryParameters(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	custom := client.WithHeader("x-ms-continuation-token", "token").
+		WithHeader("Content-Type", "text/plain").
+		WithQueryParameter("embed-detail", "true")
+
+	if _, err := custom.SendAzureGetRequest("services/images?category=Public"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SendAzureGetRequest("services/images"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	customRequest, plainRequest := requests[0], requests[1]
+	if token := customRequest.Header.Get("x-ms-continuation-token"); token != "token" {
+		t.Errorf("Expected the custom header, got %q", token)
+	}
+	if contentType := customRequest.Header.Get("Content-Type"); contentType != "text/plain" {
+		t.Errorf("Expected the custom header to replace the client's, got %q", contentType)
+	}
+	if query := customRequest.URL.RawQuery; query != "category=Public&embed-detail=true" {
+		t.Errorf("Expected the custom query parameter after the URL's, got %q", query)
+	}
+
+	if plainRequest.Header.Get("x-ms-continuation-token") != "" || plainRequest.URL.RawQuery != "" {
+		t.Errorf("Expected the original client to be unchanged, got %v with query %q", plainRequest.Header, plainRequest.URL.RawQuery)
+	}
+}
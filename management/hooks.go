@@ -0,0 +1,42 @@
+package management
+
+import (
+	"net/http"
+)
+
+// RequestHook is called with every request the client sends, including
+// retries and the requests of the service sub-packages, after the client has
+// set its headers and before the request is sent. Hooks may modify the
+// request, for example to add headers or sign it. An error aborts the attempt
+// as if the transport had failed, so it is subject to the RetryPolicy.
+type RequestHook func(request *http.Request) error
+
+// ResponseHook is called with every response the client receives, before
+// its body is read. Hooks may inspect or replace the response, for example
+// for audit logging or fault injection. An error fails the attempt with the
+// status code of the response.
+type ResponseHook func(request *http.Request, response *http.Response) error
+
+// runRequestHooks calls the request hooks of the client in order, stopping at
+// the first error.
+func (client *Client) runRequestHooks(request *http.Request) error {
+	for _, hook := range client.requestHooks {
+		if err := hook(request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runResponseHooks calls the response hooks of the client in order, stopping
+// at the first error.
+func (client *Client) runResponseHooks(request *http.Request, response *http.Response) error {
+	for _, hook := range client.responseHooks {
+		if err := hook(request, response); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHooksSeeEveryAttempt(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if r.Header.Get("X-Custom") != "value" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	var attempts, responses int
+	client := newTestClient(t, server.URL, ClientConfig{
+		RequestHooks: []RequestHook{
+			func(request *http.Request) error {
+				request.Header.Set("X-Custom", "value")
+				return nil
+			},
+			func(request *http.Request) error {
+				attempts++
+				if attempts == 1 {
+					return errors.New("injected fault")
+				}
+				return nil
+			},
+		},
+		ResponseHooks: []ResponseHook{
+			func(request *http.Request, response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					t.Errorf("Expected the custom header to reach the server, got status %d", response.StatusCode)
+				}
+				responses++
+				return nil
+			},
+		},
+	})
+
+	copied := client
+	if _, err := copied.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || responses != 1 || requests != 1 {
+		t.Errorf("Expected the injected fault to be retried, got %d attempts, %d responses and %d requests", attempts, responses, requests)
+	}
+}
+
+func TestResponseHookErrorFailsAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	hookErr := errors.New("rejected by audit")
+	client := newTestClient(t, server.URL, ClientConfig{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+		ResponseHooks: []ResponseHook{
+			func(request *http.Request, response *http.Response) error {
+				return hookErr
+			},
+		},
+	})
+
+	if _, err := client.SendAzureGetRequest("locations"); err != hookErr {
+		t.Errorf("Expected the hook error, got %v", err)
+	}
+}
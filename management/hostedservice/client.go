@@ -1,39 +1,51 @@
 package hostedservice
 
 import (
-	"encoding/base64"
 	"encoding/xml"
 	"fmt"
 
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/label"
 	locationclient "github.com/MSOpenTech/azure-sdk-for-go/management/location"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/validation"
 )
 
 const (
+	apiVersion                        = "2014-05-01"
 	azureXmlns                        = "http://schemas.microsoft.com/windowsazure"
 	azureDeploymentListURL            = "services/hostedservices/%s/deployments"
 	azureHostedServiceListURL         = "services/hostedservices"
-	deleteAzureHostedServiceURL       = "services/hostedservices/%s?comp=media"
 	azureHostedServiceAvailabilityURL = "services/hostedservices/operations/isavailable/%s"
 	azureDeploymentURL                = "services/hostedservices/%s/deployments/%s"
-	deleteAzureDeploymentURL          = "services/hostedservices/%s/deployments/%s?comp=media"
-	getHostedServicePropertiesURL     = "services/hostedservices/%s"
+	azureHostedServiceURL             = "services/hostedservices/%s"
 
 	errParamNotSpecified = "Parameter %s is not specified."
 )
 
 //NewClient is used to return a handle to the HostedService API
 func NewClient(client management.Client) HostedServiceClient {
-	return HostedServiceClient{client: client}
+	return NewClientFromManagementClient(&client)
 }
 
-func (self HostedServiceClient) CreateHostedService(dnsName, location string, reverseDnsFqdn string, label string, description string) (string, error) {
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) HostedServiceClient {
+	return HostedServiceClient{client: management.RequireAPIVersion(client, apiVersion)}
+}
+
+func (self HostedServiceClient) CreateHostedService(dnsName, location string, reverseDnsFqdn string, serviceLabel string, description string) (string, error) {
 	if dnsName == "" {
 		return "", fmt.Errorf(errParamNotSpecified, "dnsName")
 	}
 	if location == "" {
 		return "", fmt.Errorf(errParamNotSpecified, "location")
 	}
+	if err := validation.CloudServiceName(dnsName); err != nil {
+		return "", err
+	}
+	if err := validation.Label(serviceLabel); err != nil {
+		return "", err
+	}
 
 	result, reason, err := self.CheckHostedServiceNameAvailability(dnsName)
 	if err != nil {
@@ -43,13 +55,13 @@ func (self HostedServiceClient) CreateHostedService(dnsName, location string, re
 		return "", fmt.Errorf("%s Hosted service name: %s", reason, dnsName)
 	}
 
-	locationClient := locationclient.NewClient(self.client)
+	locationClient := locationclient.NewClientFromManagementClient(self.client)
 	err = locationClient.ResolveLocation(location)
 	if err != nil {
 		return "", err
 	}
 
-	hostedServiceDeployment := self.createHostedServiceDeploymentConfig(dnsName, location, reverseDnsFqdn, label, description)
+	hostedServiceDeployment := self.createHostedServiceDeploymentConfig(dnsName, location, reverseDnsFqdn, serviceLabel, description)
 	hostedServiceBytes, err := xml.Marshal(hostedServiceDeployment)
 	if err != nil {
 		return "", err
@@ -69,7 +81,7 @@ func (self HostedServiceClient) CheckHostedServiceNameAvailability(dnsName strin
 		return false, "", fmt.Errorf(errParamNotSpecified, "dnsName")
 	}
 
-	requestURL := fmt.Sprintf(azureHostedServiceAvailabilityURL, dnsName)
+	requestURL := management.NewURLBuilder(azureHostedServiceAvailabilityURL, dnsName).String()
 	response, err := self.client.SendAzureGetRequest(requestURL)
 	if err != nil {
 		return false, "", err
@@ -84,47 +96,41 @@ func (self HostedServiceClient) CheckHostedServiceNameAvailability(dnsName strin
 	return availabilityResponse.Result, availabilityResponse.Reason, nil
 }
 
-func (self HostedServiceClient) DeleteHostedService(dnsName string) error {
+func (self HostedServiceClient) DeleteHostedService(dnsName string, options ...management.WaitOption) error {
 	if dnsName == "" {
 		return fmt.Errorf(errParamNotSpecified, "dnsName")
 	}
 
-	requestURL := fmt.Sprintf(deleteAzureHostedServiceURL, dnsName)
-	requestId, err := self.client.SendAzureDeleteRequest(requestURL)
-	if err != nil {
-		return err
-	}
-
-	return self.client.WaitAsyncOperation(requestId)
+	requestURL := management.NewURLBuilder(azureHostedServiceURL, dnsName).Comp(management.CompMedia).String()
+	return self.client.SendAzureDeleteRequestAndWait(requestURL, options...)
 }
 
 func (self HostedServiceClient) GetHostedService(name string) (HostedService, error) {
 	hostedService := HostedService{}
 
-	requestURL := fmt.Sprintf(getHostedServicePropertiesURL, name)
-	response, err := self.client.SendAzureGetRequest(requestURL)
+	requestURL := management.NewURLBuilder(azureHostedServiceURL, name).String()
+	response, err := self.client.SendAzureRequest(requestURL, "GET", "", nil)
 	if err != nil {
 		return hostedService, err
 	}
 
-	err = xml.Unmarshal(response, &hostedService)
+	err = xml.Unmarshal(response.Body, &hostedService)
 	if err != nil {
 		return hostedService, err
 	}
+	hostedService.ETag = response.ETag()
 
-	decodedLabel, err := base64.StdEncoding.DecodeString(hostedService.LabelBase64)
-	if err != nil {
-		return hostedService, err
-	}
-	hostedService.Label = string(decodedLabel)
 	return hostedService, nil
 }
 
-func (self HostedServiceClient) createHostedServiceDeploymentConfig(dnsName, location string, reverseDnsFqdn string, label string, description string) CreateHostedService {
-	encodedLabel := base64.StdEncoding.EncodeToString([]byte(label))
+func (self HostedServiceClient) createHostedServiceDeploymentConfig(dnsName, location string, reverseDnsFqdn string, serviceLabel string, description string) CreateHostedService {
+	hostedServiceLabel := label.DefaultFor(dnsName)
+	if serviceLabel != "" {
+		hostedServiceLabel = label.Label(serviceLabel)
+	}
 	deployment := CreateHostedService{
 		ServiceName:    dnsName,
-		Label:          encodedLabel,
+		Label:          hostedServiceLabel,
 		Description:    description,
 		Location:       location,
 		ReverseDnsFqdn: reverseDnsFqdn,
@@ -4,18 +4,20 @@ import (
 	"encoding/xml"
 
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/label"
 )
 
-//HostedServiceClient is used to manage operations on Azure Hosted Services
+//HostedServiceClient is used to manage operations on Azure Hosted Services.
+//It is safe for concurrent use by multiple goroutines.
 type HostedServiceClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 type CreateHostedService struct {
 	XMLName        xml.Name
 	Xmlns          string `xml:"xmlns,attr"`
 	ServiceName    string
-	Label          string
+	Label          label.Label
 	Description    string
 	Location       string
 	ReverseDnsFqdn string `xml:"omitempty"`
@@ -30,12 +32,15 @@ type AvailabilityResponse struct {
 type HostedService struct {
 	Url                               string
 	ServiceName                       string
-	Description                       string `xml:"HostedServiceProperties>Description"`
-	AffinityGroup                     string `xml:"HostedServiceProperties>AffinityGroup"`
-	Location                          string `xml:"HostedServiceProperties>Location"`
-	LabelBase64                       string `xml:"HostedServiceProperties>Label"`
-	Label                             string
-	Status                            string `xml:"HostedServiceProperties>Status"`
-	ReverseDnsFqdn                    string `xml:"HostedServiceProperties>ReverseDnsFqdn"`
+	Description                       string      `xml:"HostedServiceProperties>Description"`
+	AffinityGroup                     string      `xml:"HostedServiceProperties>AffinityGroup"`
+	Location                          string      `xml:"HostedServiceProperties>Location"`
+	Label                             label.Label `xml:"HostedServiceProperties>Label"`
+	Status                            string      `xml:"HostedServiceProperties>Status"`
+	ReverseDnsFqdn                    string      `xml:"HostedServiceProperties>ReverseDnsFqdn"`
 	DefaultWinRmCertificateThumbprint string
+
+	// ETag is the entity tag of the hosted service when it was retrieved,
+	// if the service sent one.
+	ETag string `xml:"-"`
 }
@@ -2,18 +2,40 @@ package management
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"github.com/MSOpenTech/azure-sdk-for-go/core/http"
-	"github.com/MSOpenTech/azure-sdk-for-go/core/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const (
 	msVersionHeader           = "x-ms-version"
 	msVersionHeaderValue      = "2014-05-01"
 	contentHeader             = "Content-Type"
+	acceptEncodingHeader      = "Accept-Encoding"
+	contentEncodingHeader     = "Content-Encoding"
 	defaultContentHeaderValue = "application/xml"
 	requestIdHeader           = "X-Ms-Request-Id"
+	servedByRegionHeader      = "X-Ms-Servedbyregion"
+
+	errNoRequestID      = "The service accepted %s %s as an asynchronous operation but returned no request ID"
+	errTooManyRedirects = "Stopped after %d redirects of %s"
+	errInsecureRedirect = "Refusing to follow the redirect of %s to %s, which is not HTTPS"
+
+	errEmptyErrorBody = "The response has no body"
+
+	maxRedirects = 10
+
+	// maxErrorSnippet is the number of characters of a response body that
+	// is not an <Error> document kept in the message of the AzureError.
+	maxErrorSnippet = 256
 )
 
 //sendAzureGetRequest sends a request to the management API using the HTTP GET method
@@ -23,13 +45,12 @@ func (client *Client) SendAzureGetRequest(url string) ([]byte, error) {
 		return nil, fmt.Errorf(errParamNotSpecified, "url")
 	}
 
-	response, err := client.sendAzureRequest(url, "GET", "", nil)
+	response, err := client.SendAzureRequest(url, "GET", "", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	responseContent := getResponseBody(response)
-	return responseContent, nil
+	return response.Body, nil
 }
 
 //sendAzurePostRequest sends a request to the management API using the HTTP POST method
@@ -39,13 +60,12 @@ func (client *Client) SendAzurePostRequest(url string, data []byte) (string, err
 		return "", fmt.Errorf(errParamNotSpecified, "url")
 	}
 
-	response, err := client.sendAzureRequest(url, "POST", "", data)
+	response, err := client.SendAzureRequest(url, "POST", "", data)
 	if err != nil {
 		return "", err
 	}
 
-	requestId := response.Header[requestIdHeader]
-	return requestId[0], nil
+	return response.RequestID, nil
 }
 
 //sendAzurePutRequest sends a request to the management API using the HTTP PUT method
@@ -53,16 +73,15 @@ func (client *Client) SendAzurePostRequest(url string, data []byte) (string, err
 //if an empty string is passed, the default of "application/xml" will be used.
 func (client *Client) SendAzurePutRequest(url string, contentType string, data []byte) (string, error) {
 	if url == "" {
-		return "", fmt.Errorf(errParamNotSpecified, contentType, "url")
+		return "", fmt.Errorf(errParamNotSpecified, "url")
 	}
 
-	response, err := client.sendAzureRequest(url, "PUT", contentType, data)
+	response, err := client.SendAzureRequest(url, "PUT", contentType, data)
 	if err != nil {
 		return "", err
 	}
 
-	requestId := response.Header[requestIdHeader]
-	return requestId[0], nil
+	return response.RequestID, nil
 }
 
 //sendAzureDeleteRequest sends a request to the management API using the HTTP DELETE method
@@ -72,18 +91,83 @@ func (client *Client) SendAzureDeleteRequest(url string) (string, error) {
 		return "", fmt.Errorf(errParamNotSpecified, "url")
 	}
 
-	response, err := client.sendAzureRequest(url, "DELETE", "", nil)
+	response, err := client.SendAzureRequest(url, "DELETE", "", nil)
 	if err != nil {
 		return "", err
 	}
 
-	requestId := response.Header[requestIdHeader]
-	return requestId[0], nil
+	return response.RequestID, nil
+}
+
+//SendAzurePutRequestAndWait sends a request to the management API using the
+//HTTP PUT method and, if the service accepted it as an asynchronous operation,
+//waits for the operation to complete with WaitAsyncOperation.
+func (client *Client) SendAzurePutRequestAndWait(url string, contentType string, data []byte, options ...WaitOption) error {
+	if url == "" {
+		return fmt.Errorf(errParamNotSpecified, "url")
+	}
+
+	return client.sendAzureRequestAndWait(url, "PUT", contentType, data, options)
+}
+
+//SendAzureDeleteRequestAndWait sends a request to the management API using the
+//HTTP DELETE method and, if the service accepted it as an asynchronous
+//operation, waits for the operation to complete with WaitAsyncOperation.
+func (client *Client) SendAzureDeleteRequestAndWait(url string, options ...WaitOption) error {
+	if url == "" {
+		return fmt.Errorf(errParamNotSpecified, "url")
+	}
+
+	return client.sendAzureRequestAndWait(url, "DELETE", "", nil, options)
+}
+
+//sendAzureRequestAndWait sends a request and waits for the operation it
+//started. Operations the service completed synchronously, answering with
+//another status than 202 Accepted, are not polled.
+func (client *Client) sendAzureRequestAndWait(url string, requestType string, contentType string, data []byte, options []WaitOption) error {
+	response, err := client.SendAzureRequest(url, requestType, contentType, data)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusAccepted {
+		return nil
+	}
+	if response.RequestID == "" {
+		return fmt.Errorf(errNoRequestID, requestType, url)
+	}
+
+	return client.WaitAsyncOperation(response.RequestID, options...)
+}
+
+//SendAzureRequest sends a request to the management API and returns the
+//full response, including the headers and the diagnostics collected for the
+//round trip, or an error.
+func (client *Client) SendAzureRequest(url string, requestType string, contentType string, data []byte) (*AzureResponse, error) {
+	return client.sendAzureRequest(url, requestType, contentType, data, false)
+}
+
+//SendAzureGetRequestStream sends a request to the management API using the
+//HTTP GET method and returns the response body as a stream, without reading
+//it into memory first, which suits very large list responses. The caller must
+//close the stream. Error responses are read and returned as errors, like in
+//SendAzureGetRequest.
+func (client *Client) SendAzureGetRequestStream(url string) (io.ReadCloser, error) {
+	if url == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "url")
+	}
+
+	response, err := client.sendAzureRequest(url, "GET", "", nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.stream, nil
 }
 
-//sendAzureRequest constructs an HTTP client for the request, sends it to the
-//management API and returns the response or an error.
-func (client *Client) sendAzureRequest(url string, requestType string, contentType string, data []byte) (*http.Response, error) {
+//sendAzureRequest sends a request to the management API. If stream is true,
+//the body of a successful response is not read; it is left in the stream of
+//the AzureResponse, and the request stays active until the stream is closed.
+func (client *Client) sendAzureRequest(url string, requestType string, contentType string, data []byte, stream bool) (*AzureResponse, error) {
 	if url == "" {
 		return nil, fmt.Errorf(errParamNotSpecified, "url")
 	}
@@ -91,73 +175,340 @@ func (client *Client) sendAzureRequest(url string, requestType string, contentTy
 		return nil, fmt.Errorf(errParamNotSpecified, "requestType")
 	}
 
-	httpClient := client.createHttpClient()
+	err := client.lifecycle.beginRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersion := client.EffectiveAPIVersion()
+	cacheable := requestType == "GET" && !stream && client.responseCache != nil
+	if cacheable {
+		if response, ok := client.responseCache.get(client.cacheKey(url, apiVersion)); ok {
+			client.lifecycle.endRequest()
+			return response, nil
+		}
+	}
 
-	response, err := client.sendRequest(httpClient, url, requestType, contentType, data, 7)
+	httpClient, err := client.createHttpClient()
+	if err != nil {
+		client.lifecycle.endRequest()
+		return nil, err
+	}
+	response, err := client.sendRequest(httpClient, url, requestType, contentType, apiVersion, data, stream)
+	if IsVersionNotSupported(err) {
+		response, err = client.negotiateAPIVersion(httpClient, url, requestType, contentType, data, stream, apiVersion, err)
+	}
 	if err != nil {
+		client.lifecycle.endRequest()
+		client.reportError(err)
 		return nil, err
 	}
 
+	if cacheable {
+		client.responseCache.put(client.cacheKey(url, client.EffectiveAPIVersion()), url, response)
+	}
+
+	if response.stream != nil {
+		response.stream.release = append(response.stream.release, client.lifecycle.endRequest)
+	} else {
+		client.lifecycle.endRequest()
+	}
+
 	return response, nil
 }
 
-//createHttpClient creates an HTTP Client configured with the key pair for
-//the subscription for this client.
-func (client *Client) createHttpClient() *http.Client {
-	cert, _ := tls.X509KeyPair(client.publishSettings.SubscriptionCert, client.publishSettings.SubscriptionKey)
+//createHttpClient returns the HTTP client of the client, which is created
+//once, in makeClient, so that connections and TLS sessions are reused across
+//requests. Clients without credentials get a new unpooled one.
+func (client *Client) createHttpClient() (*http.Client, error) {
+	if client.httpClient != nil {
+		return client.httpClient, nil
+	}
+
+	return newHttpClient(client.publishSettings, ClientConfig{})
+}
+
+//newHttpClient creates an HTTP Client configured with the key pair of the
+//subscription and the connection settings of config, or returns the
+//HTTPClient of config if it is set. It returns an error if the key pair of
+//the subscription cannot be parsed.
+func newHttpClient(publishSettings publishSettings, config ClientConfig) (*http.Client, error) {
+	if config.HTTPClient != nil {
+		httpClient := *config.HTTPClient
+		httpClient.CheckRedirect = doNotFollowRedirects
+		return &httpClient, nil
+	}
 
 	ssl := &tls.Config{}
-	ssl.Certificates = []tls.Certificate{cert}
+	if config.TLSConfig != nil {
+		ssl = config.TLSConfig.Clone()
+	}
+	if len(publishSettings.SubscriptionCert) != 0 {
+		cert, err := tls.X509KeyPair(publishSettings.SubscriptionCert, publishSettings.SubscriptionKey)
+		if err != nil {
+			return nil, err
+		}
+		ssl.Certificates = append(ssl.Certificates, cert)
+	}
 
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: ssl,
-		},
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = ProxyFromEnvironment
 	}
 
-	return httpClient
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = ssl
+	transport.Proxy = proxy
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	transport.DisableKeepAlives = config.DisableKeepAlives
+	transport.DisableCompression = config.DisableCompression
+	transport.DialContext = newDialer(config).DialContext
+	transport.TLSHandshakeTimeout = transportTimeout(config.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout)
+	transport.ResponseHeaderTimeout = transportTimeout(config.ResponseHeaderTimeout, DefaultResponseHeaderTimeout)
+
+	var roundTripper http.RoundTripper = transport
+	if config.WrapTransport != nil {
+		roundTripper = config.WrapTransport(roundTripper)
+	}
+
+	return &http.Client{Transport: roundTripper, CheckRedirect: doNotFollowRedirects, Timeout: config.RequestTimeout}, nil
+}
+
+//doNotFollowRedirects makes an HTTP client return redirects, which are
+//followed by Client.do instead.
+func doNotFollowRedirects(request *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
 }
 
 //sendRequest sends a request to the Azure management API using the given
-//HTTP client and parameters. It returns the response from the call or an
-//error.
-func (client *Client) sendRequest(httpClient *http.Client, url string, requestType string, contentType string, data []byte, numberOfRetries int) (*http.Response, error) {
-	request, reqErr := client.createAzureRequest(url, requestType, contentType, data)
+//HTTP client and parameters, retrying it according to the retry policy of the
+//client. It returns the response from the call or an error. Requests rejected
+//because of their API version or for want of a token are not retried.
+//Throttled requests are retried after the delay asked for by the service,
+//without counting as attempts.
+func (client *Client) sendRequest(httpClient *http.Client, url string, requestType string, contentType string, apiVersion string, data []byte, stream bool) (*AzureResponse, error) {
+	policy := client.retryPolicy
+	throttling := throttleState{}
+	for attempt := 1; ; {
+		response, statusCode, header, err := client.sendAttempt(httpClient, url, requestType, contentType, apiVersion, data, stream)
+		if err == nil {
+			return response, nil
+		}
+
+		ctx := client.Context()
+		var tokenErr *TokenError
+		if ctx.Err() != nil || IsVersionNotSupported(err) || errors.As(err, &tokenErr) {
+			return nil, err
+		}
+
+		var delay time.Duration
+		if isThrottled(statusCode) {
+			delay, err = throttling.next(policy, statusCode, header, err)
+			if err != nil {
+				client.log().Error("Request throttled, retries exhausted", "method", requestType, "url", url, "error", err)
+				return nil, err
+			}
+			client.log().Warn("Request throttled, retrying", "method", requestType, "url", url, "status", statusCode, "delay", delay)
+		} else {
+			if attempt >= policy.maxAttempts() || !policy.isRetryable(requestType, statusCode, err) {
+				return nil, err
+			}
+			delay = policy.backoff(attempt)
+			attempt++
+			client.log().Info("Request failed, retrying", "method", requestType, "url", url, "attempt", attempt, "delay", delay, "error", err)
+		}
+
+		err = sleepContext(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+//sendAttempt sends a request once. It returns the response, or an error with
+//the status code and headers of the error response. The status code is zero
+//if the request failed before a response was received. If stream is true,
+//the body of a successful response is left unread in the returned stream.
+func (client *Client) sendAttempt(httpClient *http.Client, url string, requestType string, contentType string, apiVersion string, data []byte, stream bool) (*AzureResponse, int, http.Header, error) {
+	request, reqErr := client.createAzureRequest(url, requestType, contentType, apiVersion, data)
 	if reqErr != nil {
-		return nil, reqErr
+		return nil, 0, nil, reqErr
 	}
 
-	response, err := httpClient.Do(request)
+	ctx := client.Context()
+	if err := client.rateLimiter.Wait(ctx); err != nil {
+		return nil, 0, nil, err
+	}
+	ctx, timings := client.traceConnection(ctx)
+	request = request.WithContext(ctx)
+
+	diagnostics := RequestDiagnostics{
+		Operation: client.operationName(url),
+		Method:    request.Method,
+		URL:       request.URL.String(),
+		Start:     time.Now(),
+	}
+
+	client.log().Debug("Sending request", "method", request.Method, "url", diagnostics.URL)
+	response, request, err := client.do(ctx, httpClient, request, data)
+	diagnostics.URL = request.URL.String()
+	timings.apply(&diagnostics)
 	if err != nil {
-		if numberOfRetries == 0 {
-			return nil, err
+		diagnostics.Duration = time.Since(diagnostics.Start)
+		client.collectDiagnostics(diagnostics)
+		client.log().Debug("Request failed", "method", request.Method, "url", diagnostics.URL, "duration", diagnostics.Duration, "error", err)
+		if ctx.Err() != nil {
+			return nil, 0, nil, ctx.Err()
 		}
 
-		return client.sendRequest(httpClient, url, requestType, contentType, data, numberOfRetries-1)
+		return nil, 0, nil, err
+	}
+
+	diagnostics.TimeToFirstByte = time.Since(diagnostics.Start)
+	err = decompressResponse(response)
+	if err == nil {
+		err = client.runResponseHooks(request, response)
+	}
+	if err != nil {
+		response.Body.Close()
+		diagnostics.Duration = time.Since(diagnostics.Start)
+		diagnostics.StatusCode = response.StatusCode
+		client.collectDiagnostics(diagnostics)
+		return nil, response.StatusCode, response.Header, err
+	}
+	diagnostics.StatusCode = response.StatusCode
+	diagnostics.RequestID = response.Header.Get(requestIdHeader)
+	diagnostics.ServedByRegion = response.Header.Get(servedByRegionHeader)
+	client.quota.record(response.Header)
+	client.adaptRateLimit()
+	captureResponse(ctx, request, response)
+
+	if stream && response.StatusCode < http.StatusBadRequest {
+		client.tracer.traceResponse(request, response, nil, true)
+		diagnostics.Duration = diagnostics.TimeToFirstByte
+		client.collectDiagnostics(diagnostics)
+		client.logResponse(diagnostics)
+		return &AzureResponse{
+			StatusCode:  response.StatusCode,
+			Header:      response.Header,
+			RequestID:   diagnostics.RequestID,
+			Diagnostics: diagnostics,
+			stream:      &responseStream{ReadCloser: response.Body},
+		}, response.StatusCode, response.Header, nil
 	}
 
+	responseContent, err := getResponseBody(response)
+	response.Body.Close()
+	client.tracer.traceResponse(request, response, responseContent, false)
+	diagnostics.Duration = time.Since(diagnostics.Start)
+	if ctx.Err() != nil {
+		diagnostics.StatusCode = 0
+		client.collectDiagnostics(diagnostics)
+		return nil, 0, nil, ctx.Err()
+	}
+	client.collectDiagnostics(diagnostics)
+	if err != nil {
+		client.log().Debug("Reading response failed", "method", request.Method, "url", diagnostics.URL, "error", err)
+		return nil, 0, nil, err
+	}
+	client.logResponse(diagnostics)
+
 	if response.StatusCode >= http.StatusBadRequest {
-		responseContent := getResponseBody(response)
-		azureErr := getAzureError(responseContent)
-		if azureErr != nil {
-			if numberOfRetries == 0 {
-				return nil, azureErr
-			}
+		azureErr := getAzureError(response.StatusCode, responseContent)
+		azureErr.StatusCode = response.StatusCode
+		azureErr.RequestID = diagnostics.RequestID
+		azureErr.Method = request.Method
+		azureErr.URL = diagnostics.URL
+		azureErr.Body = responseContent
+		return nil, response.StatusCode, response.Header, azureErr
+	}
 
-			return client.sendRequest(httpClient, url, requestType, contentType, data, numberOfRetries-1)
-		}
+	azureResponse := &AzureResponse{
+		StatusCode:  response.StatusCode,
+		Header:      response.Header,
+		Body:        responseContent,
+		RequestID:   diagnostics.RequestID,
+		Diagnostics: diagnostics,
+	}
+	if stream {
+		azureResponse.stream = &responseStream{ReadCloser: ioutil.NopCloser(bytes.NewReader(responseContent))}
 	}
 
-	return response, nil
+	return azureResponse, response.StatusCode, response.Header, nil
+}
+
+//logResponse logs the end of a request that received a response.
+func (client *Client) logResponse(diagnostics RequestDiagnostics) {
+	client.log().Debug("Received response", "method", diagnostics.Method, "url", diagnostics.URL,
+		"status", diagnostics.StatusCode, "requestID", diagnostics.RequestID, "duration", diagnostics.Duration)
+}
+
+//do authorizes request, passes it to the request hooks and sends it. Temporary
+//and permanent redirects are followed with a new request for the location
+//they give, which is authorized and passed to the hooks like the first one,
+//so that it carries the x-ms-version and credentials of the client. The last
+//request sent is returned with its response.
+func (client *Client) do(ctx context.Context, httpClient *http.Client, request *http.Request, data []byte) (*http.Response, *http.Request, error) {
+	for redirects := 0; ; redirects++ {
+		err := client.authorize(ctx, request)
+		if err == nil {
+			err = client.runRequestHooks(request)
+		}
+		if err != nil {
+			return nil, request, err
+		}
+		client.tracer.traceRequest(request, data)
+
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return nil, request, err
+		}
+		if response.StatusCode != http.StatusTemporaryRedirect && response.StatusCode != http.StatusPermanentRedirect {
+			return response, request, nil
+		}
+
+		location, err := request.URL.Parse(response.Header.Get("Location"))
+		response.Body.Close()
+		if err != nil {
+			return nil, request, err
+		}
+		// Like the errors of the HTTP client, these are *url.Error, so they
+		// are not retried.
+		if redirects >= maxRedirects {
+			return nil, request, &url.Error{Op: request.Method, URL: request.URL.String(), Err: fmt.Errorf(errTooManyRedirects, maxRedirects, request.URL)}
+		}
+		if request.URL.Scheme == "https" && location.Scheme != "https" {
+			return nil, request, &url.Error{Op: request.Method, URL: request.URL.String(), Err: fmt.Errorf(errInsecureRedirect, request.URL, location)}
+		}
+
+		client.log().Debug("Following redirect", "method", request.Method, "from", request.URL.String(), "to", location.String())
+		request, err = client.newAzureRequest(location.String(), request.Method, request.Header.Get(contentHeader), request.Header.Get(msVersionHeader), data)
+		if err != nil {
+			return nil, request, err
+		}
+		request = request.WithContext(ctx)
+	}
 }
 
 //createAzureRequest packages up the request with the correct set of headers and returns
-//the request object or an error.
-func (client *Client) createAzureRequest(url string, requestType string, contentType string, data []byte) (*http.Request, error) {
+//the request object or an error. url is relative to the subscription; the
+//SubscriptionURL "/" addresses the subscription itself.
+func (client *Client) createAzureRequest(url string, requestType string, contentType string, apiVersion string, data []byte) (*http.Request, error) {
+	path := client.addQuery(strings.TrimPrefix(url, "/"))
+	if path != "" && !strings.HasPrefix(path, "?") {
+		path = "/" + path
+	}
+
+	url = fmt.Sprintf("%s/%s%s", client.managementURL, client.publishSettings.SubscriptionID, path)
+	return client.newAzureRequest(url, requestType, contentType, apiVersion, data)
+}
+
+//newAzureRequest creates a request for the absolute url with the headers of
+//the client.
+func (client *Client) newAzureRequest(url string, requestType string, contentType string, apiVersion string, data []byte) (*http.Request, error) {
 	var request *http.Request
 	var err error
 
-	url = fmt.Sprintf("%s/%s/%s", client.managementURL, client.publishSettings.SubscriptionID, url)
 	if data != nil {
 		body := bytes.NewBuffer(data)
 		request, err = http.NewRequest(requestType, url, body)
@@ -169,23 +520,48 @@ func (client *Client) createAzureRequest(url string, requestType string, content
 		return nil, err
 	}
 
-	request.Header.Add(msVersionHeader, msVersionHeaderValue)
+	request.Header.Add(msVersionHeader, apiVersion)
+	request.Header.Set(userAgentHeader, client.UserAgent())
+	if !client.disableCompression {
+		request.Header.Set(acceptEncodingHeader, "gzip")
+	}
+	if client.ifMatch != "" {
+		request.Header.Set(ifMatchHeader, client.ifMatch)
+	}
 	if len(contentType) > 0 {
 		request.Header.Add(contentHeader, contentType)
 	} else {
 		request.Header.Add(contentHeader, defaultContentHeaderValue)
 	}
+	client.setHeaders(request)
 
 	return request, nil
 }
 
-//getAzureError converts an error response body into an AzureError type.
-func getAzureError(responseBody []byte) error {
-	error := new(AzureError)
-	err := xml.Unmarshal(responseBody, error)
-	if err != nil {
-		return err
+//getAzureError converts an error response body into an AzureError type. Bodies
+//that are not an <Error> document, such as the HTML pages of gateways or empty
+//bodies, give an AzureError whose code is the HTTP status text and whose
+//message is the start of the body.
+func getAzureError(statusCode int, responseBody []byte) *AzureError {
+	azureErr := new(AzureError)
+	err := xml.Unmarshal(responseBody, azureErr)
+	if err == nil && (azureErr.Code != "" || azureErr.Message != "") {
+		return azureErr
+	}
+
+	return &AzureError{Code: http.StatusText(statusCode), Message: errorSnippet(responseBody)}
+}
+
+//errorSnippet returns the start of a response body for an error message, on a
+//single line.
+func errorSnippet(responseBody []byte) string {
+	snippet := strings.Join(strings.Fields(string(responseBody)), " ")
+	if snippet == "" {
+		return errEmptyErrorBody
+	}
+	if runes := []rune(snippet); len(runes) > maxErrorSnippet {
+		snippet = string(runes[:maxErrorSnippet]) + "..."
 	}
 
-	return error
+	return snippet
 }
@@ -0,0 +1,552 @@
+package management
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+)
+
+type recordingCollector struct {
+	mu          sync.Mutex
+	diagnostics []RequestDiagnostics
+}
+
+func (c *recordingCollector) CollectRequestDiagnostics(diagnostics RequestDiagnostics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diagnostics = append(c.diagnostics, diagnostics)
+}
+
+func newTestClient(t *testing.T, serverURL string, config ClientConfig) Client {
+	config.ManagementURL = serverURL
+	client, err := NewClientFromConfig("subscriptionID", testManagementCert(t), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestSendAzureRequestCollectsDiagnostics(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-request-id", "request-1")
+		w.Header().Set("x-ms-servedbyregion", "ussouth2")
+		w.Header().Set("Content-Length", "12")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	collector := &recordingCollector{}
+	client := newTestClient(t, server.URL, ClientConfig{DiagnosticsCollector: collector})
+
+	response, err := client.SendAzureRequest("locations", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collector.diagnostics) != 1 {
+		t.Fatalf("Expected 1 collected diagnostics, got %d", len(collector.diagnostics))
+	}
+	diagnostics := collector.diagnostics[0]
+	if diagnostics != response.Diagnostics {
+		t.Fatalf("Collected diagnostics %+v differ from response diagnostics %+v", diagnostics, response.Diagnostics)
+	}
+
+	if expected := "ussouth2"; diagnostics.ServedByRegion != expected {
+		t.Fatalf("Wrong served-by region. Expected: '%s', got: '%s'", expected, diagnostics.ServedByRegion)
+	}
+	if expected := "request-1"; diagnostics.RequestID != expected || response.RequestID != expected {
+		t.Fatalf("Wrong request ID. Expected: '%s', got: '%s' and '%s'", expected, diagnostics.RequestID, response.RequestID)
+	}
+	if diagnostics.StatusCode != http.StatusOK || diagnostics.Method != "GET" {
+		t.Fatalf("Wrong status or method: %d %s", diagnostics.StatusCode, diagnostics.Method)
+	}
+	if expected := server.URL + "/subscriptionID/locations"; diagnostics.URL != expected {
+		t.Fatalf("Wrong URL. Expected: '%s', got: '%s'", expected, diagnostics.URL)
+	}
+
+	if diagnostics.Start.IsZero() {
+		t.Fatal("Start time was not recorded")
+	}
+	if diagnostics.TimeToFirstByte <= 0 {
+		t.Fatalf("Time to first byte was not recorded: %v", diagnostics.TimeToFirstByte)
+	}
+	if diagnostics.Duration < diagnostics.TimeToFirstByte+delay {
+		t.Fatalf("Duration %v should include the time to first byte %v and the body delay %v", diagnostics.Duration, diagnostics.TimeToFirstByte, delay)
+	}
+	if diagnostics.Duration > 5*time.Second {
+		t.Fatalf("Implausible duration: %v", diagnostics.Duration)
+	}
+}
+
+func TestSendAzureRequestWithoutServedByRegion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+
+	response, err := client.SendAzureRequest("locations", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Diagnostics.ServedByRegion != "" {
+		t.Fatalf("Expected no served-by region, got '%s'", response.Diagnostics.ServedByRegion)
+	}
+	if string(response.Body) != "<Locations/>" {
+		t.Fatalf("Wrong body: %s", response.Body)
+	}
+}
+
+func TestSendAzureRequestCollectsConnectionTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	// Address the server by name so that the host name is resolved.
+	serverURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+
+	collector := &recordingCollector{}
+	client := newTestClient(t, serverURL, ClientConfig{HTTPClient: httpClient, DiagnosticsCollector: collector})
+	response, err := client.SendAzureRequest("locations", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := response.Diagnostics
+	if diagnostics.DNS <= 0 || diagnostics.Connect <= 0 || diagnostics.TLSHandshake <= 0 {
+		t.Fatalf("Connection timings were not recorded: DNS %v, connect %v, TLS handshake %v", diagnostics.DNS, diagnostics.Connect, diagnostics.TLSHandshake)
+	}
+	if connection := diagnostics.DNS + diagnostics.Connect + diagnostics.TLSHandshake; connection > diagnostics.TimeToFirstByte {
+		t.Fatalf("Connection timings %v should precede the time to first byte %v", connection, diagnostics.TimeToFirstByte)
+	}
+	if diagnostics.TimeToFirstByte > diagnostics.Duration {
+		t.Fatalf("Time to first byte %v should precede the duration %v", diagnostics.TimeToFirstByte, diagnostics.Duration)
+	}
+	if collector.diagnostics[0] != diagnostics {
+		t.Fatalf("Collected diagnostics %+v differ from response diagnostics %+v", collector.diagnostics[0], diagnostics)
+	}
+
+	response, err = client.SendAzureRequest("locations", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diagnostics := response.Diagnostics; diagnostics.DNS != 0 || diagnostics.Connect != 0 || diagnostics.TLSHandshake != 0 {
+		t.Fatalf("Expected no connection timings on a reused connection, got %+v", diagnostics)
+	}
+}
+
+func TestSendAzureRequestWithoutCollectorSkipsConnectionTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{HTTPClient: server.Client()})
+	response, err := client.SendAzureRequest("locations", "GET", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Diagnostics.Connect != 0 || response.Diagnostics.TLSHandshake != 0 {
+		t.Fatalf("Expected connections not to be traced without a collector, got %+v", response.Diagnostics)
+	}
+}
+
+func TestWithContextCancelsRequestInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	bound := client.WithContext(ctx)
+	_, err := bound.SendAzureGetRequest("locations")
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Request was not aborted, took %v", elapsed)
+	}
+	if client.Context() != context.Background() {
+		t.Errorf("Expected WithContext to leave the original client unbound")
+	}
+}
+
+func TestWithContextBindsWaits(t *testing.T) {
+	server := newOperationServer(func(int) string { return "InProgress" })
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	bound := client.WithContext(ctx)
+	err := bound.WaitAsyncOperation("operation")
+	var abortedErr *AbortedOperationError
+	if !errors.As(err, &abortedErr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the wait to be aborted by the client context, got %v", err)
+	}
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      ClientConfig
+		connections int64
+	}{
+		{"pooled", ClientConfig{}, 1},
+		{"keep-alives disabled", ClientConfig{DisableKeepAlives: true}, 5},
+	}
+
+	for _, test := range tests {
+		var connections int64
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "12")
+			w.Write([]byte("<Locations/>"))
+		}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt64(&connections, 1)
+			}
+		}
+		server.Start()
+
+		client := newTestClient(t, server.URL, test.config)
+		for i := 0; i < 5; i++ {
+			copied := client
+			if _, err := copied.SendAzureGetRequest("locations"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		server.Close()
+
+		if connections != test.connections {
+			t.Errorf("%s: expected %d connections, got %d", test.name, test.connections, connections)
+		}
+	}
+}
+
+func newChunkedServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < len(body); i += 1000 {
+			end := i + 1000
+			if end > len(body) {
+				end = len(body)
+			}
+			w.Write([]byte(body[i:end]))
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+func TestSendAzureGetRequestReadsChunkedBody(t *testing.T) {
+	body := "<Locations>" + strings.Repeat("<Location><Name>West US</Name></Location>", 500) + "</Locations>"
+	server := newChunkedServer(body)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	response, err := client.SendAzureGetRequest("locations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response) != body {
+		t.Errorf("Expected the %d byte body, got %d bytes", len(body), len(response))
+	}
+}
+
+func TestSendAzureGetRequestStream(t *testing.T) {
+	body := "<Locations>" + strings.Repeat("<Location><Name>West US</Name></Location>", 500) + "</Locations>"
+	server := newChunkedServer(body)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	stream, err := client.SendAzureGetRequestStream("locations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active := client.Stats().ActiveRequests; active != 1 {
+		t.Errorf("Expected the request to be active until the stream is closed, got %d active requests", active)
+	}
+
+	content, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != body {
+		t.Errorf("Expected the %d byte body, got %d bytes", len(body), len(content))
+	}
+
+	stream.Close()
+	stream.Close()
+	if active := client.Stats().ActiveRequests; active != 0 {
+		t.Errorf("Expected no active requests after the stream is closed, got %d", active)
+	}
+}
+
+func TestSendAzureGetRequestStreamReturnsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<Error><Code>ResourceNotFound</Code><Message>No such thing</Message></Error>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+	if _, err := client.SendAzureGetRequestStream("locations"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+	if active := client.Stats().ActiveRequests; active != 0 {
+		t.Errorf("Expected no active requests, got %d", active)
+	}
+}
+
+func TestSendAzureRequestAndWaitPollsAcceptedOperations(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		switch {
+		case strings.Contains(r.URL.Path, "/operations/"):
+			body := "<Operation><ID>request-1</ID><Status>Succeeded</Status></Operation>"
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.Write([]byte(body))
+		case strings.HasSuffix(r.URL.Path, "/sync"):
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("x-ms-request-id", "request-1")
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+	if err := client.SendAzurePutRequestAndWait("services/async", "text/plain", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendAzureDeleteRequestAndWait("services/sync"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "PUT /subscriptionID/services/async,GET /subscriptionID/operations/request-1,DELETE /subscriptionID/services/sync"
+	if actual := strings.Join(requests, ","); actual != expected {
+		t.Errorf("Expected requests %s, got %s", expected, actual)
+	}
+}
+
+type countingTransport struct {
+	requests int64
+}
+
+func (t *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestClientUsesInjectedHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := newTestClient(t, server.URL, ClientConfig{HTTPClient: &http.Client{Transport: transport}})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("Expected the request to be sent by the injected client, it sent %d requests", transport.requests)
+	}
+}
+
+func TestClientIsSafeForConcurrentUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "<Locations/>"
+		if strings.Contains(r.URL.Path, "/operations/") {
+			body = "<Operation><Status>Succeeded</Status></Operation>"
+		}
+		w.Header().Set("x-ms-ratelimit-remaining-subscription-reads", "100")
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var trace bytes.Buffer
+	client := newTestClient(t, server.URL, ClientConfig{
+		DiagnosticsCollector: &recordingCollector{},
+		Metrics:              &recordingMetrics{},
+		TraceWriter:          &trace,
+		RateLimiter:          NewRateLimiter(1000, 100),
+		DefaultPollInterval:  time.Millisecond,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			copied := client.WithContext(context.Background())
+			if _, err := copied.SendAzureGetRequest("locations"); err != nil {
+				t.Error(err)
+			}
+			if err := client.WaitAsyncOperation(fmt.Sprintf("operation-%d", i)); err != nil {
+				t.Error(err)
+			}
+			if i%5 == 0 {
+				client.SetAPIVersion("2014-10-01")
+			}
+			client.EffectiveAPIVersion()
+			client.Stats()
+			client.RemainingQuota()
+		}(i)
+	}
+	wg.Wait()
+
+	if active := client.Stats().ActiveRequests; active != 0 {
+		t.Errorf("Expected no active requests, got %d", active)
+	}
+}
+
+func TestClientDecompressesGzipResponses(t *testing.T) {
+	body := "<Images>" + strings.Repeat("<OSImage><Name>image</Name></OSImage>", 1000) + "</Images>"
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(body))
+	writer.Close()
+
+	var acceptEncoding atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding.Store(r.Header.Get("Accept-Encoding"))
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", fmt.Sprint(compressed.Len()))
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		config         ClientConfig
+		acceptEncoding string
+	}{
+		{"default", ClientConfig{}, "gzip"},
+		{"injected client", ClientConfig{HTTPClient: &http.Client{Transport: &http.Transport{DisableCompression: true}}}, "gzip"},
+		{"disabled", ClientConfig{DisableCompression: true}, ""},
+	}
+
+	for _, test := range tests {
+		client := newTestClient(t, server.URL, test.config)
+		response, err := client.SendAzureGetRequest("services/images")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(response) != body {
+			t.Errorf("%s: expected the %d byte body, got %d bytes", test.name, len(body), len(response))
+		}
+		if actual := acceptEncoding.Load().(string); actual != test.acceptEncoding {
+			t.Errorf("%s: expected Accept-Encoding '%s', got '%s'", test.name, test.acceptEncoding, actual)
+		}
+	}
+}
+
+type staticTokenSource string
+
+func (token staticTokenSource) Token(ctx context.Context) (Token, error) {
+	return Token{AccessToken: string(token), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestClientFollowsRedirectsWithItsHeaders(t *testing.T) {
+	var received string
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = fmt.Sprintf("%s %s %s %s %s", r.Method, r.URL.Path, r.Header.Get("x-ms-version"), r.Header.Get("Authorization"), body)
+		w.Header().Set("x-ms-request-id", "request-1")
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer regional.Close()
+
+	var redirects int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&redirects, 1)
+		w.Header().Set("Location", regional.URL+r.URL.Path)
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	var hooks int
+	client, err := NewClientFromTokenSource("subscriptionID", staticTokenSource("token"), ClientConfig{
+		ManagementURL: server.URL,
+		APIVersion:    "2014-10-01",
+		RequestHooks:  []RequestHook{func(*http.Request) error { hooks++; return nil }},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestID, err := client.SendAzurePutRequest("services/networking/media", "text/plain", []byte("<config/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requestID != "request-1" {
+		t.Errorf("Expected the request ID of the redirected response, got '%s'", requestID)
+	}
+	if expected := "PUT /subscriptionID/services/networking/media 2014-10-01 Bearer token <config/>"; received != expected {
+		t.Errorf("Wrong redirected request. Expected: '%s', got: '%s'", expected, received)
+	}
+	if redirects != 1 || hooks != 2 {
+		t.Errorf("Expected 1 redirect and the hooks to see both requests, got %d redirects and %d hook calls", redirects, hooks)
+	}
+}
+
+func TestClientStopsFollowingRedirectLoops(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Location", r.URL.Path)
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	if _, err := client.SendAzureGetRequest("locations"); err == nil || !strings.Contains(err.Error(), "redirects") {
+		t.Errorf("Expected the redirect loop to be reported, got %v", err)
+	}
+	if requests != maxRedirects+1 {
+		t.Errorf("Expected %d requests, got %d", maxRedirects+1, requests)
+	}
+}
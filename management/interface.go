@@ -0,0 +1,52 @@
+package management
+
+import "context"
+
+// ManagementClient is the part of Client used by the service sub-packages.
+// *Client implements it; package fake provides an in-memory implementation
+// with canned responses, so that code built on the sub-packages can be unit
+// tested without Azure credentials:
+//
+//	fakeClient := fake.NewClient()
+//	fakeClient.AddResponse("GET", "services/storageservices", listXML)
+//	storageClient := storageservice.NewClientFromManagementClient(fakeClient)
+type ManagementClient interface {
+	SendAzureRequest(url string, requestType string, contentType string, data []byte) (*AzureResponse, error)
+	SendAzureGetRequest(url string) ([]byte, error)
+	SendAzurePostRequest(url string, data []byte) (string, error)
+	SendAzurePutRequest(url string, contentType string, data []byte) (string, error)
+	SendAzureDeleteRequest(url string) (string, error)
+	SendAzurePutRequestAndWait(url string, contentType string, data []byte, options ...WaitOption) error
+	SendAzureDeleteRequestAndWait(url string, options ...WaitOption) error
+	WaitAsyncOperation(operationId string, options ...WaitOption) error
+	Route(name string, args ...interface{}) string
+	Environment() Environment
+}
+
+var _ ManagementClient = (*Client)(nil)
+
+// RequireAPIVersion returns client with the API version required by a
+// service sub-package applied, as with Client.WithAPIVersion, if client is a
+// *Client. Other implementations are returned unchanged.
+func RequireAPIVersion(client ManagementClient, version string) ManagementClient {
+	azureClient, ok := client.(*Client)
+	if !ok || azureClient == nil {
+		return client
+	}
+
+	versioned := azureClient.WithAPIVersion(version)
+	return &versioned
+}
+
+// BindContext returns client with its requests and waits bound to ctx, as
+// with Client.WithContext, if client is a *Client. Other implementations are
+// returned unchanged.
+func BindContext(client ManagementClient, ctx context.Context) ManagementClient {
+	azureClient, ok := client.(*Client)
+	if !ok || azureClient == nil {
+		return client
+	}
+
+	bound := azureClient.WithContext(ctx)
+	return &bound
+}
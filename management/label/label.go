@@ -0,0 +1,67 @@
+// Package label implements the base64 encoding the Azure Service Management
+// API uses for the labels of resources such as storage accounts, hosted
+// services, affinity groups and deployments.
+package label
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+)
+
+// MaxLength is the maximum length of a decoded label accepted by the
+// management API.
+const MaxLength = 100
+
+// Encode returns the base64 wire form of a label.
+func Encode(label string) string {
+	return base64.StdEncoding.EncodeToString([]byte(label))
+}
+
+// Decode returns the label encoded in s. Both padded and unpadded base64 are
+// accepted, and surrounding whitespace is ignored. An empty s decodes to an
+// empty label.
+func Decode(s string) (string, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	decoded, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+// DefaultFor returns the label to use for a resource that was not given a
+// label of its own: its name, truncated to MaxLength characters.
+func DefaultFor(name string) Label {
+	if runes := []rune(name); len(runes) > MaxLength {
+		name = string(runes[:MaxLength])
+	}
+
+	return Label(name)
+}
+
+// Label is a resource label. It holds the decoded label in Go and is
+// marshalled to and from its base64 wire form in XML.
+type Label string
+
+// MarshalXML implements xml.Marshaler by encoding the label as base64.
+func (l Label) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(Encode(string(l)), start)
+}
+
+// UnmarshalXML implements xml.Unmarshaler by decoding a base64 label.
+func (l *Label) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var encoded string
+	if err := d.DecodeElement(&encoded, &start); err != nil {
+		return err
+	}
+
+	decoded, err := Decode(encoded)
+	if err != nil {
+		return err
+	}
+
+	*l = Label(decoded)
+	return nil
+}
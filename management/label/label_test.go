@@ -0,0 +1,84 @@
+package label
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, label := range []string{"", "a", "ab", "abc", "my storage account", "ünïcödé label"} {
+		decoded, err := Decode(Encode(label))
+		if err != nil {
+			t.Fatalf("Unexpected error decoding '%s': %v", label, err)
+		}
+		if decoded != label {
+			t.Fatalf("Round trip changed the label. Expected: '%s', got: '%s'", label, decoded)
+		}
+	}
+}
+
+func TestDecodeUnpadded(t *testing.T) {
+	tests := map[string]string{
+		"YQ":         "a",
+		"YQ==":       "a",
+		"YWI":        "ab",
+		" YWJj \n":   "abc",
+		"bXlsYWJlbA": "mylabel",
+	}
+
+	for encoded, expected := range tests {
+		decoded, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("Unexpected error decoding '%s': %v", encoded, err)
+		}
+		if decoded != expected {
+			t.Fatalf("Wrong label decoded from '%s'. Expected: '%s', got: '%s'", encoded, expected, decoded)
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for _, encoded := range []string{"!!!!", "YQ=Q", "Y"} {
+		if _, err := Decode(encoded); err == nil {
+			t.Fatalf("Expected an error decoding '%s'", encoded)
+		}
+	}
+}
+
+func TestDefaultFor(t *testing.T) {
+	if actual := DefaultFor("account"); actual != "account" {
+		t.Fatalf("Wrong default label. Expected: 'account', got: '%s'", actual)
+	}
+
+	if actual := DefaultFor(strings.Repeat("x", MaxLength+10)); len(actual) != MaxLength {
+		t.Fatalf("Expected the default label to be truncated to %d characters, got %d", MaxLength, len(actual))
+	}
+}
+
+type labelled struct {
+	XMLName xml.Name `xml:"Service"`
+	Label   Label
+}
+
+func TestLabelXML(t *testing.T) {
+	data, err := xml.Marshal(labelled{Label: "my label"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<Service><Label>bXkgbGFiZWw=</Label></Service>"; string(data) != expected {
+		t.Fatalf("Wrong XML. Expected: '%s', got: '%s'", expected, data)
+	}
+
+	var decoded labelled
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Label != "my label" {
+		t.Fatalf("Wrong label after round trip: '%s'", decoded.Label)
+	}
+
+	if err := xml.Unmarshal([]byte("<Service><Label>!!!</Label></Service>"), &decoded); err == nil {
+		t.Fatal("Expected an error unmarshalling a malformed label")
+	}
+}
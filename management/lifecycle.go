@@ -0,0 +1,165 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned by the requests and waits of a Client that has
+// been shut down.
+var ErrClientClosed = errors.New("azure: client is closed")
+
+// OperationHandle identifies an asynchronous operation whose wait did not
+// complete, so that it can be resumed later, possibly by another Client,
+// with WaitAsyncOperation.
+type OperationHandle struct {
+	OperationID string
+}
+
+// ClientStats is a snapshot of the activity of a Client.
+type ClientStats struct {
+	// ActiveRequests is the number of HTTP requests in flight.
+	ActiveRequests int
+
+	// WaitingOperations is the number of asynchronous operations being
+	// waited on with WaitAsyncOperation.
+	WaitingOperations int
+}
+
+// lifecycle tracks the in-flight requests and active waits of a client, so
+// that it can be shut down gracefully. It is shared by all copies of a
+// Client and is safe for concurrent use.
+type lifecycle struct {
+	mu             sync.Mutex
+	closed         bool
+	activeRequests int
+	drained        chan struct{}
+	waiters        map[*waiter]struct{}
+}
+
+// waiter is an active WaitAsyncOperation call. cancelled is closed when the
+// client is shut down.
+type waiter struct {
+	operationID string
+	cancelled   chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{waiters: make(map[*waiter]struct{})}
+}
+
+// beginRequest registers a request, unless the client is closed.
+func (l *lifecycle) beginRequest() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClientClosed
+	}
+	l.activeRequests++
+	return nil
+}
+
+func (l *lifecycle) endRequest() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.activeRequests--
+	if l.activeRequests == 0 && l.drained != nil {
+		close(l.drained)
+		l.drained = nil
+	}
+}
+
+// beginWait registers a wait for operationID, unless the client is closed.
+func (l *lifecycle) beginWait(operationID string) (*waiter, error) {
+	w := &waiter{operationID: operationID, cancelled: make(chan struct{})}
+	if l == nil {
+		return w, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, ErrClientClosed
+	}
+	l.waiters[w] = struct{}{}
+	return w, nil
+}
+
+func (l *lifecycle) endWait(w *waiter) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.waiters, w)
+}
+
+// Shutdown stops the client: new requests and waits, on the client and all
+// its copies, fail with ErrClientClosed, and the active waits are cancelled.
+// It then waits for the requests in flight to finish, until ctx is done, in
+// which case ctx.Err() is returned.
+//
+// The handles of the operations whose waits were cancelled are returned, so
+// that they can be resumed later. The operations themselves keep running on
+// the server.
+func (client *Client) Shutdown(ctx context.Context) ([]OperationHandle, error) {
+	l := client.lifecycle
+	if l == nil {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, nil
+	}
+	l.closed = true
+
+	var handles []OperationHandle
+	for w := range l.waiters {
+		close(w.cancelled)
+		handles = append(handles, OperationHandle{OperationID: w.operationID})
+	}
+
+	var drained chan struct{}
+	if l.activeRequests > 0 {
+		drained = make(chan struct{})
+		l.drained = drained
+	}
+	l.mu.Unlock()
+
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			return handles, ctx.Err()
+		}
+	}
+
+	return handles, nil
+}
+
+// Stats returns a snapshot of the activity of the client and its copies.
+func (client *Client) Stats() ClientStats {
+	l := client.lifecycle
+	if l == nil {
+		return ClientStats{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ClientStats{
+		ActiveRequests:    l.activeRequests,
+		WaitingOperations: len(l.waiters),
+	}
+}
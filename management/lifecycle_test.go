@@ -0,0 +1,122 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdownReturnsPendingOperations(t *testing.T) {
+	var completed atomic.Bool
+	server := newOperationServer(func(int) string {
+		if completed.Load() {
+			return "Succeeded"
+		}
+		return "InProgress"
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+
+	ids := []string{"operation-1", "operation-2", "operation-3"}
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			errs[i] = client.WaitAsyncOperation(id)
+		}(i, id)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.Stats().WaitingOperations != len(ids) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Waits did not start, stats: %+v", client.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	handles, err := client.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	var handleIDs []string
+	for _, handle := range handles {
+		handleIDs = append(handleIDs, handle.OperationID)
+	}
+	sort.Strings(handleIDs)
+	if fmt.Sprint(handleIDs) != fmt.Sprint(ids) {
+		t.Fatalf("Expected handles for %v, got %v", ids, handleIDs)
+	}
+
+	for i, err := range errs {
+		var abortedErr *AbortedOperationError
+		if !errors.As(err, &abortedErr) || abortedErr.OperationID != ids[i] || !errors.Is(err, ErrClientClosed) {
+			t.Errorf("Expected wait for %s to be aborted by the shutdown, got %v", ids[i], err)
+		}
+	}
+
+	if stats := client.Stats(); stats != (ClientStats{}) {
+		t.Errorf("Expected no activity after shutdown, got %+v", stats)
+	}
+	if _, err := client.SendAzureGetRequest("locations"); err != ErrClientClosed {
+		t.Errorf("Expected new requests to fail with ErrClientClosed, got %v", err)
+	}
+	if err := client.WaitAsyncOperation("operation-4"); err != ErrClientClosed {
+		t.Errorf("Expected new waits to fail with ErrClientClosed, got %v", err)
+	}
+
+	completed.Store(true)
+	fresh := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+	for _, handle := range handles {
+		if err := fresh.WaitAsyncOperation(handle.OperationID); err != nil {
+			t.Errorf("Resuming %s: %v", handle.OperationID, err)
+		}
+	}
+}
+
+func TestShutdownWaitsForActiveRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := newOperationServer(func(int) string {
+		<-release
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.SendAzureGetRequest("operations/operation")
+		done <- err
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.Stats().ActiveRequests != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Request did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the drain to time out, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the in-flight request to complete, got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 )
 
 const (
+	apiVersion           = "2014-05-01"
 	azureLocationListURL = "locations"
 	errInvalidLocation   = "Invalid location: %s. Available locations: %s"
 	errParamNotSpecified = "Parameter %s is not specified."
@@ -16,7 +17,13 @@ const (
 
 //NewClient is used to instantiate a new LocationClient from an Azure client
 func NewClient(client management.Client) LocationClient {
-	return LocationClient{client: client}
+	return NewClientFromManagementClient(&client)
+}
+
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) LocationClient {
+	return LocationClient{client: management.RequireAPIVersion(client, apiVersion)}
 }
 
 func (self LocationClient) ResolveLocation(location string) error {
@@ -40,22 +47,72 @@ func (self LocationClient) ResolveLocation(location string) error {
 	return errors.New(fmt.Sprintf(errInvalidLocation, location, locations))
 }
 
+//GetLocationList returns the locations available to the subscription. Use
+//ListLocations to avoid holding all of them in memory.
 func (self LocationClient) GetLocationList() (LocationList, error) {
 	locationList := LocationList{}
 
-	response, err := self.client.SendAzureGetRequest(azureLocationListURL)
-	if err != nil {
-		return locationList, err
+	locations := self.ListLocations()
+	for locations.Next() {
+		locationList.Locations = append(locationList.Locations, locations.Value())
 	}
-
-	err = xml.Unmarshal(response, &locationList)
-	if err != nil {
+	if err := locations.Err(); err != nil {
 		return locationList, err
 	}
 
 	return locationList, nil
 }
 
+//ListLocations returns an iterator over the locations available to the
+//subscription, in the order of the service, which fetches them a page at a
+//time.
+func (self LocationClient) ListLocations() *LocationIterator {
+	return &LocationIterator{pager: management.NewPager(self.client, azureLocationListURL)}
+}
+
+//LocationIterator iterates over locations. Call Next before each Value, and Err
+//once Next returns false.
+type LocationIterator struct {
+	pager     *management.Pager
+	locations []Location
+	index     int
+	err       error
+}
+
+//Next advances to the next location, fetching the next page if needed. It
+//returns false at the end of the list or on error.
+func (iterator *LocationIterator) Next() bool {
+	for iterator.index >= len(iterator.locations) {
+		if iterator.err != nil || !iterator.pager.Next() {
+			return false
+		}
+
+		page := LocationList{}
+		if err := xml.Unmarshal(iterator.pager.Page(), &page); err != nil {
+			iterator.err = err
+			return false
+		}
+		iterator.locations, iterator.index = page.Locations, 0
+	}
+
+	iterator.index++
+	return true
+}
+
+//Value returns the current location.
+func (iterator *LocationIterator) Value() Location {
+	return iterator.locations[iterator.index-1]
+}
+
+//Err returns the error that stopped Next, if any.
+func (iterator *LocationIterator) Err() error {
+	if iterator.err != nil {
+		return iterator.err
+	}
+
+	return iterator.pager.Err()
+}
+
 func (self LocationClient) GetLocation(location string) (*Location, error) {
 	if location == "" {
 		return nil, fmt.Errorf(errParamNotSpecified, "location")
@@ -9,9 +9,10 @@ import (
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
 )
 
-//LocationClient is used to manage operations on Azure Locations
+//LocationClient is used to manage operations on Azure Locations.
+//It is safe for concurrent use by multiple goroutines.
 type LocationClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 type LocationList struct {
@@ -0,0 +1,100 @@
+package management
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogLevel is the severity of a log event.
+type LogLevel int
+
+// Log levels, from the most to the least verbose.
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(level))
+}
+
+// Logger receives the log events of a Client. Each event is a message
+// followed by alternating keys and values that describe it.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// nopLogger discards every event. It is used when no Logger is configured.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Warn(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+
+// log returns the Logger of the client, which is never nil.
+func (client *Client) log() Logger {
+	if client.logger == nil {
+		return nopLogger{}
+	}
+
+	return client.logger
+}
+
+// NewLogger returns a Logger that writes the events of level and above to w,
+// one line each, in the form
+//
+//	2015-01-02T15:04:05Z WARN message key=value key=value
+func NewLogger(w io.Writer, level LogLevel) Logger {
+	return &writerLogger{w: w, level: level}
+}
+
+type writerLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level LogLevel
+}
+
+func (l *writerLogger) Debug(msg string, keyvals ...interface{}) { l.log(LogDebug, msg, keyvals) }
+func (l *writerLogger) Info(msg string, keyvals ...interface{})  { l.log(LogInfo, msg, keyvals) }
+func (l *writerLogger) Warn(msg string, keyvals ...interface{})  { l.log(LogWarn, msg, keyvals) }
+func (l *writerLogger) Error(msg string, keyvals ...interface{}) { l.log(LogError, msg, keyvals) }
+
+func (l *writerLogger) log(level LogLevel, msg string, keyvals []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var line bytes.Buffer
+	fmt.Fprintf(&line, "%s %s %s", time.Now().UTC().Format(time.RFC3339), level, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(&line, " %v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprintf(&line, " %v", keyvals[i])
+		}
+	}
+	line.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(line.Bytes())
+}
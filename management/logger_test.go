@@ -0,0 +1,62 @@
+package management
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerReceivesRequestRetryAndPollingEvents(t *testing.T) {
+	var requests int64
+	server := newFlakyServer(1, http.StatusInternalServerError, &requests)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := newTestClient(t, server.URL, ClientConfig{Logger: NewLogger(&buffer, LogDebug)})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+
+	operations := newOperationServer(func(polls int) string {
+		if polls < 2 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer operations.Close()
+
+	pollingClient := newTestClient(t, operations.URL, ClientConfig{
+		Logger:              NewLogger(&buffer, LogDebug),
+		DefaultPollInterval: 10 * time.Millisecond,
+	})
+	if err := pollingClient.WaitAsyncOperation("operation"); err != nil {
+		t.Fatal(err)
+	}
+
+	log := buffer.String()
+	for _, expected := range []string{
+		"DEBUG Sending request method=GET",
+		"DEBUG Received response method=GET",
+		"status=500",
+		"INFO Request failed, retrying method=GET url=locations attempt=2",
+		"DEBUG Waiting for operation operation=operation",
+		"INFO Operation status changed operation=operation from=InProgress to=Succeeded",
+	} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("Expected the log to contain %q, got:\n%s", expected, log)
+		}
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLogger(&buffer, LogWarn)
+	logger.Info("hidden")
+	logger.Warn("shown", "key", "value", "odd")
+
+	if log := buffer.String(); strings.Contains(log, "hidden") || !strings.Contains(log, "WARN shown key=value odd\n") {
+		t.Errorf("Unexpected log:\n%s", log)
+	}
+}
@@ -0,0 +1,114 @@
+package management
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestMetric describes a single attempt of a request sent by a Client.
+type RequestMetric struct {
+	// Operation names what the request did: the name of the route it was
+	// sent to, such as RouteStorageService, or, for requests not made
+	// through a route, their URL relative to the subscription, without the
+	// query.
+	Operation string
+	Method    string
+
+	// StatusCode is zero if the attempt failed before a response was
+	// received.
+	StatusCode int
+	Duration   time.Duration
+}
+
+// PollMetric describes a single status check of WaitAsyncOperation.
+type PollMetric struct {
+	OperationID string
+
+	// Status is the status of the operation, or empty if Err is set.
+	Status   string
+	Duration time.Duration
+	Err      error
+}
+
+// Metrics is implemented by types that export the request counts, latencies
+// and error rates of a Client, for example to Prometheus or statsd. Its
+// methods are called for every attempt of every request and for every status
+// check of WaitAsyncOperation, possibly concurrently, so they should be quick
+// and safe for concurrent use.
+type Metrics interface {
+	RequestCompleted(metric RequestMetric)
+	OperationPolled(metric PollMetric)
+}
+
+func (client *Client) recordRequest(diagnostics RequestDiagnostics) {
+	if client.metrics == nil {
+		return
+	}
+
+	client.metrics.RequestCompleted(RequestMetric{
+		Operation:  diagnostics.Operation,
+		Method:     diagnostics.Method,
+		StatusCode: diagnostics.StatusCode,
+		Duration:   diagnostics.Duration,
+	})
+}
+
+func (client *Client) recordPoll(metric PollMetric) {
+	if client.metrics == nil {
+		return
+	}
+
+	client.metrics.OperationPolled(metric)
+}
+
+// operationName returns the name of the route url was made from, or url
+// without its query if it matches no route. Route names are tried in order so
+// that the result does not depend on map iteration.
+func (client *Client) operationName(url string) string {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+
+	names := make([]string, 0, len(defaultRoutes))
+	for name := range defaultRoutes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		template, ok := client.routes[name]
+		if !ok {
+			template = defaultRoutes[name]
+		}
+		if matchesRoute(template, url) {
+			return name
+		}
+	}
+
+	return url
+}
+
+// matchesRoute reports whether path has the segments of template, with each
+// fmt verb of the template matching any single segment.
+func matchesRoute(template, path string) bool {
+	templateSegments := strings.Split(template, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(templateSegments) != len(pathSegments) {
+		return false
+	}
+
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "%") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package management
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"net/http"
+	"net/http/httptest"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	requests []RequestMetric
+	polls    []PollMetric
+}
+
+func (m *recordingMetrics) RequestCompleted(metric RequestMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, metric)
+}
+
+func (m *recordingMetrics) OperationPolled(metric PollMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.polls = append(m.polls, metric)
+}
+
+func TestMetricsRecordRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.Header().Set("Content-Length", "0")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := newTestClient(t, server.URL, ClientConfig{Metrics: metrics, RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+	client.SendAzureGetRequest(client.Route(RouteStorageService, "account"))
+	client.SendAzureGetRequest("services/hostedservices/missing?embed-detail=true")
+
+	expected := []RequestMetric{
+		{Operation: RouteStorageService, Method: "GET", StatusCode: http.StatusOK},
+		{Operation: "services/hostedservices/missing", Method: "GET", StatusCode: http.StatusNotFound},
+	}
+	if len(metrics.requests) != len(expected) {
+		t.Fatalf("Expected %d request metrics, got %+v", len(expected), metrics.requests)
+	}
+	for i, metric := range metrics.requests {
+		if metric.Duration <= 0 {
+			t.Errorf("Request %d has no duration", i)
+		}
+		metric.Duration = 0
+		if metric != expected[i] {
+			t.Errorf("Wrong request metric %d. Expected: %+v, got: %+v", i, expected[i], metric)
+		}
+	}
+}
+
+func TestMetricsRecordPolls(t *testing.T) {
+	server := newOperationServer(func(polls int) string {
+		if polls < 2 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := newTestClient(t, server.URL, ClientConfig{Metrics: metrics, DefaultPollInterval: 10 * time.Millisecond})
+	if err := client.WaitAsyncOperation("operation"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metrics.polls) != 2 {
+		t.Fatalf("Expected 2 poll metrics, got %+v", metrics.polls)
+	}
+	for i, status := range []string{"InProgress", "Succeeded"} {
+		poll := metrics.polls[i]
+		if poll.OperationID != "operation" || poll.Status != status || poll.Err != nil || poll.Duration <= 0 {
+			t.Errorf("Wrong poll metric %d: %+v", i, poll)
+		}
+	}
+	for _, request := range metrics.requests {
+		if request.Operation != RouteOperationStatus {
+			t.Errorf("Expected status checks to be named %s, got %s", RouteOperationStatus, request.Operation)
+		}
+	}
+}
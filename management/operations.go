@@ -1,9 +1,13 @@
 package management
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
 	"time"
 )
 
@@ -25,7 +29,7 @@ func (client *Client) getOperationStatus(operationId string) (*operation, error)
 	}
 
 	operation := new(operation)
-	url := "operations/" + operationId
+	url := client.Route(RouteOperationStatus, operationId)
 	response, azureErr := client.SendAzureGetRequest(url)
 	if azureErr != nil {
 		return nil, azureErr
@@ -39,29 +43,280 @@ func (client *Client) getOperationStatus(operationId string) (*operation, error)
 	return operation, nil
 }
 
+const (
+	//DefaultPollInterval is the time WaitAsyncOperation waits between two
+	//status checks when neither the call nor the client configures one.
+	DefaultPollInterval = 2 * time.Second
+
+	//DefaultOperationTimeout is the maximum time WaitAsyncOperation waits
+	//for an operation when neither the call nor the client configures one.
+	//It is NoOperationTimeout, so operations are waited on until they reach
+	//a terminal state.
+	DefaultOperationTimeout = NoOperationTimeout
+
+	//NoOperationTimeout can be passed as an operation timeout to wait for
+	//an operation indefinitely, even if the client sets a default timeout.
+	NoOperationTimeout time.Duration = -1
+
+	errOperationTimeout = "Azure operation %s did not complete within %s. Last known status: %s"
+)
+
+//WaitOption configures a single call to WaitAsyncOperation. Options given to
+//a call take precedence over the defaults of the client, which in turn take
+//precedence over the package defaults.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	pollInterval    time.Duration
+	timeout         time.Duration
+	ctx             context.Context
+	backoff         float64
+	maxPollInterval time.Duration
+	jitter          float64
+	progress        ProgressFunc
+
+	maxConcurrentWaits int
+}
+
+//OperationProgress describes the state of an operation at one status check
+//of WaitAsyncOperation.
+type OperationProgress struct {
+	OperationID string
+	Status      string
+
+	//Elapsed is the time since the wait started, and Polls the number of
+	//status checks made so far, including this one.
+	Elapsed time.Duration
+	Polls   int
+}
+
+//ProgressFunc is called by WaitAsyncOperation after every status check, from
+//the goroutine of the wait. It should return quickly.
+type ProgressFunc func(OperationProgress)
+
+//WithProgress makes fn be called after every status check of the wait,
+//instead of the ProgressFunc of the client, for example to update a spinner
+//or log state transitions.
+func WithProgress(fn ProgressFunc) WaitOption {
+	return func(options *waitOptions) {
+		options.progress = fn
+	}
+}
+
+//WithPollInterval sets the time to wait between two status checks. A zero
+//interval leaves the client default in place.
+func WithPollInterval(interval time.Duration) WaitOption {
+	return func(options *waitOptions) {
+		if interval != 0 {
+			options.pollInterval = interval
+		}
+	}
+}
+
+//WithOperationTimeout sets the maximum time to wait for the operation. A zero
+//timeout leaves the client default in place, NoOperationTimeout waits
+//indefinitely.
+func WithOperationTimeout(timeout time.Duration) WaitOption {
+	return func(options *waitOptions) {
+		if timeout != 0 {
+			options.timeout = timeout
+		}
+	}
+}
+
+//WithPollBackoff makes the time between two status checks grow by factor
+//after every check still finding the operation in progress, up to
+//maxInterval, so that long operations are checked less often. A factor of 1
+//or less keeps the interval constant, which is the default. A zero
+//maxInterval leaves the interval unbounded.
+func WithPollBackoff(factor float64, maxInterval time.Duration) WaitOption {
+	return func(options *waitOptions) {
+		options.backoff = factor
+		options.maxPollInterval = maxInterval
+	}
+}
+
+//nextPollInterval returns the interval to wait after a check that waited
+//interval.
+func (options waitOptions) nextPollInterval(interval time.Duration) time.Duration {
+	if options.backoff <= 1 {
+		return interval
+	}
+
+	next := time.Duration(float64(interval) * options.backoff)
+	if options.maxPollInterval > 0 && next > options.maxPollInterval {
+		next = options.maxPollInterval
+	}
+	if next < interval {
+		return interval
+	}
+	return next
+}
+
+//WithPollJitter randomizes the time between two status checks by up to
+//fraction of it, in either direction, so that waits started together spread
+//out their checks. fraction must be between 0 and 1; zero disables the
+//jitter.
+func WithPollJitter(fraction float64) WaitOption {
+	return func(options *waitOptions) {
+		options.jitter = fraction
+	}
+}
+
+//jitteredPollInterval returns interval randomized by the jitter of the
+//options.
+func (options waitOptions) jitteredPollInterval(interval time.Duration) time.Duration {
+	if options.jitter <= 0 || interval <= 0 {
+		return interval
+	}
+
+	spread := float64(interval) * math.Min(options.jitter, 1)
+	return interval + time.Duration(spread*(2*rand.Float64()-1))
+}
+
+//WithContext makes the wait end as soon as ctx is done. It overrides the
+//context of the client, see Client.WithContext. The operation itself keeps
+//running on the server; the returned *AbortedOperationError carries its ID so
+//the wait can be resumed later.
+func WithContext(ctx context.Context) WaitOption {
+	return func(options *waitOptions) {
+		options.ctx = ctx
+	}
+}
+
+//waitOptions resolves the options for a single wait, applying the call
+//options on top of the client and package defaults.
+func (client *Client) waitOptions(options ...WaitOption) waitOptions {
+	resolved := waitOptions{
+		pollInterval:    DefaultPollInterval,
+		timeout:         DefaultOperationTimeout,
+		ctx:             client.ctx,
+		progress:        client.progress,
+		backoff:         client.pollBackoff,
+		maxPollInterval: client.maxPollInterval,
+		jitter:          client.pollJitter,
+	}
+	if client.pollInterval > 0 {
+		resolved.pollInterval = client.pollInterval
+	}
+	if client.operationTimeout != 0 {
+		resolved.timeout = client.operationTimeout
+	}
+
+	for _, option := range options {
+		option(&resolved)
+	}
+
+	return resolved
+}
+
 //waitAsyncOperation blocks until the operation with the given operationId is
 //no longer in the InProgress state. If the operation was successful, nothing is
-//returned, otherwise an error is returned.
-func (client *Client) WaitAsyncOperation(operationId string) error {
+//returned, otherwise an error is returned. The poll interval and timeout
+//default to the ones configured on the client and can be overridden per call.
+//If the wait times out, its context is done or the client is shut down before
+//the operation completes, an *AbortedOperationError is returned; calling
+//WaitAsyncOperation again with its OperationID resumes the wait.
+func (client *Client) WaitAsyncOperation(operationId string, options ...WaitOption) error {
 	if operationId == "" {
 		return fmt.Errorf(errParamNotSpecified, "operationId")
 	}
 
+	waiter, err := client.lifecycle.beginWait(operationId)
+	if err != nil {
+		return err
+	}
+	defer client.lifecycle.endWait(waiter)
+
+	waitOptions := client.waitOptions(options...)
+	var deadline time.Time
+	if waitOptions.timeout > 0 {
+		deadline = time.Now().Add(waitOptions.timeout)
+	}
+	var done <-chan struct{}
+	pollClient := *client
+	if waitOptions.ctx != nil {
+		done = waitOptions.ctx.Done()
+		pollClient.ctx = waitOptions.ctx
+	}
+
 	status := "InProgress"
 	operation := new(operation)
-	err := errors.New("")
+	pollInterval := waitOptions.pollInterval
+	polls := 0
+	start := time.Now()
+	client.log().Debug("Waiting for operation", "operation", operationId)
 	for status == "InProgress" {
-		time.Sleep(2000 * time.Millisecond)
-		operation, err = client.getOperationStatus(operationId)
+		interval := waitOptions.jitteredPollInterval(pollInterval)
+		pollInterval = waitOptions.nextPollInterval(pollInterval)
+		if !deadline.IsZero() {
+			remaining := deadline.Sub(time.Now())
+			if remaining <= 0 {
+				err := &AbortedOperationError{
+					OperationID: operationId,
+					Err: &TimeoutError{
+						OperationID: operationId,
+						Timeout:     waitOptions.timeout,
+						LastStatus:  status,
+						Polls:       polls,
+					},
+				}
+				client.reportError(err)
+				return err
+			}
+			if remaining < interval {
+				interval = remaining
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-done:
+			timer.Stop()
+			return &AbortedOperationError{OperationID: operationId, Err: waitOptions.ctx.Err()}
+		case <-waiter.cancelled:
+			timer.Stop()
+			return &AbortedOperationError{OperationID: operationId, Err: ErrClientClosed}
+		case <-timer.C:
+		}
+
+		pollStart := time.Now()
+		operation, err = pollClient.getOperationStatus(operationId)
+		polls++
+		pollMetric := PollMetric{OperationID: operationId, Duration: time.Since(pollStart), Err: err}
+		if err == nil {
+			pollMetric.Status = operation.Status
+		}
+		client.recordPoll(pollMetric)
+		if errors.Is(err, ErrClientClosed) || (waitOptions.ctx != nil && waitOptions.ctx.Err() != nil) {
+			return &AbortedOperationError{OperationID: operationId, Err: err}
+		}
 		if err != nil {
 			return err
 		}
+		if waitOptions.progress != nil {
+			waitOptions.progress(OperationProgress{
+				OperationID: operationId,
+				Status:      operation.Status,
+				Elapsed:     time.Since(start),
+				Polls:       polls,
+			})
+		}
 
+		if operation.Status != status {
+			client.log().Info("Operation status changed", "operation", operationId, "from", status, "to", operation.Status)
+		}
 		status = operation.Status
 	}
 
 	if status == "Failed" {
-		return fmt.Errorf("Azure operation %s failed. Code: %s, Message: %s", operationId, operation.Error.Code, operation.Error.Message)
+		client.log().Error("Operation failed", "operation", operationId, "code", operation.Error.Code, "message", operation.Error.Message)
+		azureErr := operation.Error
+		azureErr.StatusCode, _ = strconv.Atoi(operation.HttpStatusCode)
+		azureErr.RequestID = operationId
+		err := &FailedOperationError{OperationID: operationId, Err: &azureErr}
+		client.reportError(err)
+		return err
 	}
 
 	return nil
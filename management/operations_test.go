@@ -0,0 +1,264 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitOptionsPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ClientConfig
+		options  []WaitOption
+		expected waitOptions
+	}{
+		{
+			name:     "package defaults",
+			expected: waitOptions{pollInterval: DefaultPollInterval, timeout: NoOperationTimeout},
+		},
+		{
+			name:     "client defaults",
+			config:   ClientConfig{DefaultPollInterval: time.Second, DefaultOperationTimeout: time.Hour},
+			expected: waitOptions{pollInterval: time.Second, timeout: time.Hour},
+		},
+		{
+			name:     "call overrides client",
+			config:   ClientConfig{DefaultPollInterval: time.Second, DefaultOperationTimeout: time.Hour},
+			options:  []WaitOption{WithPollInterval(5 * time.Second), WithOperationTimeout(5 * time.Minute)},
+			expected: waitOptions{pollInterval: 5 * time.Second, timeout: 5 * time.Minute},
+		},
+		{
+			name:     "call overrides package",
+			options:  []WaitOption{WithOperationTimeout(5 * time.Minute)},
+			expected: waitOptions{pollInterval: DefaultPollInterval, timeout: 5 * time.Minute},
+		},
+		{
+			name:     "zero call values keep client defaults",
+			config:   ClientConfig{DefaultPollInterval: time.Second, DefaultOperationTimeout: time.Hour},
+			options:  []WaitOption{WithPollInterval(0), WithOperationTimeout(0)},
+			expected: waitOptions{pollInterval: time.Second, timeout: time.Hour},
+		},
+		{
+			name:     "call disables client timeout",
+			config:   ClientConfig{DefaultOperationTimeout: time.Hour},
+			options:  []WaitOption{WithOperationTimeout(NoOperationTimeout)},
+			expected: waitOptions{pollInterval: DefaultPollInterval, timeout: NoOperationTimeout},
+		},
+		{
+			name:     "client polling backoff and jitter",
+			config:   ClientConfig{PollBackoff: 1.5, MaxPollInterval: time.Minute, PollJitter: 0.2},
+			expected: waitOptions{pollInterval: DefaultPollInterval, timeout: NoOperationTimeout, backoff: 1.5, maxPollInterval: time.Minute, jitter: 0.2},
+		},
+		{
+			name:     "call overrides client polling backoff and jitter",
+			config:   ClientConfig{PollBackoff: 1.5, MaxPollInterval: time.Minute, PollJitter: 0.2},
+			options:  []WaitOption{WithPollBackoff(1, 0), WithPollJitter(0)},
+			expected: waitOptions{pollInterval: DefaultPollInterval, timeout: NoOperationTimeout, backoff: 1},
+		},
+		{
+			name:     "last call option wins",
+			options:  []WaitOption{WithPollInterval(time.Second), WithPollInterval(3 * time.Second)},
+			expected: waitOptions{pollInterval: 3 * time.Second, timeout: NoOperationTimeout},
+		},
+	}
+
+	for _, test := range tests {
+		client := newTestClient(t, "https://management.example.com", test.config)
+		if actual := client.waitOptions(test.options...); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func newOperationServer(status func(polls int) string) *httptest.Server {
+	var polls int64
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		poll := int(atomic.AddInt64(&polls, 1))
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		body := fmt.Sprintf("<Operation><ID>%s</ID><Status>%s</Status></Operation>", id, status(poll))
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+}
+
+func TestWaitAsyncOperationUsesClientTimeout(t *testing.T) {
+	server := newOperationServer(func(int) string { return "InProgress" })
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{
+		DefaultPollInterval:     10 * time.Millisecond,
+		DefaultOperationTimeout: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	err := client.WaitAsyncOperation("operation")
+	if err == nil || !strings.Contains(err.Error(), "did not complete") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Timeout took too long: %v", elapsed)
+	}
+}
+
+func TestWaitAsyncOperationCallOptionsOverrideClient(t *testing.T) {
+	server := newOperationServer(func(polls int) string {
+		if polls < 5 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{
+		DefaultPollInterval:     time.Hour,
+		DefaultOperationTimeout: time.Millisecond,
+	})
+
+	err := client.WaitAsyncOperation("operation", WithPollInterval(5*time.Millisecond), WithOperationTimeout(NoOperationTimeout))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitAsyncOperationCancelReturnsOperationID(t *testing.T) {
+	server := newOperationServer(func(int) string { return "InProgress" })
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	err := client.WaitAsyncOperation("operation", WithContext(ctx))
+	var abortedErr *AbortedOperationError
+	if !errors.As(err, &abortedErr) {
+		t.Fatalf("Expected an AbortedOperationError, got %v", err)
+	}
+	if abortedErr.OperationID != "operation" {
+		t.Errorf("Expected operation ID %q, got %q", "operation", abortedErr.OperationID)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error to wrap context.Canceled, got %v", err)
+	}
+}
+
+func TestWaitAsyncOperationTimeoutReturnsOperationID(t *testing.T) {
+	server := newOperationServer(func(int) string { return "InProgress" })
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 10 * time.Millisecond})
+
+	err := client.WaitAsyncOperation("operation", WithOperationTimeout(30*time.Millisecond))
+	var abortedErr *AbortedOperationError
+	if !errors.As(err, &abortedErr) || abortedErr.OperationID != "operation" {
+		t.Fatalf("Expected an AbortedOperationError for operation, got %v", err)
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || !IsTimeout(err) {
+		t.Fatalf("Expected a TimeoutError, got %v", err)
+	}
+	if timeoutErr.LastStatus != "InProgress" || timeoutErr.Polls == 0 || timeoutErr.Timeout != 30*time.Millisecond {
+		t.Errorf("Expected the last known state of the operation, got %+v", timeoutErr)
+	}
+}
+
+func TestJitteredPollInterval(t *testing.T) {
+	options := waitOptions{jitter: 0.5}
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		interval := options.jitteredPollInterval(time.Second)
+		if interval < 500*time.Millisecond || interval > 1500*time.Millisecond {
+			t.Fatalf("Expected the interval to stay within the jitter, got %v", interval)
+		}
+		seen[interval] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected the intervals to be randomized, got %v", seen)
+	}
+
+	if interval := (waitOptions{}).jitteredPollInterval(time.Second); interval != time.Second {
+		t.Errorf("Expected no jitter by default, got %v", interval)
+	}
+}
+
+func TestWaitAsyncOperationPollBackoff(t *testing.T) {
+	options := waitOptions{backoff: 2, maxPollInterval: 50 * time.Millisecond}
+	var intervals []time.Duration
+	for interval := 10 * time.Millisecond; len(intervals) < 5; interval = options.nextPollInterval(interval) {
+		intervals = append(intervals, interval)
+	}
+	if expected := "[10ms 20ms 40ms 50ms 50ms]"; fmt.Sprint(intervals) != expected {
+		t.Errorf("Expected intervals %s, got %v", expected, intervals)
+	}
+	if interval := (waitOptions{}).nextPollInterval(10 * time.Millisecond); interval != 10*time.Millisecond {
+		t.Errorf("Expected a constant interval without backoff, got %v", interval)
+	}
+
+	server := newOperationServer(func(polls int) string {
+		if polls < 4 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{DefaultPollInterval: 5 * time.Millisecond})
+	start := time.Now()
+	if err := client.WaitAsyncOperation("operation", WithPollBackoff(2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Errorf("Expected the waits to double to 5+10+20+40ms, took %v", elapsed)
+	}
+}
+
+func TestWaitAsyncOperationReportsProgress(t *testing.T) {
+	server := newOperationServer(func(polls int) string {
+		if polls < 3 {
+			return "InProgress"
+		}
+		return "Succeeded"
+	})
+	defer server.Close()
+
+	var clientProgress, callProgress []OperationProgress
+	client := newTestClient(t, server.URL, ClientConfig{
+		DefaultPollInterval: 5 * time.Millisecond,
+		OperationProgress:   func(progress OperationProgress) { clientProgress = append(clientProgress, progress) },
+	})
+
+	err := client.WaitAsyncOperation("operation", WithProgress(func(progress OperationProgress) {
+		callProgress = append(callProgress, progress)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clientProgress) != 0 {
+		t.Errorf("Expected the call callback to replace the client one, got %v", clientProgress)
+	}
+	if len(callProgress) != 3 {
+		t.Fatalf("Expected 3 progress reports, got %v", callProgress)
+	}
+	for i, progress := range callProgress {
+		expected := "InProgress"
+		if i == 2 {
+			expected = "Succeeded"
+		}
+		if progress.OperationID != "operation" || progress.Status != expected || progress.Polls != i+1 {
+			t.Errorf("Unexpected progress report %d: %+v", i, progress)
+		}
+		if i > 0 && progress.Elapsed <= callProgress[i-1].Elapsed {
+			t.Errorf("Expected the elapsed time to grow, got %v", callProgress)
+		}
+	}
+}
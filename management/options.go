@@ -0,0 +1,97 @@
+package management
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// ClientOption sets a field of the ClientConfig of a client created with
+// NewClientWithOptions. Options are applied in order, so a later option
+// overrides an earlier one setting the same field.
+type ClientOption func(config *ClientConfig)
+
+// NewClientWithOptions creates a new Client using the given subscription ID and
+// management certificate, configured by options:
+//
+//	client, err := management.NewClientWithOptions(subscriptionID, cert,
+//		management.WithEnvironment(management.ChinaCloud),
+//		management.WithRetryPolicy(management.RetryPolicy{MaxAttempts: 3}),
+//		management.WithLogger(logger))
+func NewClientWithOptions(subscriptionID string, managementCert []byte, options ...ClientOption) (Client, error) {
+	return NewClientFromConfig(subscriptionID, managementCert, NewClientConfig(options...))
+}
+
+// NewClientConfig returns the ClientConfig set by options, for the
+// constructors taking a ClientConfig, such as NewClientFromTokenSource.
+func NewClientConfig(options ...ClientOption) ClientConfig {
+	var config ClientConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// WithEnvironment selects the Azure cloud the client talks to. See
+// ClientConfig.Environment.
+func WithEnvironment(environment Environment) ClientOption {
+	return func(config *ClientConfig) {
+		config.Environment = environment
+	}
+}
+
+// WithEndpoint overrides the management endpoint of the environment. See
+// ClientConfig.ManagementURL.
+func WithEndpoint(managementURL string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ManagementURL = managementURL
+	}
+}
+
+// WithAPIVersion sets the x-ms-version sent with every request. See
+// ClientConfig.APIVersion.
+func WithAPIVersion(version string) ClientOption {
+	return func(config *ClientConfig) {
+		config.APIVersion = version
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried. See
+// ClientConfig.RetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(config *ClientConfig) {
+		config.RetryPolicy = &policy
+	}
+}
+
+// WithLogger sets the Logger receiving the log events of the client.
+func WithLogger(logger Logger) ClientOption {
+	return func(config *ClientConfig) {
+		config.Logger = logger
+	}
+}
+
+// WithRequestTimeout bounds the time of every attempt of a request. See
+// ClientConfig.RequestTimeout.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(config *ClientConfig) {
+		config.RequestTimeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the connections of the client.
+// See ClientConfig.TLSConfig.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(config *ClientConfig) {
+		config.TLSConfig = tlsConfig
+	}
+}
+
+// WithHTTPClient sets the HTTP client sending the requests of the client. See
+// ClientConfig.HTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(config *ClientConfig) {
+		config.HTTPClient = httpClient
+	}
+}
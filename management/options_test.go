@@ -0,0 +1,41 @@
+package management
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	client, err := NewClientWithOptions("subscriptionID", []byte("cert"),
+		WithEnvironment(ChinaCloud),
+		WithEndpoint("https://management.example.com/"),
+		WithAPIVersion("2014-10-01"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3}),
+		WithLogger(nopLogger{}),
+		WithHTTPClient(httpClient),
+		WithAPIVersion("2015-04-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Environment().Name != ChinaCloud.Name {
+		t.Errorf("Expected the %s environment, got %s", ChinaCloud.Name, client.Environment().Name)
+	}
+	if client.managementURL != "https://management.example.com" {
+		t.Errorf("Wrong management URL: %s", client.managementURL)
+	}
+	if version := client.EffectiveAPIVersion(); version != "2015-04-01" {
+		t.Errorf("Expected the last API version option to win, got %s", version)
+	}
+	if client.retryPolicy.MaxAttempts != 3 || client.logger == nil {
+		t.Errorf("Expected the retry policy and logger to be set, got %+v and %v", client.retryPolicy, client.logger)
+	}
+	if client.httpClient.Transport != httpClient.Transport {
+		t.Errorf("Expected the injected HTTP client to be used")
+	}
+
+	if _, err := NewClientWithOptions("", []byte("cert")); err == nil {
+		t.Errorf("Expected a missing subscription ID to be reported")
+	}
+}
@@ -0,0 +1,81 @@
+package management
+
+import (
+	"encoding/xml"
+	"net/url"
+	"strings"
+)
+
+// continuationTokenParameter is the query parameter asking a list operation
+// for the page following the one that returned the token.
+const continuationTokenParameter = "ContinuationToken"
+
+// Pager fetches the pages of a list operation one at a time. A page whose
+// root element has a ContinuationToken child is followed by the page the
+// token designates; the last page has none. The list iterators of the service
+// sub-packages decode the pages of a Pager:
+//
+//	pager := management.NewPager(client, "services/images")
+//	for pager.Next() {
+//		// decode pager.Page()
+//	}
+//	if err := pager.Err(); err != nil {
+//		// handle the error
+//	}
+type Pager struct {
+	client ManagementClient
+	url    string
+	token  string
+	page   []byte
+	done   bool
+	err    error
+}
+
+// NewPager returns a Pager for the list operation at url, relative to the
+// subscription. No request is sent before the first call to Next.
+func NewPager(client ManagementClient, url string) *Pager {
+	return &Pager{client: client, url: url}
+}
+
+// Next fetches the next page. It returns false when there are no more pages
+// or the request failed; Err tells them apart.
+func (pager *Pager) Next() bool {
+	if pager.done {
+		return false
+	}
+
+	requestURL := pager.url
+	if pager.token != "" {
+		separator := "?"
+		if strings.Contains(requestURL, "?") {
+			separator = "&"
+		}
+		requestURL += separator + continuationTokenParameter + "=" + url.QueryEscape(pager.token)
+	}
+
+	page, err := pager.client.SendAzureGetRequest(requestURL)
+	if err != nil {
+		pager.page, pager.done, pager.err = nil, true, err
+		return false
+	}
+
+	var continuation struct {
+		ContinuationToken string
+	}
+	xml.Unmarshal(page, &continuation)
+
+	pager.page = page
+	pager.token = continuation.ContinuationToken
+	pager.done = pager.token == ""
+	return true
+}
+
+// Page returns the body of the page fetched by the last call to Next.
+func (pager *Pager) Page() []byte {
+	return pager.page
+}
+
+// Err returns the error that stopped Next, if any.
+func (pager *Pager) Err() error {
+	return pager.err
+}
@@ -0,0 +1,64 @@
+package management
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestPagerFollowsContinuationTokens(t *testing.T) {
+	pages := map[string]string{
+		"":    "<Images><OSImage>a</OSImage><ContinuationToken>p/2</ContinuationToken></Images>",
+		"p/2": "<Images><ContinuationToken>p 3</ContinuationToken></Images>",
+		"p 3": "<Images><OSImage>b</OSImage></Images>",
+	}
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("ContinuationToken")
+		tokens = append(tokens, token)
+		body := pages[token]
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	pager := NewPager(&client, "services/images")
+	var bodies []string
+	for pager.Next() {
+		bodies = append(bodies, string(pager.Page()))
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if pager.Next() {
+		t.Errorf("Expected Next to keep returning false at the end of the list")
+	}
+
+	if expected := `,p/2,p 3`; strings.Join(tokens, ",") != expected {
+		t.Errorf("Wrong continuation tokens. Expected: %s, got: %s", expected, strings.Join(tokens, ","))
+	}
+	if len(bodies) != 3 || bodies[2] != pages["p 3"] {
+		t.Errorf("Wrong pages: %v", bodies)
+	}
+}
+
+func TestPagerStopsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<Error><Code>ResourceNotFound</Code><Message>No such list</Message></Error>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+	pager := NewPager(&client, "services/images")
+	if pager.Next() {
+		t.Fatal("Expected Next to fail")
+	}
+	if !IsNotFound(pager.Err()) {
+		t.Errorf("Expected a not found error, got %v", pager.Err())
+	}
+}
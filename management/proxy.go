@@ -0,0 +1,86 @@
+package management
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ProxyFromEnvironment returns the URL of the proxy to use for request, as
+// given by the HTTPS_PROXY environment variable for https requests and
+// HTTP_PROXY for http requests, or their lower case versions. Hosts listed in
+// NO_PROXY, a comma-separated list of host names, domain suffixes or "*", are
+// reached directly, and so is localhost. A nil URL is returned when no proxy
+// should be used.
+//
+// It is the default of ClientConfig.Proxy.
+func ProxyFromEnvironment(request *http.Request) (*url.URL, error) {
+	var proxy string
+	if request.URL.Scheme == "https" {
+		proxy = getenv("HTTPS_PROXY", "https_proxy")
+	}
+	if proxy == "" {
+		proxy = getenv("HTTP_PROXY", "http_proxy")
+	}
+	if proxy == "" || !useProxy(request.URL.Host, getenv("NO_PROXY", "no_proxy")) {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") {
+		// Proxies are often given without a scheme, as in host:port.
+		if proxyURL, err := url.Parse("http://" + proxy); err == nil {
+			return proxyURL, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %q: %v", proxy, err)
+	}
+
+	return proxyURL, nil
+}
+
+// getenv returns the value of the first of the environment variables that is
+// set.
+func getenv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// useProxy reports whether requests to host, which may include a port, should
+// go through a proxy given the value of NO_PROXY.
+func useProxy(host string, noProxy string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return false
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if hostname, _, err := net.SplitHostPort(entry); err == nil {
+			entry = hostname
+		}
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return false
+		case host == strings.TrimPrefix(entry, "."):
+			return false
+		case strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")):
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,86 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newProxyServer answers every request itself, recording the absolute URLs
+// it was asked to fetch.
+func newProxyServer(urls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*urls = append(*urls, r.RequestURI)
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+}
+
+func TestClientUsesConfiguredProxy(t *testing.T) {
+	var urls []string
+	proxy := newProxyServer(&urls)
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := newTestClient(t, "http://management.example.com", ClientConfig{Proxy: http.ProxyURL(proxyURL)})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(urls) != 1 || urls[0] != "http://management.example.com/subscriptionID/locations" {
+		t.Errorf("Expected the request to go through the proxy, got %v", urls)
+	}
+}
+
+func TestClientUsesProxyFromEnvironment(t *testing.T) {
+	var urls []string
+	proxy := newProxyServer(&urls)
+	defer proxy.Close()
+
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "")
+	client := newTestClient(t, "http://management.example.com", ClientConfig{})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(urls) != 1 {
+		t.Errorf("Expected the request to go through the proxy, got %v", urls)
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http-proxy:3128")
+	t.Setenv("HTTPS_PROXY", "https://https-proxy:3129")
+	t.Setenv("NO_PROXY", "internal.example.com, .corp.example.com")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://management.core.windows.net/x", "https://https-proxy:3129"},
+		{"http://management.example.com/x", "http://http-proxy:3128"},
+		{"https://localhost:8443/x", ""},
+		{"https://internal.example.com/x", ""},
+		{"https://host.corp.example.com/x", ""},
+		{"https://corp.example.com/x", ""},
+		{"https://notinternal.example.com/x", "https://https-proxy:3129"},
+	}
+
+	for _, test := range tests {
+		request, _ := http.NewRequest("GET", test.url, nil)
+		proxyURL, err := ProxyFromEnvironment(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := ""
+		if proxyURL != nil {
+			actual = proxyURL.String()
+		}
+		if actual != test.expected {
+			t.Errorf("%s: expected proxy %q, got %q", test.url, test.expected, actual)
+		}
+	}
+}
@@ -5,100 +5,196 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"strings"
 )
 
-func (client *Client) importPublishSettings(id string, certPath string) error {
-	if id == "" {
-		return fmt.Errorf(errParamNotSpecified, "id")
+const (
+	errSubscriptionNotFound  = "No subscription with ID or name %q was found in the publish settings"
+	errSubscriptionAmbiguous = "%d subscriptions are named %q in the publish settings. Select one by ID."
+)
+
+// NewClientFromCertificateFile creates a new Client for the given
+// subscription, using the management certificate stored in the PEM file at
+// certPath. If config.ManagementURL is empty, the management endpoint of
+// config.Environment is used.
+func NewClientFromCertificateFile(subscriptionID string, certPath string, config ClientConfig) (Client, error) {
+	if subscriptionID == "" {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "subscriptionID")
 	}
 	if certPath == "" {
-		return fmt.Errorf(errParamNotSpecified, "certPath")
+		return Client{}, fmt.Errorf(errParamNotSpecified, "certPath")
 	}
 
 	cert, err := ioutil.ReadFile(certPath)
 	if err != nil {
-		return err
+		return Client{}, err
 	}
 
-	client.publishSettings.SubscriptionID = id
-	client.publishSettings.SubscriptionCert = cert
-	client.publishSettings.SubscriptionKey = cert
-
-	return nil
+	return makeClient(subscriptionID, cert, nil, config)
 }
 
-func (client *Client) importPublishSettingsFile(filePath string) error {
+// NewClientFromPublishSettingsFile creates a new Client for the subscription
+// of the publish settings file at filePath selected by config.Subscription,
+// or the first one. If config.ManagementURL is empty, the service management
+// URL of the subscription is used, and the environment is inferred from it
+// unless config.Environment is set.
+func NewClientFromPublishSettingsFile(filePath string, config ClientConfig) (Client, error) {
 	if filePath == "" {
-		return fmt.Errorf(errParamNotSpecified, "filePath")
+		return Client{}, fmt.Errorf(errParamNotSpecified, "filePath")
 	}
 
 	publishSettingsContent, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return err
+		return Client{}, err
+	}
+
+	return NewClientFromPublishSettingsData(publishSettingsContent, config)
+}
+
+// NewClientFromPublishSettingsReader is NewClientFromPublishSettingsFile for
+// publish settings read from reader, for example a secret store.
+func NewClientFromPublishSettingsReader(reader io.Reader, config ClientConfig) (Client, error) {
+	if reader == nil {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "reader")
+	}
+
+	publishSettingsContent, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return Client{}, err
+	}
+
+	return NewClientFromPublishSettingsData(publishSettingsContent, config)
+}
+
+// NewClientFromPublishSettingsData is NewClientFromPublishSettingsFile for
+// publish settings held in memory, for example taken from an environment
+// variable or embedded in a configuration file.
+func NewClientFromPublishSettingsData(publishSettingsContent []byte, config ClientConfig) (Client, error) {
+	if len(publishSettingsContent) == 0 {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "publishSettingsContent")
 	}
 
-	activeSubscription, err := getActiveSubscription(publishSettingsContent)
+	activeSubscription, err := getActiveSubscription(publishSettingsContent, config.Subscription)
 	if err != nil {
-		return err
+		return Client{}, err
 	}
 
 	cert, err := getSubscriptionCert(activeSubscription)
 	if err != nil {
-		return err
+		return Client{}, err
 	}
 
-	client.publishSettings.SubscriptionID = activeSubscription.Id
-	client.publishSettings.SubscriptionCert = cert
-	client.publishSettings.SubscriptionKey = cert
-	return nil
+	if config.ManagementURL == "" {
+		config.ManagementURL = strings.TrimSuffix(activeSubscription.ServiceManagementUrl, "/")
+	}
+
+	return makeClient(activeSubscription.Id, cert, nil, config)
 }
 
 func getSubscriptionCert(subscription subscription) ([]byte, error) {
-	certPassword := ""
-
 	pfxCert, err := base64.StdEncoding.DecodeString(subscription.ManagementCertificate)
 	if err != nil {
 		return nil, err
 	}
 
-	subscriptionCert, err := executeCommand(fmt.Sprintf("openssl pkcs12 -nodes -passin pass:%s", certPassword), pfxCert)
+	return LoadManagementCertificate(pfxCert, "")
+}
+
+//getActiveSubscription returns the subscription of the publish settings
+//selected by ID or name, or the first one if selection is empty.
+func getActiveSubscription(publishSettingsContent []byte, selection string) (subscription, error) {
+	subscriptions, err := parsePublishSettings(publishSettingsContent)
 	if err != nil {
-		return nil, err
+		return subscription{}, err
 	}
 
-	return subscriptionCert, nil
+	if selection == "" {
+		return subscriptions[0], nil
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.Id == selection {
+			return subscription, nil
+		}
+	}
+
+	var matches []subscription
+	for _, subscription := range subscriptions {
+		if strings.EqualFold(subscription.Name, selection) {
+			matches = append(matches, subscription)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return subscription{}, fmt.Errorf(errSubscriptionNotFound, selection)
+	case 1:
+		return matches[0], nil
+	default:
+		return subscription{}, fmt.Errorf(errSubscriptionAmbiguous, len(matches), selection)
+	}
 }
 
-func getActiveSubscription(publishSettingsContent []byte) (subscription, error) {
+//parsePublishSettings returns the subscriptions of all the publish profiles of
+//the publish settings, with the certificate and management URL of their
+//profile filled in where the subscription does not have its own, as in the
+//1.0 schema.
+func parsePublishSettings(publishSettingsContent []byte) ([]subscription, error) {
 	publishData := publishData{}
-	activeSubscription := subscription{}
-
 	err := xml.Unmarshal(publishSettingsContent, &publishData)
 	if err != nil {
-		return activeSubscription, err
+		return nil, err
 	}
 
 	if len(publishData.PublishProfiles) == 0 {
-		err = errors.New("No publish profiles were found")
-		return activeSubscription, err
+		return nil, errors.New("No publish profiles were found")
 	}
 
-	publishProfile := publishData.PublishProfiles[0]
-	subscriptions := publishProfile.Subscriptions
+	var subscriptions []subscription
+	for _, publishProfile := range publishData.PublishProfiles {
+		for _, subscription := range publishProfile.Subscriptions {
+			if subscription.ManagementCertificate == "" {
+				subscription.ManagementCertificate = publishProfile.ManagementCertificate
+				subscription.ServiceManagementUrl = publishProfile.Url
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
 	if len(subscriptions) == 0 {
-		err = errors.New("No subscriptions were found")
-		return activeSubscription, err
+		return nil, errors.New("No subscriptions were found")
 	}
 
-	activeSubscription = subscriptions[0]
+	return subscriptions, nil
+}
+
+// PublishSettingsSubscription describes one of the subscriptions of a publish
+// settings file.
+type PublishSettingsSubscription struct {
+	ID            string
+	Name          string
+	ManagementURL string
+}
+
+// Subscriptions lists the subscriptions of the given publish settings, in the
+// order of the file. Any of them can be selected by ID or name with
+// ClientConfig.Subscription.
+func Subscriptions(publishSettingsContent []byte) ([]PublishSettingsSubscription, error) {
+	subscriptions, err := parsePublishSettings(publishSettingsContent)
+	if err != nil {
+		return nil, err
+	}
 
-	if activeSubscription.ManagementCertificate == "" {
-		activeSubscription.ManagementCertificate = publishProfile.ManagementCertificate
-		activeSubscription.ServiceManagementUrl = publishProfile.Url
+	list := make([]PublishSettingsSubscription, len(subscriptions))
+	for i, subscription := range subscriptions {
+		list[i] = PublishSettingsSubscription{
+			ID:            subscription.Id,
+			Name:          subscription.Name,
+			ManagementURL: strings.TrimSuffix(subscription.ServiceManagementUrl, "/"),
+		}
 	}
 
-	return activeSubscription, nil
+	return list, nil
 }
 
 type publishSettings struct {
@@ -0,0 +1,175 @@
+package management
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClientsForDifferentSubscriptionsAreIndependent(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "publishsettings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var clients []Client
+	for _, subscriptionID := range []string{"subscription-a", "subscription-b"} {
+		certPath := filepath.Join(dir, subscriptionID+".pem")
+		if err := ioutil.WriteFile(certPath, testManagementCert(t), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		client, err := NewClientFromCertificateFile(subscriptionID, certPath, ClientConfig{ManagementURL: server.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+	}
+
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(client Client) {
+			defer wg.Done()
+			if _, err := client.SendAzureGetRequest("locations"); err != nil {
+				t.Error(err)
+			}
+		}(clients[i])
+	}
+	wg.Wait()
+
+	sort.Strings(paths)
+	expected := "/subscription-a/locations,/subscription-b/locations"
+	if actual := strings.Join(paths, ","); actual != expected {
+		t.Errorf("Expected requests to %s, got %s", expected, actual)
+	}
+}
+
+func TestNewClientFromCertificateFileDefaults(t *testing.T) {
+	file, err := ioutil.TempFile("", "cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.Write(testManagementCert(t))
+	file.Close()
+
+	client, err := NewClientFromCertificateFile("subscriptionID", file.Name(), ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.managementURL != PublicCloud.ManagementURL {
+		t.Errorf("Expected the default management URL, got %s", client.managementURL)
+	}
+
+	if _, err := NewClientFromCertificateFile("", file.Name(), ClientConfig{}); err == nil {
+		t.Errorf("Expected an error for a missing subscription ID")
+	}
+}
+
+// newTestPublishSettings returns publish settings for the given subscriptions,
+// as "id:name" pairs, all served by managementURL with a password-less PKCS#12
+// management certificate.
+func newTestPublishSettings(t *testing.T, managementURL string, subscriptions ...string) []byte {
+	certDER, keyDER := newTestCertificate(t)
+	pfx := base64.StdEncoding.EncodeToString(newTestPKCS12(t, certDER, keyDER, ""))
+
+	var content bytes.Buffer
+	content.WriteString(`<?xml version="1.0" encoding="utf-8"?><PublishData><PublishProfile SchemaVersion="2.0" PublishMethod="AzureServiceManagementAPI">`)
+	for _, subscription := range subscriptions {
+		idAndName := strings.SplitN(subscription, ":", 2)
+		fmt.Fprintf(&content, `<Subscription ServiceManagementUrl="%s" Id="%s" Name="%s" ManagementCertificate="%s" />`, managementURL, idAndName[0], idAndName[1], pfx)
+	}
+	content.WriteString(`</PublishProfile></PublishData>`)
+
+	return content.Bytes()
+}
+
+func TestNewClientFromPublishSettingsData(t *testing.T) {
+	publishSettings := newTestPublishSettings(t, "https://management.core.chinacloudapi.cn/", "subscription-a:Production")
+
+	fromData, err := NewClientFromPublishSettingsData(publishSettings, ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromReader, err := NewClientFromPublishSettingsReader(bytes.NewReader(publishSettings), ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, client := range []Client{fromData, fromReader} {
+		if client.publishSettings.SubscriptionID != "subscription-a" {
+			t.Errorf("Expected subscription-a, got %s", client.publishSettings.SubscriptionID)
+		}
+		if client.managementURL != "https://management.core.chinacloudapi.cn" || client.Environment().Name != ChinaCloud.Name {
+			t.Errorf("Expected the China cloud from the publish settings, got %s and %s", client.managementURL, client.Environment().Name)
+		}
+	}
+
+	if _, err := NewClientFromPublishSettingsData(nil, ClientConfig{}); err == nil {
+		t.Errorf("Expected empty publish settings to be rejected")
+	}
+}
+
+func TestPublishSettingsSubscriptionSelection(t *testing.T) {
+	publishSettings := newTestPublishSettings(t, "https://management.core.windows.net/", "id-a:Production", "id-b:Staging", "id-c:Test", "id-d:test")
+
+	subscriptions, err := Subscriptions(publishSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subscriptions) != 4 || subscriptions[1] != (PublishSettingsSubscription{ID: "id-b", Name: "Staging", ManagementURL: "https://management.core.windows.net"}) {
+		t.Errorf("Unexpected subscriptions %+v", subscriptions)
+	}
+
+	tests := []struct {
+		selection string
+		expected  string
+		err       string
+	}{
+		{"", "id-a", ""},
+		{"id-b", "id-b", ""},
+		{"staging", "id-b", ""},
+		{"id-d", "id-d", ""},
+		{"Test", "", "2 subscriptions are named"},
+		{"Missing", "", "No subscription with ID or name"},
+	}
+
+	for _, test := range tests {
+		client, err := NewClientFromPublishSettingsData(publishSettings, ClientConfig{Subscription: test.selection})
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q: expected an error containing %q, got %v", test.selection, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.selection, err)
+			continue
+		}
+		if client.publishSettings.SubscriptionID != test.expected {
+			t.Errorf("%q: expected subscription %s, got %s", test.selection, test.expected, client.publishSettings.SubscriptionID)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package management
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	remainingReadsHeader  = "X-Ms-Ratelimit-Remaining-Subscription-Reads"
+	remainingWritesHeader = "X-Ms-Ratelimit-Remaining-Subscription-Writes"
+
+	// maxQuotaSlowdown bounds how much the RateLimiter of a client is
+	// slowed down when the remaining quota is exhausted.
+	maxQuotaSlowdown = 10
+)
+
+// QuotaSnapshot is the remaining subscription-level request budget, as last
+// reported by the service in the x-ms-ratelimit-remaining-subscription-*
+// response headers.
+type QuotaSnapshot struct {
+	Reads  QuotaValue
+	Writes QuotaValue
+}
+
+// QuotaValue is the remaining budget of one request category. Known is false
+// until the service has reported a value for the category.
+type QuotaValue struct {
+	Known     bool
+	Remaining int64
+	UpdatedAt time.Time
+}
+
+// quotaTracker holds the latest reported budgets. It is shared by all copies
+// of a Client and is safe for concurrent use.
+type quotaTracker struct {
+	reads  quotaCounter
+	writes quotaCounter
+}
+
+type quotaCounter struct {
+	remaining atomic.Int64
+	updatedAt atomic.Int64
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{}
+}
+
+// record updates the budgets from the headers of a response. Categories the
+// response does not report keep their previous value.
+func (tracker *quotaTracker) record(header http.Header) {
+	if tracker == nil {
+		return
+	}
+
+	now := time.Now()
+	tracker.reads.record(header.Get(remainingReadsHeader), now)
+	tracker.writes.record(header.Get(remainingWritesHeader), now)
+}
+
+func (tracker *quotaTracker) snapshot() QuotaSnapshot {
+	if tracker == nil {
+		return QuotaSnapshot{}
+	}
+
+	return QuotaSnapshot{
+		Reads:  tracker.reads.value(),
+		Writes: tracker.writes.value(),
+	}
+}
+
+func (counter *quotaCounter) record(value string, now time.Time) {
+	if value == "" {
+		return
+	}
+
+	remaining, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || remaining < 0 {
+		return
+	}
+
+	counter.remaining.Store(remaining)
+	counter.updatedAt.Store(now.UnixNano())
+}
+
+func (counter *quotaCounter) value() QuotaValue {
+	updatedAt := counter.updatedAt.Load()
+	if updatedAt == 0 {
+		return QuotaValue{}
+	}
+
+	return QuotaValue{
+		Known:     true,
+		Remaining: counter.remaining.Load(),
+		UpdatedAt: time.Unix(0, updatedAt),
+	}
+}
+
+// RemainingQuota returns the remaining subscription-level request budget as
+// last reported by the service. The budget is shared by all copies of the
+// client, including the ones held by service sub-clients.
+func (client *Client) RemainingQuota() QuotaSnapshot {
+	return client.quota.snapshot()
+}
+
+// slowdown returns the factor the request rate should be divided by for
+// the remaining budget of snapshot: 1 while every known budget is at least
+// threshold, and up to maxQuotaSlowdown as the lowest budget below threshold
+// approaches zero.
+func (snapshot QuotaSnapshot) slowdown(threshold int64) float64 {
+	factor := 1.0
+	if threshold <= 0 {
+		return factor
+	}
+
+	for _, value := range []QuotaValue{snapshot.Reads, snapshot.Writes} {
+		if !value.Known || value.Remaining >= threshold {
+			continue
+		}
+		remaining := value.Remaining
+		if remaining < 1 {
+			remaining = 1
+		}
+		if f := float64(threshold) / float64(remaining); f > factor {
+			factor = f
+		}
+	}
+
+	if factor > maxQuotaSlowdown {
+		factor = maxQuotaSlowdown
+	}
+	return factor
+}
+
+// adaptRateLimit slows down the RateLimiter of the client while the
+// remaining quota is below the QuotaThreshold of the client, and restores
+// its rate once the quota recovers.
+func (client *Client) adaptRateLimit() {
+	if client.rateLimiter == nil || client.quotaThreshold <= 0 {
+		return
+	}
+
+	client.rateLimiter.setSlowdown(client.quota.snapshot().slowdown(client.quotaThreshold))
+}
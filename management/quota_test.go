@@ -0,0 +1,148 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRemainingQuota(t *testing.T) {
+	responses := []map[string]string{
+		{},
+		{"x-ms-ratelimit-remaining-subscription-reads": "100"},
+		{"x-ms-ratelimit-remaining-subscription-reads": "99", "x-ms-ratelimit-remaining-subscription-writes": "20"},
+		{"x-ms-ratelimit-remaining-subscription-writes": "19"},
+		{"x-ms-ratelimit-remaining-subscription-reads": "garbage"},
+	}
+	expected := []struct {
+		readsKnown  bool
+		reads       int64
+		writesKnown bool
+		writes      int64
+	}{
+		{false, 0, false, 0},
+		{true, 100, false, 0},
+		{true, 99, true, 20},
+		{true, 99, true, 19},
+		{true, 99, true, 19},
+	}
+
+	request := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range responses[request] {
+			w.Header().Set(name, value)
+		}
+		request++
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	if snapshot := client.RemainingQuota(); snapshot.Reads.Known || snapshot.Writes.Known {
+		t.Fatalf("Expected an unknown quota before the first request, got %+v", snapshot)
+	}
+
+	for i := range responses {
+		if _, err := client.SendAzureRequest("locations", "GET", "", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot := client.RemainingQuota()
+		actual := fmt.Sprintf("%v %d %v %d", snapshot.Reads.Known, snapshot.Reads.Remaining, snapshot.Writes.Known, snapshot.Writes.Remaining)
+		wanted := fmt.Sprintf("%v %d %v %d", expected[i].readsKnown, expected[i].reads, expected[i].writesKnown, expected[i].writes)
+		if actual != wanted {
+			t.Fatalf("Wrong quota after response %d. Expected: '%s', got: '%s'", i, wanted, actual)
+		}
+		if snapshot.Reads.Known && snapshot.Reads.UpdatedAt.IsZero() {
+			t.Fatalf("Expected the update time to be recorded after response %d", i)
+		}
+	}
+}
+
+func TestRemainingQuotaIsSharedByCopies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-ratelimit-remaining-subscription-writes", "42")
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	clientCopy := client
+	if _, err := clientCopy.SendAzureRequest("locations", "GET", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot := client.RemainingQuota(); !snapshot.Writes.Known || snapshot.Writes.Remaining != 42 {
+		t.Fatalf("Expected the quota recorded through a copy to be visible, got %+v", snapshot)
+	}
+}
+
+func TestQuotaThresholdSlowsDownRateLimiter(t *testing.T) {
+	remaining := []string{"200", "100", "50", "10", "0", "", "150"}
+	expected := []float64{1, 1, 2, 10, 10, 10, 1}
+
+	request := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if remaining[request] != "" {
+			w.Header().Set("x-ms-ratelimit-remaining-subscription-reads", remaining[request])
+		}
+		request++
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(1000, 100)
+	client := newTestClient(t, server.URL, ClientConfig{RateLimiter: limiter, QuotaThreshold: 100})
+	for i := range remaining {
+		if _, err := client.SendAzureRequest("locations", "GET", "", nil); err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot := client.RemainingQuota()
+		if remaining[i] != "" && fmt.Sprint(snapshot.Reads.Remaining) != remaining[i] {
+			t.Fatalf("Wrong quota after response %d. Expected: '%s', got: %d", i, remaining[i], snapshot.Reads.Remaining)
+		}
+
+		limiter.mu.Lock()
+		interval := limiter.currentInterval()
+		limiter.mu.Unlock()
+		if wanted := time.Duration(float64(time.Millisecond) * expected[i]); interval != wanted {
+			t.Fatalf("Wrong limiter interval after %s remaining reads. Expected: %v, got: %v", remaining[i], wanted, interval)
+		}
+	}
+}
+
+func TestQuotaThresholdDelaysRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-ratelimit-remaining-subscription-writes", "0")
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	// 200 requests per second, slowed down to 20 once the writes run out.
+	limiter := NewRateLimiter(200, 1)
+	client := newTestClient(t, server.URL, ClientConfig{RateLimiter: limiter, QuotaThreshold: 10})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.SendAzureRequest("locations", "GET", "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected the requests after the quota ran out to be 50ms apart, 3 requests took %v", elapsed)
+	}
+
+	unadapted := newTestClient(t, server.URL, ClientConfig{RateLimiter: NewRateLimiter(200, 1)})
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := unadapted.SendAzureRequest("locations", "GET", "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 70*time.Millisecond {
+		t.Errorf("Expected requests without a quota threshold not to be slowed down, 3 requests took %v", elapsed)
+	}
+}
@@ -0,0 +1,114 @@
+package management
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of requests sent to the
+// management API. It is safe for concurrent use, so a single RateLimiter can
+// be shared through ClientConfig.RateLimiter by all the clients of a
+// subscription, and all the sub-packages using them, to keep them within
+// one request budget.
+type RateLimiter struct {
+	interval time.Duration
+	burst    int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// slowdown is the factor the interval is multiplied by while the
+	// remaining subscription quota is low; see ClientConfig.QuotaThreshold.
+	// Zero means 1.
+	slowdown float64
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond requests
+// per second on average, and bursts of up to burst requests. A burst below 1
+// is taken as 1.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	var interval time.Duration
+	if requestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+
+	return &RateLimiter{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent, or ctx is done, in which case
+// ctx.Err() is returned. A nil RateLimiter, or one without a positive rate,
+// never blocks.
+func (limiter *RateLimiter) Wait(ctx context.Context) error {
+	if limiter == nil || limiter.interval <= 0 {
+		return ctx.Err()
+	}
+
+	delay := limiter.reserve()
+	err := sleepContext(ctx, delay)
+	if err != nil {
+		limiter.cancel()
+	}
+	return err
+}
+
+// reserve takes a token, possibly ahead of time, and returns how long to wait
+// for it.
+func (limiter *RateLimiter) reserve() time.Duration {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	interval := limiter.currentInterval()
+	now := time.Now()
+	limiter.tokens += float64(now.Sub(limiter.last)) / float64(interval)
+	if limiter.tokens > float64(limiter.burst) {
+		limiter.tokens = float64(limiter.burst)
+	}
+	limiter.last = now
+
+	limiter.tokens--
+	if limiter.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-limiter.tokens * float64(interval))
+}
+
+// currentInterval returns the interval between requests, slowed down while
+// the remaining quota is low. It must be called with mu held.
+func (limiter *RateLimiter) currentInterval() time.Duration {
+	if limiter.slowdown <= 1 {
+		return limiter.interval
+	}
+	return time.Duration(float64(limiter.interval) * limiter.slowdown)
+}
+
+// setSlowdown makes the limiter allow 1/factor of its configured rate, or
+// its configured rate if factor is 1 or less.
+func (limiter *RateLimiter) setSlowdown(factor float64) {
+	if limiter == nil {
+		return
+	}
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	limiter.slowdown = factor
+}
+
+// cancel returns the token of a wait that was abandoned.
+func (limiter *RateLimiter) cancel() {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	limiter.tokens++
+}
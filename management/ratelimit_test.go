@@ -0,0 +1,64 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(100, 2)
+	first := newTestClient(t, server.URL, ClientConfig{RateLimiter: limiter})
+	second := newTestClient(t, server.URL, ClientConfig{RateLimiter: limiter})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(client Client) {
+			defer wg.Done()
+			if _, err := client.SendAzureGetRequest("locations"); err != nil {
+				t.Error(err)
+			}
+		}([]Client{first, second}[i%2])
+	}
+	wg.Wait()
+
+	// A burst of 2, then 4 requests 10ms apart.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected the 6 requests to take at least 40ms, took %v", elapsed)
+	}
+	if requests != 6 {
+		t.Errorf("Expected 6 requests, got %d", requests)
+	}
+}
+
+func TestRateLimiterWaitHonorsContext(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+
+	var unlimited *RateLimiter
+	if err := unlimited.Wait(context.Background()); err != nil {
+		t.Errorf("Expected a nil limiter not to block, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package management
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// scrubbedSubscriptionID replaces the subscription ID in recorded
+// interactions.
+const scrubbedSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// RecorderMode selects whether a Recorder talks to the service.
+type RecorderMode int
+
+const (
+	// RecordOrReplay replays the fixture file if it exists, and records
+	// a new one otherwise.
+	RecordOrReplay RecorderMode = iota
+
+	// Record always sends requests to the service, and records them.
+	Record
+
+	// Replay never sends requests to the service. Requests that were not
+	// recorded fail.
+	Replay
+)
+
+// Recorder records the interactions of clients with the management API to a
+// fixture file, and replays them later, so that tests do not need a live
+// subscription on every run. Secrets are scrubbed from the recording: the
+// Authorization header, the sensitive elements of bodies, as in traces (see
+// ClientConfig.TraceWriter), and the subscription ID. Requests are replayed
+// in the order they were recorded, matched by method and URL path and query.
+//
+// A Recorder is installed with ClientConfig.WrapTransport:
+//
+//	recorder, err := management.NewRecorder("testdata/create_vm.json", management.RecordOrReplay)
+//	...
+//	defer recorder.Save()
+//	client, err := management.NewClientFromConfig(subscriptionID, cert, management.ClientConfig{
+//		WrapTransport: recorder.Wrap,
+//	})
+type Recorder struct {
+	path string
+
+	mu           sync.Mutex
+	replaying    bool
+	interactions []interaction
+	replayed     []bool
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// NewRecorder returns a Recorder for the fixture file at path.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	if path == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "path")
+	}
+
+	recorder := &Recorder{path: path}
+	if mode == Record {
+		return recorder, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mode == RecordOrReplay {
+		return recorder, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &recorder.interactions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", path, err)
+	}
+	recorder.replaying = true
+	recorder.replayed = make([]bool, len(recorder.interactions))
+
+	return recorder, nil
+}
+
+// Replaying reports whether the recorder replays a fixture file rather than
+// recording one.
+func (recorder *Recorder) Replaying() bool {
+	return recorder.replaying
+}
+
+// Wrap returns a transport that records the requests sent through transport,
+// or replays them without using transport.
+func (recorder *Recorder) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: recorder, transport: transport}
+}
+
+// Save writes the recorded interactions to the fixture file. It does nothing
+// when replaying.
+func (recorder *Recorder) Save() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.replaying {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(recorder.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(recorder.path, append(content, '\n'), 0644)
+}
+
+// record scrubs and stores an interaction.
+func (recorder *Recorder) record(request *http.Request, requestBody []byte, response *http.Response, responseBody []byte) {
+	subscriptionID := subscriptionFromPath(request.URL.Path)
+	scrub := func(s string) string {
+		if subscriptionID != "" {
+			s = strings.Replace(s, subscriptionID, scrubbedSubscriptionID, -1)
+		}
+		return s
+	}
+
+	recorded := interaction{
+		Request: recordedRequest{
+			Method: request.Method,
+			URL:    scrub(request.URL.String()),
+			Header: scrubHeader(request.Header, scrub),
+			Body:   scrub(string(redactBody(requestBody))),
+		},
+		Response: recordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     scrubHeader(response.Header, scrub),
+			Body:       scrub(string(redactBody(responseBody))),
+		},
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.interactions = append(recorder.interactions, recorded)
+}
+
+// replay returns the first interaction not replayed yet that matches
+// request.
+func (recorder *Recorder) replay(request *http.Request) (*interaction, error) {
+	key := replayKey(request.Method, request.URL.RequestURI())
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for i := range recorder.interactions {
+		if recorder.replayed[i] {
+			continue
+		}
+
+		recorded := &recorder.interactions[i]
+		recordedURL, err := parseRecordedURL(recorded.Request.URL)
+		if err != nil || replayKey(recorded.Request.Method, recordedURL) != key {
+			continue
+		}
+
+		recorder.replayed[i] = true
+		return recorded, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction for %s %s in %s", request.Method, request.URL.RequestURI(), recorder.path)
+}
+
+// recordingTransport is the transport returned by Recorder.Wrap.
+type recordingTransport struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.recorder.replaying {
+		recorded, err := t.recorder.replay(request)
+		if err != nil {
+			return nil, err
+		}
+
+		body := []byte(recorded.Response.Body)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.Response.StatusCode, http.StatusText(recorded.Response.StatusCode)),
+			StatusCode:    recorded.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cloneHeader(recorded.Response.Header),
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       request,
+		}, nil
+	}
+
+	if t.transport == nil {
+		return nil, errors.New("azure: recorder has no transport to record")
+	}
+
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	response, err := t.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := decompressResponse(response); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	t.recorder.record(request, requestBody, response, responseBody)
+	return response, nil
+}
+
+// subscriptionFromPath returns the first segment of a management API path,
+// which is the subscription ID.
+func subscriptionFromPath(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[0]
+}
+
+// replayKey identifies a request by method and path, without its
+// subscription ID, and query.
+func replayKey(method string, requestURI string) string {
+	path := strings.TrimPrefix(requestURI, "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i:]
+	}
+	return method + " " + path
+}
+
+// parseRecordedURL returns the request URI of a recorded URL.
+func parseRecordedURL(rawURL string) (string, error) {
+	recordedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return recordedURL.RequestURI(), nil
+}
+
+func scrubHeader(header http.Header, scrub func(string) string) http.Header {
+	scrubbed := make(http.Header, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			scrubbed[name] = append(scrubbed[name], scrub(value))
+		}
+	}
+	return scrubbed
+}
+
+func cloneHeader(header http.Header) http.Header {
+	cloned := make(http.Header, len(header))
+	for name, values := range header {
+		cloned[name] = append([]string(nil), values...)
+	}
+	return cloned
+}
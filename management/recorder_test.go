@@ -0,0 +1,105 @@
+package management
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRecordsAndReplays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "<StorageServices><StorageService><Url>" + "http://" + r.Host + r.URL.Path + "/account</Url><ServiceName>account</ServiceName><StorageServiceKeys><Primary>c2VjcmV0</Primary></StorageServiceKeys></StorageService></StorageServices>"
+		if r.Method == "POST" {
+			w.Header().Set("x-ms-request-id", "request-1")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+
+	recorder, err := NewRecorder(fixture, RecordOrReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Replaying() {
+		t.Fatal("Expected a missing fixture to be recorded")
+	}
+
+	hook := func(request *http.Request) error {
+		request.Header.Set("Authorization", "Bearer token")
+		return nil
+	}
+	client, err := NewClientFromConfig("a1b2c3d4-subscription", testManagementCert(t), ClientConfig{
+		ManagementURL: server.URL,
+		WrapTransport: recorder.Wrap,
+		RequestHooks:  []RequestHook{hook},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded, err := client.SendAzureGetRequest("services/storageservices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestID, err := client.SendAzurePostRequest("services/storageservices", []byte("<Password>hunter2</Password>"))
+	if err != nil || requestID != "request-1" {
+		t.Fatalf("Expected request-1, got %s, %v", requestID, err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	content, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"a1b2c3d4-subscription", "Bearer token", "c2VjcmV0", "hunter2"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("Expected %q to be scrubbed from the fixture:\n%s", secret, content)
+		}
+	}
+
+	replayer, err := NewRecorder(fixture, RecordOrReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replayer.Replaying() {
+		t.Fatal("Expected an existing fixture to be replayed")
+	}
+	replayClient, err := NewClientFromConfig("another-subscription", testManagementCert(t), ClientConfig{
+		ManagementURL: "https://management.example.com",
+		WrapTransport: replayer.Wrap,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := replayClient.SendAzureGetRequest("services/storageservices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(strings.Replace(string(recorded), "a1b2c3d4-subscription", scrubbedSubscriptionID, 1), "c2VjcmV0", redacted, 1); string(replayed) != expected {
+		t.Errorf("Expected the scrubbed recorded body %s, got %s", expected, replayed)
+	}
+	if requestID, err := replayClient.SendAzurePostRequest("services/storageservices", nil); err != nil || requestID != "request-1" {
+		t.Errorf("Expected the recorded request ID, got %s, %v", requestID, err)
+	}
+
+	replayClient.retryPolicy = RetryPolicy{MaxAttempts: 1}
+	if _, err := replayClient.SendAzureGetRequest("services/storageservices"); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("Expected requests beyond the recording to fail, got %v", err)
+	}
+}
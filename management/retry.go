@@ -0,0 +1,186 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const defaultMaxAttempts = 8
+
+// RetryPolicy controls how the client retries requests that failed, either
+// in the transport or with an error response from the service. It applies to
+// every request, including the ones made by the service sub-packages.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, including the
+	// first attempt. Zero selects the default of 8; 1 disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// each further retry, up to MaxBackoff if that is not zero.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Jitter randomizes each delay by up to this fraction of it, in either
+	// direction, so that clients retrying together spread out. It must be
+	// between 0 and 1.
+	Jitter float64
+
+	// RetryableStatusCodes lists the status codes of the error responses
+	// that are retried. If empty, every error response is retried. Transport
+	// failures are retried if they are transient, see
+	// IsTransientNetworkError, and errors of request hooks always are.
+	RetryableStatusCodes []int
+
+	// RetryNonIdempotent opts in to retrying POST requests, which may have
+	// been carried out by the service even though they failed, for example
+	// when the connection was reset before the response was received. By
+	// default they are only retried when throttled.
+	RetryNonIdempotent bool
+
+	// Throttled requests, answered with status 429 or 503, are retried
+	// after the delay given by the Retry-After header of the response, or
+	// after the backoff if there is none. These retries do not count as
+	// attempts; instead, at most MaxThrottleRetries of them are made, waiting
+	// no more than MaxThrottleWait in total, before a *ThrottledError is
+	// returned. Zero values select the defaults of 5 retries and 2 minutes;
+	// a negative MaxThrottleRetries disables the retries and a negative
+	// MaxThrottleWait removes the limit on the wait.
+	MaxThrottleRetries int
+	MaxThrottleWait    time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used by clients whose ClientConfig
+// does not set one: up to 8 attempts of idempotent requests, without delays
+// between them, for any error response or transient network failure, and the
+// default throttling budget.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: defaultMaxAttempts}
+}
+
+func (policy RetryPolicy) maxAttempts() int {
+	if policy.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+
+	return policy.MaxAttempts
+}
+
+func (policy RetryPolicy) maxThrottleRetries() int {
+	if policy.MaxThrottleRetries == 0 {
+		return defaultMaxThrottleRetries
+	}
+	if policy.MaxThrottleRetries < 0 {
+		return 0
+	}
+
+	return policy.MaxThrottleRetries
+}
+
+func (policy RetryPolicy) maxThrottleWait() time.Duration {
+	if policy.MaxThrottleWait == 0 {
+		return defaultMaxThrottleWait
+	}
+	if policy.MaxThrottleWait < 0 {
+		return 0
+	}
+
+	return policy.MaxThrottleWait
+}
+
+// isRetryable reports whether a request with the given method that failed
+// with statusCode and err may be retried. A zero statusCode is a failure
+// before a response was received.
+func (policy RetryPolicy) isRetryable(method string, statusCode int, err error) bool {
+	if !isIdempotent(method) && !policy.RetryNonIdempotent {
+		return false
+	}
+	if statusCode == 0 {
+		var urlErr *url.Error
+		return !errors.As(err, &urlErr) || IsTransientNetworkError(err)
+	}
+	if len(policy.RetryableStatusCodes) == 0 {
+		return true
+	}
+
+	for _, retryable := range policy.RetryableStatusCodes {
+		if statusCode == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdempotent reports whether sending a request with method twice has the
+// same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// IsTransientNetworkError reports whether err, or any error it wraps, is a
+// network failure that may not happen again, such as a connection reset or
+// refused, a connection closed before the response was complete, or a
+// timeout. Requests that failed with such errors are retried.
+func IsTransientNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the delay before the retry that follows the given attempt.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < attempt && delay > 0; i++ {
+		delay *= 2
+		if policy.MaxBackoff > 0 && delay >= policy.MaxBackoff {
+			break
+		}
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+
+	if policy.Jitter > 0 && delay > 0 {
+		spread := float64(delay) * policy.Jitter
+		delay += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+
+	return delay
+}
+
+// sleepContext pauses for d, or until ctx is done, in which case ctx.Err()
+// is returned.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,211 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newFlakyServer fails the first failures requests with statusCode and
+// counts the requests it receives.
+func newFlakyServer(failures int64, statusCode int, requests *int64) *httptest.Server {
+	return newThrottlingServer(failures, statusCode, "", requests)
+}
+
+// newThrottlingServer is a newFlakyServer whose error responses carry the
+// given Retry-After header, unless it is empty.
+func newThrottlingServer(failures int64, statusCode int, retryAfter string, requests *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := "<Locations/>"
+		status := http.StatusOK
+		if atomic.AddInt64(requests, 1) <= failures {
+			body = "<Error><Code>InternalError</Code><Message>Try again</Message></Error>"
+			status = statusCode
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestRetryPolicyRetriesWithBackoff(t *testing.T) {
+	var requests int64
+	server := newFlakyServer(2, http.StatusInternalServerError, &requests)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+	}})
+
+	start := time.Now()
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 attempts, got %d", requests)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected backoffs of 10ms and 20ms, took %v", elapsed)
+	}
+}
+
+func TestRetryPolicyLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		status   int
+		expected int64
+	}{
+		{"default", nil, http.StatusInternalServerError, defaultMaxAttempts},
+		{"max attempts", &RetryPolicy{MaxAttempts: 2}, http.StatusInternalServerError, 2},
+		{"no retries", &RetryPolicy{MaxAttempts: 1}, http.StatusInternalServerError, 1},
+		{"retryable status", &RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{http.StatusBadGateway}}, http.StatusBadGateway, 3},
+		{"not retryable status", &RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []int{http.StatusBadGateway}}, http.StatusNotFound, 1},
+	}
+
+	for _, test := range tests {
+		var requests int64
+		server := newFlakyServer(100, test.status, &requests)
+		client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: test.policy})
+
+		if _, err := client.SendAzureGetRequest("locations"); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if requests != test.expected {
+			t.Errorf("%s: expected %d attempts, got %d", test.name, test.expected, requests)
+		}
+		server.Close()
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 350 * time.Millisecond}
+	for attempt, expected := range []time.Duration{100, 200, 350, 350} {
+		if actual := policy.backoff(attempt + 1); actual != expected*time.Millisecond {
+			t.Errorf("Attempt %d: expected %v, got %v", attempt+1, expected*time.Millisecond, actual)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if actual := policy.backoff(1); actual < 50*time.Millisecond || actual > 150*time.Millisecond {
+			t.Fatalf("Expected jittered backoff within 50ms of 100ms, got %v", actual)
+		}
+	}
+}
+
+func TestRetryPolicyDoesNotRetryPostsByDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		expected int64
+	}{
+		{"default", nil, 1},
+		{"opted in", &RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true}, 3},
+	}
+
+	for _, test := range tests {
+		var requests int64
+		server := newFlakyServer(100, http.StatusInternalServerError, &requests)
+		client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: test.policy})
+
+		if _, err := client.SendAzurePostRequest("services/storageservices", []byte("<x/>")); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if requests != test.expected {
+			t.Errorf("%s: expected %d attempts, got %d", test.name, test.expected, requests)
+		}
+		server.Close()
+	}
+}
+
+func TestRetryPolicyRetriesTransientNetworkErrors(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if requests := atomic.LoadInt64(&requests); requests != 2 {
+		t.Errorf("Expected the GET to be retried once, got %d requests", requests)
+	}
+
+	atomic.StoreInt64(&requests, 0)
+	if _, err := client.SendAzurePostRequest("services/storageservices", []byte("<x/>")); !IsTransientNetworkError(err) {
+		t.Errorf("Expected the POST to fail with a transient network error, got %v", err)
+	}
+	if requests := atomic.LoadInt64(&requests); requests != 1 {
+		t.Errorf("Expected the POST not to be retried, got %d requests", requests)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryPermanentTransportErrors(t *testing.T) {
+	var requests int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+	}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	if _, err := client.SendAzureGetRequest("locations"); err == nil || IsTransientNetworkError(err) {
+		t.Fatalf("Expected a certificate verification error, got %v", err)
+	}
+	if requests := atomic.LoadInt64(&requests); requests != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", requests)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransientNetworkError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{&url.Error{Op: "Get", URL: "https://management.core.windows.net", Err: io.ErrUnexpectedEOF}, true},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{&url.Error{Op: "Get", URL: "https://management.core.windows.net", Err: timeoutError{}}, true},
+		{&net.DNSError{Err: "no such host", Name: "management.example", IsNotFound: true}, false},
+		{errors.New("injected fault"), false},
+	}
+
+	for _, test := range tests {
+		if actual := IsTransientNetworkError(test.err); actual != test.expected {
+			t.Errorf("IsTransientNetworkError(%v): expected %v, got %v", test.err, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package management
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Names of the routes known to a RouteTable.
+const (
+	RouteOperationStatus            = "OperationStatus"
+	RouteStorageServiceList         = "StorageServiceList"
+	RouteStorageService             = "StorageService"
+	RouteStorageServiceAvailability = "StorageServiceAvailability"
+	RouteStorageServiceKeys         = "StorageServiceKeys"
+)
+
+// SubscriptionURL is the URL, relative to the subscription, of the
+// subscription itself.
+const SubscriptionURL = "/"
+
+// RouteTable maps route names to URL templates. Templates are relative to
+// the subscription URL and use fmt verbs for the names of the resources they
+// address.
+type RouteTable map[string]string
+
+var defaultRoutes = RouteTable{
+	RouteOperationStatus:            "operations/%s",
+	RouteStorageServiceList:         "services/storageservices",
+	RouteStorageService:             "services/storageservices/%s",
+	RouteStorageServiceAvailability: "services/storageservices/operations/isavailable/%s",
+	RouteStorageServiceKeys:         "services/storageservices/%s/keys",
+}
+
+// DefaultRoutes returns a copy of the routes of the public Azure Service
+// Management API.
+func DefaultRoutes() RouteTable {
+	routes := make(RouteTable, len(defaultRoutes))
+	for name, template := range defaultRoutes {
+		routes[name] = template
+	}
+	return routes
+}
+
+// Route returns the URL of the named route, formatted with args by
+// FormatRoute. Routes overridden in the
+// ClientConfig take precedence over DefaultRoutes. An empty string is
+// returned for unknown routes.
+func (client *Client) Route(name string, args ...interface{}) string {
+	template, ok := client.routes[name]
+	if !ok {
+		template = defaultRoutes[name]
+	}
+	if template == "" {
+		return ""
+	}
+
+	return FormatRoute(template, args...)
+}
+
+// FormatRoute formats the URL template of a route with args, escaping string
+// args as path segments, as NewURLBuilder does. Implementations of
+// ManagementClient use it for their Route method.
+func FormatRoute(template string, args ...interface{}) string {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		if name, ok := arg.(string); ok {
+			arg = url.PathEscape(name)
+		}
+		escaped[i] = arg
+	}
+
+	return fmt.Sprintf(template, escaped...)
+}
+
+// mergeRoutes returns DefaultRoutes with the given overrides applied.
+func mergeRoutes(overrides RouteTable) RouteTable {
+	routes := DefaultRoutes()
+	for name, template := range overrides {
+		routes[name] = template
+	}
+	return routes
+}
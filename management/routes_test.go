@@ -0,0 +1,94 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDefaultRoutes(t *testing.T) {
+	client := NewAnonymousClient()
+
+	tests := []struct {
+		route    string
+		args     []interface{}
+		expected string
+	}{
+		{RouteOperationStatus, []interface{}{"id"}, "operations/id"},
+		{RouteStorageServiceList, nil, "services/storageservices"},
+		{RouteStorageService, []interface{}{"account"}, "services/storageservices/account"},
+		{RouteStorageServiceAvailability, []interface{}{"account"}, "services/storageservices/operations/isavailable/account"},
+		{RouteStorageServiceKeys, []interface{}{"account"}, "services/storageservices/account/keys"},
+		{RouteStorageService, []interface{}{"a/b?c"}, "services/storageservices/a%2Fb%3Fc"},
+		{"UnknownRoute", nil, ""},
+	}
+
+	for _, test := range tests {
+		if actual := client.Route(test.route, test.args...); actual != test.expected {
+			t.Errorf("Wrong URL for route %s. Expected: '%s', got: '%s'", test.route, test.expected, actual)
+		}
+	}
+}
+
+func TestDefaultRoutesReturnsCopy(t *testing.T) {
+	routes := DefaultRoutes()
+	routes[RouteOperationStatus] = "changed/%s"
+
+	if actual := DefaultRoutes()[RouteOperationStatus]; actual != "operations/%s" {
+		t.Fatalf("DefaultRoutes was modified through a returned copy: '%s'", actual)
+	}
+}
+
+func TestOverriddenOperationStatusRoute(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body := "<Operation><ID>id</ID><Status>Succeeded</Status></Operation>"
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{
+		DefaultPollInterval: 1,
+		Routes:              RouteTable{RouteOperationStatus: "stack/operationstatus/%s"},
+	})
+
+	if err := client.WaitAsyncOperation("id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/subscriptionID/stack/operationstatus/id" {
+		t.Fatalf("Expected a single request to the overridden route, got %v", paths)
+	}
+	if actual := client.Route(RouteStorageServiceList); actual != "services/storageservices" {
+		t.Fatalf("Routes that are not overridden should keep their default, got '%s'", actual)
+	}
+}
+
+func TestSubscriptionURLAddressesSubscription(t *testing.T) {
+	var requestURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURIs = append(requestURIs, r.URL.RequestURI())
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	for _, url := range []string{SubscriptionURL, "locations", "/locations"} {
+		if _, err := client.SendAzureGetRequest(url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withQuery := client.WithQueryParameter("detail", "true")
+	if _, err := withQuery.SendAzureGetRequest(SubscriptionURL); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/subscriptionID,/subscriptionID/locations,/subscriptionID/locations,/subscriptionID?detail=true"
+	if actual := strings.Join(requestURIs, ","); actual != expected {
+		t.Errorf("Expected requests to %s, got %s", expected, actual)
+	}
+}
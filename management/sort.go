@@ -0,0 +1,44 @@
+package management
+
+import (
+	"strings"
+)
+
+// SortKey names a property that the entries of a list can be ordered by.
+type SortKey string
+
+// Sort keys understood by the list operations of the sub-clients.
+const (
+	SortByName     SortKey = "Name"
+	SortByLocation SortKey = "Location"
+)
+
+// Sortable is implemented by the entries of lists that list operations
+// return in a stable order. SortValue returns the value of the entry for
+// key, or an empty string if the entry has no such property.
+//
+// By convention, list operations always sort their entries by name after
+// decoding, since the order of Service Management API responses is not
+// guaranteed. Their options may add keys that are compared before the name.
+type Sortable interface {
+	SortValue(key SortKey) string
+}
+
+// Less reports whether a sorts before b. The keys are compared in order,
+// followed by the name. Values are compared case-insensitively, and only
+// names that are equal regardless of case are compared case-sensitively, so
+// that the order is total and "Abc" and "abc" never swap places.
+func Less(a, b Sortable, keys ...SortKey) bool {
+	for _, key := range keys {
+		x, y := strings.ToLower(a.SortValue(key)), strings.ToLower(b.SortValue(key))
+		if x != y {
+			return x < y
+		}
+	}
+
+	x, y := a.SortValue(SortByName), b.SortValue(SortByName)
+	if lowerX, lowerY := strings.ToLower(x), strings.ToLower(y); lowerX != lowerY {
+		return lowerX < lowerY
+	}
+	return x < y
+}
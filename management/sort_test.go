@@ -0,0 +1,62 @@
+package management
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type sortableEntry struct {
+	name, location string
+}
+
+func (e sortableEntry) SortValue(key SortKey) string {
+	switch key {
+	case SortByName:
+		return e.name
+	case SortByLocation:
+		return e.location
+	}
+	return ""
+}
+
+func sortedNames(entries []sortableEntry, keys ...SortKey) string {
+	sort.Slice(entries, func(i, j int) bool { return Less(entries[i], entries[j], keys...) })
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestLessIsStableUnderShuffling(t *testing.T) {
+	entries := []sortableEntry{
+		{"abc", "West US"},
+		{"Abc", "East US"},
+		{"b", "East US"},
+		{"ABD", "West US"},
+		{"a", "west us"},
+	}
+
+	tests := []struct {
+		keys     []SortKey
+		expected string
+	}{
+		{nil, "a,Abc,abc,ABD,b"},
+		{[]SortKey{SortByLocation}, "Abc,b,a,abc,ABD"},
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			shuffled := append([]sortableEntry(nil), entries...)
+			random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+			if actual := sortedNames(shuffled, test.keys...); actual != test.expected {
+				t.Fatalf("Sorting by %v: expected %s, got %s", test.keys, test.expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package storageservice
+
+import (
+	"context"
+	"sync"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+//CreateStorageServiceOperation is a handle on the creation of a storage
+//account started by CreateStorageServiceAsync, so that many accounts can be
+//created in parallel and joined on later:
+//
+//	var operations []*storageservice.CreateStorageServiceOperation
+//	for _, params := range accounts {
+//		operation, err := storageClient.CreateStorageServiceAsync(params)
+//		...
+//		operations = append(operations, operation)
+//	}
+//	for _, operation := range operations {
+//		storageService, err := operation.Wait(ctx)
+//		...
+//	}
+//
+//The creation is waited on in the background as soon as Done or Wait is first
+//called. A CreateStorageServiceOperation is safe for concurrent use.
+type CreateStorageServiceOperation struct {
+	// OperationID is the ID of the asynchronous operation creating the
+	// account.
+	OperationID string
+	ServiceName string
+
+	client  StorageServiceClient
+	options []management.WaitOption
+
+	start          sync.Once
+	done           chan struct{}
+	storageService *StorageService
+	err            error
+}
+
+//CreateStorageServiceAsync starts the creation of the storage account
+//described by params, as CreateStorageServiceWithParams does, and returns
+//without waiting for it to complete. The options control the wait of the
+//returned operation.
+func (self StorageServiceClient) CreateStorageServiceAsync(params CreateStorageServiceParams, options ...management.WaitOption) (*CreateStorageServiceOperation, error) {
+	if err := validateCreateStorageServiceParams(params); err != nil {
+		return nil, wrapError("CreateStorageServiceAsync", params.ServiceName, err)
+	}
+
+	requestId, err := self.startCreateStorageService(params)
+	if err != nil {
+		return nil, wrapError("CreateStorageServiceAsync", params.ServiceName, err)
+	}
+
+	return &CreateStorageServiceOperation{
+		OperationID: requestId,
+		ServiceName: params.ServiceName,
+		client:      self,
+		options:     options,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+//Done returns a channel that is closed when the creation completes, or its
+//wait is aborted.
+func (operation *CreateStorageServiceOperation) Done() <-chan struct{} {
+	operation.start.Do(func() {
+		go func() {
+			operation.storageService, operation.err = operation.client.finishCreateStorageService(operation.ServiceName, operation.OperationID, operation.options)
+			operation.err = wrapError("CreateStorageServiceAsync", operation.ServiceName, operation.err)
+			close(operation.done)
+		}()
+	})
+
+	return operation.done
+}
+
+//Wait blocks until the creation completes and returns the created storage
+//account. If ctx is done first, a *management.AbortedOperationError is
+//returned, and the creation keeps being waited on in the background.
+func (operation *CreateStorageServiceOperation) Wait(ctx context.Context) (*StorageService, error) {
+	select {
+	case <-operation.Done():
+		return operation.storageService, operation.err
+	case <-ctx.Done():
+		return nil, wrapError("CreateStorageServiceAsync", operation.ServiceName, &management.AbortedOperationError{
+			OperationID: operation.OperationID,
+			Resource:    operation.ServiceName,
+			Err:         ctx.Err(),
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package storageservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+func TestCreateStorageServiceAsyncCreatesInParallel(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	var operations []*CreateStorageServiceOperation
+	for _, name := range []string{"first", "second", "third"} {
+		operation, err := client.CreateStorageServiceAsync(CreateStorageServiceParams{ServiceName: name, Location: "West US"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		operations = append(operations, operation)
+	}
+	if count := server.countRequests("POST", "services/storageservices"); count != 3 {
+		t.Fatalf("Expected all creations to be started before waiting, got %d", count)
+	}
+
+	for _, operation := range operations {
+		storageService, err := operation.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if storageService.ServiceName != operation.ServiceName {
+			t.Errorf("Expected %s, got %s", operation.ServiceName, storageService.ServiceName)
+		}
+	}
+}
+
+func TestCreateStorageServiceOperationWaitHonorsContext(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.operationStatus = "InProgress"
+	client := newTestClient(t, server)
+
+	operation, err := client.CreateStorageServiceAsync(CreateStorageServiceParams{ServiceName: "account", Location: "West US"},
+		management.WithOperationTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err = operation.Wait(ctx)
+	var abortedErr *management.AbortedOperationError
+	if !errors.As(err, &abortedErr) || abortedErr.OperationID != operation.OperationID || abortedErr.Resource != "account" {
+		t.Fatalf("Expected an AbortedOperationError for %s, got %v", operation.OperationID, err)
+	}
+
+	<-operation.Done()
+}
@@ -0,0 +1,86 @@
+package storageservice
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/validation"
+)
+
+//AvailabilityReason tells why a storage account name is not available.
+type AvailabilityReason string
+
+const (
+	//AvailabilityReasonAlreadyExists means that the name is taken by an
+	//account of this or another subscription.
+	AvailabilityReasonAlreadyExists AvailabilityReason = "AlreadyExists"
+
+	//AvailabilityReasonInvalidName means that the name is not a valid
+	//storage account name, see validation.StorageAccountName.
+	AvailabilityReasonInvalidName AvailabilityReason = "InvalidName"
+
+	//AvailabilityReasonUnknown is reported for the reasons given by the
+	//service that are not recognized.
+	AvailabilityReasonUnknown AvailabilityReason = "Unknown"
+)
+
+//AvailabilityResult is the result of CheckAvailability. Reason and Message are
+//empty if the name is available.
+type AvailabilityResult struct {
+	Available bool
+	Reason    AvailabilityReason
+
+	// Message is the explanation given by the service, or by the
+	// validation of the name, with surrounding whitespace removed.
+	Message string
+}
+
+//CheckAvailability checks whether name can be used for a new storage account,
+//like IsAvailable, but tells apart names that are taken from names that are
+//invalid. Invalid names are reported without sending a request.
+func (self StorageServiceClient) CheckAvailability(name string) (*AvailabilityResult, error) {
+	if name == "" {
+		return nil, wrapError("CheckAvailability", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+
+	var validationErr *validation.Error
+	if err := validation.StorageAccountName(name); errors.As(err, &validationErr) {
+		return &AvailabilityResult{Reason: AvailabilityReasonInvalidName, Message: validationErr.Error()}, nil
+	}
+
+	requestURL := self.client.Route(management.RouteStorageServiceAvailability, name)
+	response, err := self.client.SendAzureGetRequest(requestURL)
+	if err != nil {
+		return nil, wrapError("CheckAvailability", name, err)
+	}
+
+	availabilityResponse := new(AvailabilityResponse)
+	err = xml.Unmarshal(response, availabilityResponse)
+	if err != nil {
+		return nil, wrapError("CheckAvailability", name, err)
+	}
+
+	if availabilityResponse.Result {
+		return &AvailabilityResult{Available: true}, nil
+	}
+
+	message := strings.TrimSpace(availabilityResponse.Reason)
+	return &AvailabilityResult{Reason: availabilityReason(message), Message: message}, nil
+}
+
+//availabilityReason classifies the reason given by the service for a name not
+//being available, such as "The storage account named x is already taken.".
+func availabilityReason(message string) AvailabilityReason {
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "already"):
+		return AvailabilityReasonAlreadyExists
+	case strings.Contains(message, "invalid") || strings.Contains(message, "not a valid") || strings.Contains(message, "not valid"):
+		return AvailabilityReasonInvalidName
+	}
+
+	return AvailabilityReasonUnknown
+}
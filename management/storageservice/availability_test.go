@@ -0,0 +1,44 @@
+package storageservice
+
+import (
+	"testing"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("GET", "services/storageservices/operations/isavailable/taken",
+		`<AvailabilityResponse><Result>false</Result><Reason> The storage account named taken is already taken. </Reason></AvailabilityResponse>`)
+	client.AddResponse("GET", "services/storageservices/operations/isavailable/free",
+		`<AvailabilityResponse><Result>true</Result></AvailabilityResponse>`)
+	storageClient := NewClientFromManagementClient(client)
+
+	tests := []struct {
+		name      string
+		available bool
+		reason    AvailabilityReason
+		message   string
+	}{
+		{"taken", false, AvailabilityReasonAlreadyExists, "The storage account named taken is already taken."},
+		{"free", true, "", ""},
+		{"Not_Valid", false, AvailabilityReasonInvalidName, ""},
+	}
+
+	for _, test := range tests {
+		result, err := storageClient.CheckAvailability(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Available != test.available || result.Reason != test.reason {
+			t.Errorf("%s: expected %v, %s, got %+v", test.name, test.available, test.reason, result)
+		}
+		if test.message != "" && result.Message != test.message {
+			t.Errorf("%s: expected message '%s', got '%s'", test.name, test.message, result.Message)
+		}
+	}
+
+	if requests := client.Requests(); len(requests) != 2 {
+		t.Errorf("Expected the invalid name to be rejected without a request, got %v", requests)
+	}
+}
@@ -1,42 +1,87 @@
 package storageservice
 
 import (
-	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/label"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/validation"
 )
 
 const (
-	azureStorageServiceListURL         = "services/storageservices"
-	azureStorageServiceURL             = "services/storageservices/%s"
-	azureStorageAccountAvailabilityURL = "services/storageservices/operations/isavailable/%s"
-
+	// apiVersion is the first version accepting the AccountType of storage
+	// accounts.
+	apiVersion = "2014-06-01"
 	azureXmlns = "http://schemas.microsoft.com/windowsazure"
 
-	errBlobEndpointNotFound = "Blob endpoint was not found in storage serice %s"
-	errParamNotSpecified    = "Parameter %s is not specified."
+	packageName = "storageservice"
+
+	errParamNotSpecified        = "Parameter %s is not specified."
+	errLocationAndAffinityGroup = "Parameters Location and AffinityGroup are mutually exclusive."
 )
 
 //NewClient is used to instantiate a new StorageServiceClient from an Azure client
 func NewClient(self management.Client) StorageServiceClient {
-	return StorageServiceClient{client: self}
+	return NewClientFromManagementClient(&self)
+}
+
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(self management.ManagementClient) StorageServiceClient {
+	return StorageServiceClient{client: management.RequireAPIVersion(self, apiVersion)}
 }
 
+//GetStorageServiceList returns the storage services of the subscription,
+//sorted by name. If some entries of the list cannot be decoded, the others are
+//still returned together with a *PartialListError. Use ListStorageServices to
+//avoid holding all of them in memory.
 func (self StorageServiceClient) GetStorageServiceList() (*StorageServiceList, error) {
-	storageServiceList := new(StorageServiceList)
+	return self.GetStorageServiceListWithOptions(ListOptions{})
+}
 
-	response, err := self.client.SendAzureGetRequest(azureStorageServiceListURL)
-	if err != nil {
-		return nil, err
+//GetStorageServiceListWithOptions is GetStorageServiceList with control over
+//how the list is decoded.
+func (self StorageServiceClient) GetStorageServiceListWithOptions(options ListOptions) (*StorageServiceList, error) {
+	if options.Strict {
+		storageServiceList := new(StorageServiceList)
+		pager := management.NewPager(self.client, self.client.Route(management.RouteStorageServiceList))
+		for pager.Next() {
+			page := new(StorageServiceList)
+			err := xml.Unmarshal(pager.Page(), page)
+			if err != nil {
+				return nil, wrapError("GetStorageServiceList", "", err)
+			}
+			storageServiceList.XMLName, storageServiceList.Xmlns = page.XMLName, page.Xmlns
+			storageServiceList.StorageServices = append(storageServiceList.StorageServices, page.StorageServices...)
+		}
+		if err := pager.Err(); err != nil {
+			return nil, wrapError("GetStorageServiceList", "", err)
+		}
+		sortStorageServices(storageServiceList.StorageServices, options.SortBy)
+		return storageServiceList, nil
+	}
+
+	storageServiceList := new(StorageServiceList)
+	storageServices := self.ListStorageServices()
+	for storageServices.Next() {
+		storageServiceList.StorageServices = append(storageServiceList.StorageServices, storageServices.Value())
+	}
+	if storageServices.list != nil {
+		storageServiceList.XMLName, storageServiceList.Xmlns = storageServices.list.XMLName, storageServices.list.Xmlns
 	}
+	sortStorageServices(storageServiceList.StorageServices, options.SortBy)
 
-	err = xml.Unmarshal(response, storageServiceList)
+	err := storageServices.Err()
+	var partialErr *PartialListError
+	if errors.As(err, &partialErr) {
+		partialErr.StorageServices = storageServiceList.StorageServices
+		return storageServiceList, wrapError("GetStorageServiceList", "", partialErr)
+	}
 	if err != nil {
-		return storageServiceList, err
+		return nil, wrapError("GetStorageServiceList", "", err)
 	}
 
 	return storageServiceList, nil
@@ -44,97 +89,340 @@ func (self StorageServiceClient) GetStorageServiceList() (*StorageServiceList, e
 
 func (self StorageServiceClient) GetStorageServiceByName(serviceName string) (*StorageService, error) {
 	if serviceName == "" {
-		return nil, fmt.Errorf(errParamNotSpecified, "serviceName")
+		return nil, wrapError("GetStorageServiceByName", serviceName, fmt.Errorf(errParamNotSpecified, "serviceName"))
 	}
 
 	storageService := new(StorageService)
-	requestURL := fmt.Sprintf(azureStorageServiceURL, serviceName)
-	response, err := self.client.SendAzureGetRequest(requestURL)
+	requestURL := self.client.Route(management.RouteStorageService, serviceName)
+	response, err := self.client.SendAzureRequest(requestURL, "GET", "", nil)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("GetStorageServiceByName", serviceName, err)
 	}
 
-	err = xml.Unmarshal(response, storageService)
+	err = xml.Unmarshal(response.Body, storageService)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("GetStorageServiceByName", serviceName, err)
 	}
+	storageService.ETag = response.ETag()
 
 	return storageService, nil
 }
 
+//GetStorageServiceByLocation returns the storage service of the subscription
+//in location that comes first by name. If there is none, the error is an
+//*ErrNoStorageServiceInLocation, for which management.IsNotFound holds.
 func (self StorageServiceClient) GetStorageServiceByLocation(location string) (*StorageService, error) {
 	if location == "" {
-		return nil, fmt.Errorf(errParamNotSpecified, "location")
+		return nil, wrapError("GetStorageServiceByLocation", location, fmt.Errorf(errParamNotSpecified, "location"))
 	}
 
-	storageService := new(StorageService)
-	storageServiceList, err := self.GetStorageServiceList()
+	storageServices, err := self.storageServicesByLocation(location)
+	if len(storageServices) != 0 {
+		return &storageServices[0], nil
+	}
 	if err != nil {
-		return storageService, err
+		return nil, wrapError("GetStorageServiceByLocation", location, err)
 	}
 
+	return nil, wrapError("GetStorageServiceByLocation", location, &ErrNoStorageServiceInLocation{Location: location})
+}
+
+//GetStorageServicesByLocation returns the storage services of the subscription
+//in location, sorted by name, or an empty slice if there are none. If some
+//entries of the list cannot be decoded, the matching ones among the others are
+//still returned together with a *PartialListError.
+func (self StorageServiceClient) GetStorageServicesByLocation(location string) ([]StorageService, error) {
+	if location == "" {
+		return nil, wrapError("GetStorageServicesByLocation", location, fmt.Errorf(errParamNotSpecified, "location"))
+	}
+
+	storageServices, err := self.storageServicesByLocation(location)
+	return storageServices, wrapError("GetStorageServicesByLocation", location, err)
+}
+
+func (self StorageServiceClient) storageServicesByLocation(location string) ([]StorageService, error) {
+	storageServiceList, err := self.GetStorageServiceList()
+	var partialErr *PartialListError
+	if err != nil && !errors.As(err, &partialErr) {
+		return nil, err
+	}
+
+	storageServices := []StorageService{}
 	for _, storageService := range storageServiceList.StorageServices {
-		if storageService.StorageServiceProperties.Location != location {
-			continue
+		if strings.EqualFold(storageService.StorageServiceProperties.Location, location) {
+			storageServices = append(storageServices, storageService)
 		}
-
-		return &storageService, nil
 	}
 
-	return nil, nil
+	return storageServices, err
 }
 
-func (self StorageServiceClient) CreateStorageService(name, location string) (*StorageService, error) {
+//CreateStorageService creates a storage account of the default account type
+//and waits for it to be provisioned. The options control the wait for the
+//creation to complete.
+func (self StorageServiceClient) CreateStorageService(name, location string, options ...management.WaitOption) (*StorageService, error) {
 	if name == "" {
-		return nil, fmt.Errorf(errParamNotSpecified, "name")
+		return nil, wrapError("CreateStorageService", name, fmt.Errorf(errParamNotSpecified, "name"))
 	}
 	if location == "" {
-		return nil, fmt.Errorf(errParamNotSpecified, "location")
+		return nil, wrapError("CreateStorageService", name, fmt.Errorf(errParamNotSpecified, "location"))
 	}
 
-	storageDeploymentConfig := self.createStorageServiceDeploymentConf(name, location)
-	deploymentBytes, err := xml.Marshal(storageDeploymentConfig)
+	return self.CreateStorageServiceWithParams(CreateStorageServiceParams{ServiceName: name, Location: location}, options...)
+}
+
+//CreateStorageServiceWithParams creates the storage account described by
+//params, for example of a given AccountType or in an affinity group, and waits
+//for it to be provisioned. The options control the wait for the creation to
+//complete.
+func (self StorageServiceClient) CreateStorageServiceWithParams(params CreateStorageServiceParams, options ...management.WaitOption) (*StorageService, error) {
+	if err := validateCreateStorageServiceParams(params); err != nil {
+		return nil, wrapError("CreateStorageService", params.ServiceName, err)
+	}
+
+	storageService, err := self.createStorageService(params, options)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("CreateStorageService", params.ServiceName, err)
+	}
+
+	return storageService, nil
+}
+
+//EnsureStorageService makes sure a storage account matching params exists. If
+//the account does not exist yet it is created, and the returned bool is true.
+//If it already exists with a different location, affinity group or account
+//type, the existing account is returned together with an
+//*ErrExistsWithDifferentProperties error. The options control the wait for a
+//creation to complete.
+func (self StorageServiceClient) EnsureStorageService(params CreateStorageServiceParams, options ...management.WaitOption) (*StorageService, bool, error) {
+	if err := validateCreateStorageServiceParams(params); err != nil {
+		return nil, false, wrapError("EnsureStorageService", params.ServiceName, err)
+	}
+
+	storageService, err := self.GetStorageServiceByName(params.ServiceName)
+	if err == nil {
+		return storageService, false, wrapError("EnsureStorageService", params.ServiceName, verifyStorageServiceProperties(storageService, params))
+	}
+	if !management.IsNotFound(err) {
+		return nil, false, wrapError("EnsureStorageService", params.ServiceName, err)
+	}
+
+	storageService, createErr := self.createStorageService(params, options)
+	if createErr == nil {
+		return storageService, true, nil
+	}
+	if !management.IsConflict(createErr) {
+		return nil, false, wrapError("EnsureStorageService", params.ServiceName, createErr)
 	}
 
-	requestId, err := self.client.SendAzurePostRequest(azureStorageServiceListURL, deploymentBytes)
+	// Somebody else created the account between our GET and our POST, so
+	// reconcile against what they created. If the account still cannot be
+	// found, the name is taken by another subscription.
+	storageService, err = self.GetStorageServiceByName(params.ServiceName)
 	if err != nil {
-		return nil, err
+		if management.IsNotFound(err) {
+			return nil, false, wrapError("EnsureStorageService", params.ServiceName, createErr)
+		}
+		return nil, false, wrapError("EnsureStorageService", params.ServiceName, err)
+	}
+
+	return storageService, false, wrapError("EnsureStorageService", params.ServiceName, verifyStorageServiceProperties(storageService, params))
+}
+
+//DeleteStorageService deletes a storage account, together with the data it
+//holds, and waits for the deletion to complete. The options control the wait.
+//See https://msdn.microsoft.com/en-us/library/azure/hh264517.aspx
+func (self StorageServiceClient) DeleteStorageService(name string, options ...management.WaitOption) error {
+	if name == "" {
+		return wrapError("DeleteStorageService", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+
+	requestURL := self.client.Route(management.RouteStorageService, name)
+	err := self.client.SendAzureDeleteRequestAndWait(requestURL, options...)
+	if err != nil {
+		var abortedErr *management.AbortedOperationError
+		if errors.As(err, &abortedErr) {
+			abortedErr.Resource = name
+		}
+		return wrapError("DeleteStorageService", name, err)
 	}
 
-	err = self.client.WaitAsyncOperation(requestId)
+	return nil
+}
+
+//UpdateStorageService changes the label, description, geo-replication setting
+//and extended properties of a storage account, including accounts created
+//outside of this package, and waits for the update to complete. The options
+//control the wait.
+//See https://msdn.microsoft.com/en-us/library/azure/hh264516.aspx
+func (self StorageServiceClient) UpdateStorageService(name string, params UpdateStorageServiceParams, options ...management.WaitOption) error {
+	if name == "" {
+		return wrapError("UpdateStorageService", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+	if err := validation.Label(params.Label); err != nil {
+		return wrapError("UpdateStorageService", name, err)
+	}
+
+	updateInput := UpdateStorageServiceInput{
+		Xmlns:                 azureXmlns,
+		Description:           params.Description,
+		Label:                 label.Label(params.Label),
+		GeoReplicationEnabled: params.GeoReplicationEnabled,
+	}
+	if len(params.ExtendedProperties) != 0 {
+		updateInput.ExtendedProperties = &ExtendedPropertyList{ExtendedProperty: params.ExtendedProperties}
+	}
+
+	err := self.updateStorageService(name, updateInput, params.ETag, options)
+	if err != nil {
+		return wrapError("UpdateStorageService", name, err)
+	}
+
+	return nil
+}
+
+//SetCustomDomain maps the custom domain, such as assets.contoso.com, to the
+//blob endpoint of a storage account, replacing the custom domain the account
+//had. The CNAME record of domain must point at the blob endpoint, or, if
+//useSubdomainVerification is set, the one of asverify.domain must point at
+//asverify.<account>.blob.core.windows.net, which allows the domain to keep
+//serving while it is moved. An empty domain removes the custom domain. The
+//options control the wait for the update to complete.
+func (self StorageServiceClient) SetCustomDomain(serviceName, domain string, useSubdomainVerification bool, options ...management.WaitOption) error {
+	if serviceName == "" {
+		return wrapError("SetCustomDomain", serviceName, fmt.Errorf(errParamNotSpecified, "serviceName"))
+	}
+
+	updateInput := UpdateStorageServiceInput{
+		Xmlns:         azureXmlns,
+		CustomDomains: []CustomDomain{{Name: domain, UseSubDomainName: useSubdomainVerification}},
+	}
+	err := self.updateStorageService(serviceName, updateInput, "", options)
+	if err != nil {
+		return wrapError("SetCustomDomain", serviceName, err)
+	}
+
+	return nil
+}
+
+func (self StorageServiceClient) updateStorageService(name string, updateInput UpdateStorageServiceInput, etag string, options []management.WaitOption) error {
+	updateBytes, err := xml.Marshal(updateInput)
+	if err != nil {
+		return err
+	}
+
+	client := management.IfMatch(self.client, etag)
+	requestURL := client.Route(management.RouteStorageService, name)
+	err = client.SendAzurePutRequestAndWait(requestURL, "application/xml", updateBytes, options...)
+	var abortedErr *management.AbortedOperationError
+	if errors.As(err, &abortedErr) {
+		abortedErr.Resource = name
+	}
+
+	return err
+}
+
+func validateCreateStorageServiceParams(params CreateStorageServiceParams) error {
+	if params.ServiceName == "" {
+		return fmt.Errorf(errParamNotSpecified, "ServiceName")
+	}
+	if params.Location == "" && params.AffinityGroup == "" {
+		return fmt.Errorf(errParamNotSpecified, "Location")
+	}
+	if params.Location != "" && params.AffinityGroup != "" {
+		return errors.New(errLocationAndAffinityGroup)
+	}
+
+	return validation.StorageAccountName(params.ServiceName)
+}
+
+func (self StorageServiceClient) createStorageService(params CreateStorageServiceParams, options []management.WaitOption) (*StorageService, error) {
+	requestId, err := self.startCreateStorageService(params)
 	if err != nil {
 		return nil, err
 	}
 
-	storageService, err := self.GetStorageServiceByName(storageDeploymentConfig.ServiceName)
+	return self.finishCreateStorageService(params.ServiceName, requestId, options)
+}
+
+//startCreateStorageService sends the request creating a storage account and
+//returns the ID of the operation it started.
+func (self StorageServiceClient) startCreateStorageService(params CreateStorageServiceParams) (string, error) {
+	storageDeploymentConfig := self.createStorageServiceDeploymentConf(params)
+	deploymentBytes, err := xml.Marshal(storageDeploymentConfig)
 	if err != nil {
+		return "", err
+	}
+
+	requestURL := self.client.Route(management.RouteStorageServiceList)
+	return self.client.SendAzurePostRequest(requestURL, deploymentBytes)
+}
+
+//finishCreateStorageService waits for the creation of a storage account and
+//returns the account.
+func (self StorageServiceClient) finishCreateStorageService(name, requestId string, options []management.WaitOption) (*StorageService, error) {
+	err := self.client.WaitAsyncOperation(requestId, options...)
+	if err != nil {
+		var abortedErr *management.AbortedOperationError
+		if errors.As(err, &abortedErr) {
+			abortedErr.Resource = name
+		}
 		return nil, err
 	}
 
-	return storageService, nil
+	return self.GetStorageServiceByName(name)
 }
 
+func verifyStorageServiceProperties(storageService *StorageService, params CreateStorageServiceParams) error {
+	var mismatches []PropertyMismatch
+
+	properties := storageService.StorageServiceProperties
+	if params.Location != "" && !strings.EqualFold(properties.Location, params.Location) {
+		mismatches = append(mismatches, PropertyMismatch{Property: "Location", Expected: params.Location, Actual: properties.Location})
+	}
+	if params.AffinityGroup != "" && !strings.EqualFold(properties.AffinityGroup, params.AffinityGroup) {
+		mismatches = append(mismatches, PropertyMismatch{Property: "AffinityGroup", Expected: params.AffinityGroup, Actual: properties.AffinityGroup})
+	}
+	if params.AccountType != "" && !strings.EqualFold(properties.AccountType, params.AccountType) {
+		mismatches = append(mismatches, PropertyMismatch{Property: "AccountType", Expected: params.AccountType, Actual: properties.AccountType})
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	return &ErrExistsWithDifferentProperties{ServiceName: storageService.ServiceName, Mismatches: mismatches}
+}
+
+//GetBlobEndpoint returns the blob service endpoint of storageService. While the
+//storage service does not list its endpoints yet, the endpoint is derived from
+//the environment of the client.
 func (self StorageServiceClient) GetBlobEndpoint(storageService *StorageService) (string, error) {
-	for _, endpoint := range storageService.StorageServiceProperties.Endpoints {
-		if !strings.Contains(endpoint, ".blob.core") {
-			continue
-		}
+	if storageService == nil {
+		return "", wrapError("GetBlobEndpoint", "", fmt.Errorf(errParamNotSpecified, "storageService"))
+	}
+	if len(storageService.StorageServiceProperties.Endpoints) == 0 && storageService.ServiceName != "" {
+		return self.client.Environment().BlobEndpoint(storageService.ServiceName), nil
+	}
 
-		return endpoint, nil
+	endpoint, err := serviceEndpoint(storageService, blobServiceLabel)
+	if err != nil {
+		return "", wrapError("GetBlobEndpoint", storageService.ServiceName, err)
 	}
 
-	return "", errors.New(fmt.Sprintf(errBlobEndpointNotFound, storageService.ServiceName))
+	return endpoint, nil
 }
 
-func (self *StorageServiceClient) createStorageServiceDeploymentConf(name, location string) StorageServiceDeployment {
+func (self StorageServiceClient) createStorageServiceDeploymentConf(params CreateStorageServiceParams) StorageServiceDeployment {
 	storageServiceDeployment := StorageServiceDeployment{}
 
-	storageServiceDeployment.ServiceName = name
-	label := base64.StdEncoding.EncodeToString([]byte(name))
-	storageServiceDeployment.Label = label
-	storageServiceDeployment.Location = location
+	storageServiceDeployment.ServiceName = params.ServiceName
+	storageServiceDeployment.Label = label.DefaultFor(params.ServiceName)
+	storageServiceDeployment.Description = params.Description
+	storageServiceDeployment.Location = params.Location
+	storageServiceDeployment.AffinityGroup = params.AffinityGroup
+	storageServiceDeployment.AccountType = params.AccountType
+	storageServiceDeployment.ExtendedProperties.ExtendedProperty = params.ExtendedProperties
 	storageServiceDeployment.Xmlns = azureXmlns
 
 	return storageServiceDeployment
@@ -142,22 +430,28 @@ func (self *StorageServiceClient) createStorageServiceDeploymentConf(name, locat
 
 // The Check Storage Account Name Availability operation checks to see if the specified storage account name is available, or if it has already been taken.
 // See https://msdn.microsoft.com/en-us/library/azure/jj154125.aspx
+// Use CheckAvailability to tell why a name is not available.
 func (self StorageServiceClient) IsAvailable(name string) (bool, string, error) {
 	if name == "" {
-		return false, "", fmt.Errorf(errParamNotSpecified, "name")
+		return false, "", wrapError("IsAvailable", name, fmt.Errorf(errParamNotSpecified, "name"))
 	}
 
-	requestURL := fmt.Sprintf(azureStorageAccountAvailabilityURL, name)
+	requestURL := self.client.Route(management.RouteStorageServiceAvailability, name)
 	response, err := self.client.SendAzureGetRequest(requestURL)
 	if err != nil {
-		return false, "", err
+		return false, "", wrapError("IsAvailable", name, err)
 	}
 
 	availabilityResponse := new(AvailabilityResponse)
 	err = xml.Unmarshal(response, availabilityResponse)
 	if err != nil {
-		return false, "", err
+		return false, "", wrapError("IsAvailable", name, err)
 	}
 
 	return availabilityResponse.Result, availabilityResponse.Reason, nil
 }
+
+//wrapError annotates err with the storage service operation that failed.
+func wrapError(operation, resource string, err error) error {
+	return management.WrapError(packageName, operation, resource, err)
+}
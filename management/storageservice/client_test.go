@@ -0,0 +1,763 @@
+package storageservice
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/validation"
+)
+
+const testSubscriptionID = "subscriptionID"
+
+// fakeServer is an in-memory implementation of the storage service
+// management API, good enough to drive StorageServiceClient in tests.
+type fakeServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	services   map[string]StorageService
+	operations int
+	requests   []string
+
+	// rawList, when set, is served as the storage service list instead
+	// of services.
+	rawList string
+
+	// operationStatus is reported for every asynchronous operation;
+	// empty means Succeeded.
+	operationStatus string
+
+	// racing holds services that another client creates just before
+	// ours, so that our POST for them fails with a conflict.
+	racing map[string]StorageService
+}
+
+func newFakeServer() *fakeServer {
+	f := &fakeServer{
+		services: make(map[string]StorageService),
+		racing:   make(map[string]StorageService),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeServer) addService(service StorageService) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.services[service.ServiceName] = service
+}
+
+func (f *fakeServer) countRequests(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, request := range f.requests {
+		if request == method+" /"+testSubscriptionID+"/"+path {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	path := strings.TrimPrefix(r.URL.Path, "/"+testSubscriptionID+"/")
+	switch {
+	case r.Method == "GET" && path == "services/storageservices" && f.rawList != "":
+		writeRaw(w, http.StatusOK, f.rawList)
+	case r.Method == "GET" && path == "services/storageservices":
+		list := StorageServiceList{Xmlns: azureXmlns}
+		for _, service := range f.services {
+			list.StorageServices = append(list.StorageServices, service)
+		}
+		writeXML(w, http.StatusOK, list)
+	case r.Method == "POST" && path == "services/storageservices":
+		body, _ := ioutil.ReadAll(r.Body)
+		deployment := StorageServiceDeployment{}
+		if err := xml.Unmarshal(body, &deployment); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		if service, ok := f.racing[deployment.ServiceName]; ok {
+			f.services[deployment.ServiceName] = service
+		}
+		if _, ok := f.services[deployment.ServiceName]; ok {
+			writeError(w, http.StatusConflict, "ConflictError", "The specified DNS name is already taken.")
+			return
+		}
+		f.services[deployment.ServiceName] = StorageService{
+			Url:         f.URL + "/" + testSubscriptionID + "/services/storageservices/" + deployment.ServiceName,
+			ServiceName: deployment.ServiceName,
+			StorageServiceProperties: StorageServiceProperties{
+				Location:      deployment.Location,
+				AffinityGroup: deployment.AffinityGroup,
+				Label:         deployment.Label,
+				Status:        "Created",
+				Endpoints:     []string{fmt.Sprintf("https://%s.blob.core.windows.net/", deployment.ServiceName)},
+				AccountType:   deployment.AccountType,
+			},
+		}
+		f.operations++
+		w.Header().Set("x-ms-request-id", fmt.Sprintf("operation-%d", f.operations))
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == "GET" && strings.HasPrefix(path, "operations/"):
+		status := f.operationStatus
+		if status == "" {
+			status = "Succeeded"
+		}
+		writeRaw(w, http.StatusOK, fmt.Sprintf(
+			`<Operation xmlns="%s"><ID>%s</ID><Status>%s</Status><HttpStatusCode>200</HttpStatusCode></Operation>`,
+			azureXmlns, strings.TrimPrefix(path, "operations/"), status))
+	case r.Method == "GET" && strings.HasPrefix(path, "services/storageservices/operations/isavailable/"):
+		_, taken := f.services[strings.TrimPrefix(path, "services/storageservices/operations/isavailable/")]
+		writeXML(w, http.StatusOK, AvailabilityResponse{Xmlns: azureXmlns, Result: !taken})
+	case r.Method == "DELETE" && strings.HasPrefix(path, "services/storageservices/"):
+		name := strings.TrimPrefix(path, "services/storageservices/")
+		if _, ok := f.services[name]; !ok {
+			writeError(w, http.StatusNotFound, "ResourceNotFound", "The storage account was not found.")
+			return
+		}
+		delete(f.services, name)
+		f.operations++
+		w.Header().Set("x-ms-request-id", fmt.Sprintf("operation-%d", f.operations))
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == "PUT" && strings.HasPrefix(path, "services/storageservices/"):
+		name := strings.TrimPrefix(path, "services/storageservices/")
+		service, ok := f.services[name]
+		if !ok {
+			writeError(w, http.StatusNotFound, "ResourceNotFound", "The storage account was not found.")
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		update := UpdateStorageServiceInput{}
+		if err := xml.Unmarshal(body, &update); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		if update.Label != "" {
+			service.StorageServiceProperties.Label = update.Label
+		}
+		if update.Description != "" {
+			service.StorageServiceProperties.Description = update.Description
+		}
+		if update.ExtendedProperties != nil {
+			if service.ExtendedProperties == nil {
+				service.ExtendedProperties = ExtendedProperties{}
+			}
+			for _, property := range update.ExtendedProperties.ExtendedProperty {
+				if property.Value == "" {
+					delete(service.ExtendedProperties, property.Name)
+				} else {
+					service.ExtendedProperties[property.Name] = property.Value
+				}
+			}
+		}
+		if update.CustomDomains != nil {
+			service.StorageServiceProperties.CustomDomains = nil
+			for _, domain := range update.CustomDomains {
+				if domain.Name != "" {
+					service.StorageServiceProperties.CustomDomains = append(service.StorageServiceProperties.CustomDomains, CustomDomain{Name: domain.Name})
+				}
+			}
+		}
+		if update.GeoReplicationEnabled != nil {
+			service.StorageServiceProperties.GeoReplicationEnabled = fmt.Sprint(*update.GeoReplicationEnabled)
+		}
+		f.services[name] = service
+		f.operations++
+		w.Header().Set("x-ms-request-id", fmt.Sprintf("operation-%d", f.operations))
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == "GET" && strings.HasPrefix(path, "services/storageservices/"):
+		service, ok := f.services[strings.TrimPrefix(path, "services/storageservices/")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "ResourceNotFound", "The storage account was not found.")
+			return
+		}
+		writeXML(w, http.StatusOK, service)
+	default:
+		writeError(w, http.StatusBadRequest, "BadRequest", "Unexpected request "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func writeXML(w http.ResponseWriter, statusCode int, v interface{}) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	writeRaw(w, statusCode, string(body))
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeRaw(w, statusCode, fmt.Sprintf(`<Error xmlns="%s"><Code>%s</Code><Message>%s</Message></Error>`, azureXmlns, code, message))
+}
+
+func writeRaw(w http.ResponseWriter, statusCode int, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(statusCode)
+	w.Write([]byte(body))
+}
+
+func newTestClient(t *testing.T, server *fakeServer) StorageServiceClient {
+	client, err := management.NewClientFromConfig(testSubscriptionID, []byte("cert"), management.ClientConfig{
+		ManagementURL:       server.URL,
+		DefaultPollInterval: 10 * time.Millisecond,
+		HTTPClient:          &http.Client{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(client)
+}
+
+func TestStorageServiceClientIsSafeForConcurrentUse(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "existing"})
+
+	client := newTestClient(t, server)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetStorageServiceList(); err != nil {
+				errs <- err
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("account%d", i)
+			service, err := client.CreateStorageService(name, "West US")
+			if err != nil {
+				errs <- err
+				return
+			}
+			if service.ServiceName != name {
+				errs <- fmt.Errorf("Expected service %s, got %s", name, service.ServiceName)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	list, err := client.GetStorageServiceList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := workers + 1; len(list.StorageServices) != expected {
+		t.Fatalf("Expected %d storage services, got %d", expected, len(list.StorageServices))
+	}
+}
+
+func TestEnsureStorageServiceCreatesAbsentAccount(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	service, created, err := client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "West US", AccountType: "Standard_GRS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Fatal("Expected the storage service to be created")
+	}
+	if service.ServiceName != "account" || service.StorageServiceProperties.AccountType != "Standard_GRS" {
+		t.Fatalf("Unexpected storage service: %+v", service)
+	}
+	if service.StorageServiceProperties.Label != "account" {
+		t.Fatalf("Expected the storage service to be labelled with its name, got '%s'", service.StorageServiceProperties.Label)
+	}
+}
+
+func TestEnsureStorageServiceAcceptsMatchingAccount(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{
+		ServiceName:              "account",
+		StorageServiceProperties: StorageServiceProperties{Location: "West US", AccountType: "Standard_GRS"},
+	})
+
+	client := newTestClient(t, server)
+	service, created, err := client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "west us", AccountType: "Standard_GRS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("Expected the existing storage service to be reused")
+	}
+	if service.ServiceName != "account" {
+		t.Fatalf("Unexpected storage service: %+v", service)
+	}
+	if count := server.countRequests("POST", "services/storageservices"); count != 0 {
+		t.Fatalf("Expected no create requests, got %d", count)
+	}
+}
+
+func TestEnsureStorageServiceReportsMismatchedAccount(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{
+		ServiceName:              "account",
+		StorageServiceProperties: StorageServiceProperties{Location: "East US", AccountType: "Standard_LRS"},
+	})
+
+	client := newTestClient(t, server)
+	service, created, err := client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "West US", AccountType: "Standard_GRS"})
+	if created {
+		t.Fatal("Expected the existing storage service not to be recreated")
+	}
+	if service == nil || service.ServiceName != "account" {
+		t.Fatalf("Expected the existing storage service to be returned, got %+v", service)
+	}
+
+	var mismatchErr *ErrExistsWithDifferentProperties
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("Expected ErrExistsWithDifferentProperties, got %v", err)
+	}
+	expected := []PropertyMismatch{
+		{Property: "Location", Expected: "West US", Actual: "East US"},
+		{Property: "AccountType", Expected: "Standard_GRS", Actual: "Standard_LRS"},
+	}
+	if len(mismatchErr.Mismatches) != len(expected) {
+		t.Fatalf("Expected mismatches %+v, got %+v", expected, mismatchErr.Mismatches)
+	}
+	for i := range expected {
+		if mismatchErr.Mismatches[i] != expected[i] {
+			t.Fatalf("Expected mismatches %+v, got %+v", expected, mismatchErr.Mismatches)
+		}
+	}
+}
+
+func TestEnsureStorageServiceInAffinityGroup(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{
+		ServiceName:              "other",
+		StorageServiceProperties: StorageServiceProperties{AffinityGroup: "othergroup", Location: "West US"},
+	})
+
+	client := newTestClient(t, server)
+	service, created, err := client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", AffinityGroup: "mygroup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || service.StorageServiceProperties.AffinityGroup != "mygroup" {
+		t.Fatalf("Expected the storage service to be created in the affinity group, got %+v", service)
+	}
+
+	if _, created, err = client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", AffinityGroup: "MyGroup"}); err != nil || created {
+		t.Fatalf("Expected the existing storage service to be reused, got created %v, error %v", created, err)
+	}
+
+	_, _, err = client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "other", AffinityGroup: "mygroup"})
+	var mismatchErr *ErrExistsWithDifferentProperties
+	if !errors.As(err, &mismatchErr) || len(mismatchErr.Mismatches) != 1 || mismatchErr.Mismatches[0] != (PropertyMismatch{Property: "AffinityGroup", Expected: "mygroup", Actual: "othergroup"}) {
+		t.Fatalf("Expected an AffinityGroup mismatch, got %v", err)
+	}
+
+	_, _, err = client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "West US", AffinityGroup: "mygroup"})
+	if err == nil || !strings.Contains(err.Error(), errLocationAndAffinityGroup) {
+		t.Fatalf("Expected Location and AffinityGroup to be rejected together, got %v", err)
+	}
+}
+
+func TestEnsureStorageServiceReconcilesCreateRace(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.racing["account"] = StorageService{
+		ServiceName:              "account",
+		StorageServiceProperties: StorageServiceProperties{Location: "West US"},
+	}
+
+	client := newTestClient(t, server)
+	service, created, err := client.EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "West US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("Expected the storage service created by the other client to be reused")
+	}
+	if service.ServiceName != "account" {
+		t.Fatalf("Unexpected storage service: %+v", service)
+	}
+}
+
+func TestCreateStorageServiceWrapsErrors(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "myaccount"})
+
+	client := newTestClient(t, server)
+	_, err := client.CreateStorageService("myaccount", "West US")
+	if err == nil {
+		t.Fatal("Expected a conflict error")
+	}
+
+	if prefix := "storageservice.CreateStorageService(myaccount): "; !strings.HasPrefix(err.Error(), prefix) {
+		t.Fatalf("Expected error to start with '%s', got '%s'", prefix, err.Error())
+	}
+	if !management.IsConflict(err) {
+		t.Fatalf("Expected a conflict error, got %v", err)
+	}
+
+	var operationErr *management.OperationError
+	if !errors.As(err, &operationErr) {
+		t.Fatalf("Expected an OperationError, got %T", err)
+	}
+	if operationErr.Service != "storageservice" || operationErr.Operation != "CreateStorageService" || operationErr.Resource != "myaccount" {
+		t.Fatalf("Unexpected operation error: %+v", operationErr)
+	}
+}
+
+func TestCreateStorageServiceValidatesNameBeforeSending(t *testing.T) {
+	client := fake.NewClient()
+
+	_, err := NewClientFromManagementClient(client).CreateStorageService("My_Account", "West US")
+	var validationErr *validation.Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a *validation.Error, got %v", err)
+	}
+	if requests := client.Requests(); len(requests) != 0 {
+		t.Fatalf("Expected no request to be sent, got %v", requests)
+	}
+}
+
+func TestCreateStorageServiceWithParamsSetsAccountType(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	service, err := client.CreateStorageServiceWithParams(CreateStorageServiceParams{
+		ServiceName: "account",
+		Location:    "West US",
+		AccountType: AccountTypePremiumLRS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.StorageServiceProperties.AccountType != AccountTypePremiumLRS {
+		t.Fatalf("Expected account type %s, got '%s'", AccountTypePremiumLRS, service.StorageServiceProperties.AccountType)
+	}
+}
+
+func TestCreateStorageServiceWithParamsInAffinityGroup(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("POST", "services/storageservices", "operation-1")
+	client.AddResponse("GET", "services/storageservices/account", `<StorageService><ServiceName>account</ServiceName></StorageService>`)
+
+	_, err := NewClientFromManagementClient(client).CreateStorageServiceWithParams(CreateStorageServiceParams{
+		ServiceName:        "account",
+		AffinityGroup:      "mygroup",
+		Description:        "logs",
+		ExtendedProperties: []ExtendedProperty{{Name: "owner", Value: "ops"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployment := StorageServiceDeployment{}
+	if err := xml.Unmarshal(client.Requests()[0].Body, &deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.AffinityGroup != "mygroup" || deployment.Location != "" || deployment.Description != "logs" {
+		t.Errorf("Unexpected deployment: %+v", deployment)
+	}
+	if properties := deployment.ExtendedProperties.ExtendedProperty; len(properties) != 1 || properties[0].Name != "owner" {
+		t.Errorf("Expected the extended properties to be sent, got %+v", properties)
+	}
+
+	_, err = NewClientFromManagementClient(client).CreateStorageServiceWithParams(CreateStorageServiceParams{
+		ServiceName:   "account",
+		Location:      "West US",
+		AffinityGroup: "mygroup",
+	})
+	if err == nil || !strings.Contains(err.Error(), errLocationAndAffinityGroup) {
+		t.Fatalf("Expected Location and AffinityGroup to be rejected together, got %v", err)
+	}
+}
+
+func TestCreateStorageServiceCancelReportsOperation(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.operationStatus = "InProgress"
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := client.CreateStorageService("account", "West US", management.WithContext(ctx))
+	var abortedErr *management.AbortedOperationError
+	if !errors.As(err, &abortedErr) {
+		t.Fatalf("Expected an AbortedOperationError, got %v", err)
+	}
+	if abortedErr.OperationID != "operation-1" || abortedErr.Resource != "account" {
+		t.Errorf("Expected operation-1 on account, got %s on %s", abortedErr.OperationID, abortedErr.Resource)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error to wrap context.Canceled, got %v", err)
+	}
+}
+
+func TestDeleteStorageServiceWaitsForOperation(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "myaccount"})
+
+	client := newTestClient(t, server)
+	if err := client.DeleteStorageService("myaccount"); err != nil {
+		t.Fatal(err)
+	}
+	if count := server.countRequests("GET", "operations/operation-1"); count != 1 {
+		t.Errorf("Expected the deletion to be polled once, got %d", count)
+	}
+
+	_, err := client.GetStorageServiceByName("myaccount")
+	if !management.IsNotFound(err) {
+		t.Fatalf("Expected the account to be gone, got %v", err)
+	}
+
+	err = client.DeleteStorageService("myaccount")
+	if !management.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if prefix := "storageservice.DeleteStorageService(myaccount): "; !strings.HasPrefix(err.Error(), prefix) {
+		t.Fatalf("Expected error to start with '%s', got '%s'", prefix, err.Error())
+	}
+}
+
+func TestUpdateStorageServiceChangesOnlyGivenProperties(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{
+		ServiceName: "myaccount",
+		StorageServiceProperties: StorageServiceProperties{
+			Label:                 "myaccount",
+			Description:           "unchanged",
+			GeoReplicationEnabled: "true",
+		},
+	})
+
+	client := newTestClient(t, server)
+	geoReplicationEnabled := false
+	err := client.UpdateStorageService("myaccount", UpdateStorageServiceParams{
+		Label:                 "renamed",
+		GeoReplicationEnabled: &geoReplicationEnabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storageService, err := client.GetStorageServiceByName("myaccount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := storageService.StorageServiceProperties
+	if properties.Label != "renamed" {
+		t.Errorf("Expected label 'renamed', got '%s'", properties.Label)
+	}
+	if properties.Description != "unchanged" {
+		t.Errorf("Expected the description to be left unchanged, got '%s'", properties.Description)
+	}
+	if properties.GeoReplicationEnabled != "false" {
+		t.Errorf("Expected geo-replication to be disabled, got '%s'", properties.GeoReplicationEnabled)
+	}
+}
+
+func TestUpdateStorageServiceSendsOnlyGivenProperties(t *testing.T) {
+	client := fake.NewClient()
+	url := "services/storageservices/myaccount"
+	client.AddResponse("PUT", url, "")
+
+	err := NewClientFromManagementClient(client).UpdateStorageService("myaccount", UpdateStorageServiceParams{Description: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := client.Requests()
+	if len(requests) != 1 || requests[0].Method != "PUT" || requests[0].URL != url {
+		t.Fatalf("Expected a PUT to %s, got %v", url, requests)
+	}
+	if body := string(requests[0].Body); !strings.Contains(body, "<Description>new</Description>") || strings.Contains(body, "<Label>") {
+		t.Errorf("Expected only the description to be sent, got %s", body)
+	}
+}
+
+func TestSetCustomDomain(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "myaccount"})
+
+	client := newTestClient(t, server)
+	if err := client.SetCustomDomain("myaccount", "assets.contoso.com", true); err != nil {
+		t.Fatal(err)
+	}
+
+	storageService, err := client.GetStorageServiceByName("myaccount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domains := storageService.StorageServiceProperties.CustomDomains; len(domains) != 1 || domains[0].Name != "assets.contoso.com" {
+		t.Fatalf("Expected the custom domain to be set, got %+v", domains)
+	}
+
+	if err := client.SetCustomDomain("myaccount", "", false); err != nil {
+		t.Fatal(err)
+	}
+	storageService, err = client.GetStorageServiceByName("myaccount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domains := storageService.StorageServiceProperties.CustomDomains; len(domains) != 0 {
+		t.Fatalf("Expected the custom domain to be removed, got %+v", domains)
+	}
+}
+
+func TestGetStorageServiceByNameWrapsNotFound(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	_, err := client.GetStorageServiceByName("missing")
+	if !management.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if prefix := "storageservice.GetStorageServiceByName(missing): "; !strings.HasPrefix(err.Error(), prefix) {
+		t.Fatalf("Expected error to start with '%s', got '%s'", prefix, err.Error())
+	}
+}
+
+func TestStorageServiceRequestURLs(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	if _, err := client.CreateStorageService("account", "West US"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetStorageServiceList(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.IsAvailable("account"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"POST /subscriptionID/services/storageservices",
+		"GET /subscriptionID/operations/operation-1",
+		"GET /subscriptionID/services/storageservices/account",
+		"GET /subscriptionID/services/storageservices",
+		"GET /subscriptionID/services/storageservices/operations/isavailable/account",
+	}
+	if len(server.requests) != len(expected) {
+		t.Fatalf("Expected requests %v, got %v", expected, server.requests)
+	}
+	for i := range expected {
+		if server.requests[i] != expected[i] {
+			t.Fatalf("Expected requests %v, got %v", expected, server.requests)
+		}
+	}
+}
+
+func TestStorageServiceClientHonorsClientContext(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+
+	client, err := management.NewClientFromConfig(testSubscriptionID, []byte("cert"), management.ClientConfig{ManagementURL: server.URL, HTTPClient: &http.Client{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = NewClient(client.WithContext(ctx)).GetStorageServiceList()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if count := server.countRequests("GET", "services/storageservices"); count != 0 {
+		t.Errorf("Expected no request to be sent, got %d", count)
+	}
+}
+
+func TestGetBlobEndpointUsesClientEnvironment(t *testing.T) {
+	client, err := management.NewClientFromConfig(testSubscriptionID, []byte("cert"), management.ClientConfig{Environment: management.USGovernmentCloud, HTTPClient: &http.Client{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageClient := NewClient(client)
+
+	endpoint, err := storageClient.GetBlobEndpoint(newTestStorageService())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "https://account.blob.core.usgovcloudapi.net/"; endpoint != expected {
+		t.Errorf("Expected endpoint %s for a storage service without endpoints, got %s", expected, endpoint)
+	}
+
+	endpoint, err = storageClient.GetBlobEndpoint(newTestStorageService("https://account.blob.local.azurestack.external/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "https://account.blob.local.azurestack.external/"; endpoint != expected {
+		t.Errorf("Expected the endpoint listed by the storage service, got %s", endpoint)
+	}
+}
+
+func TestStorageServiceClientWithFakeManagementClient(t *testing.T) {
+	fakeClient := fake.NewClient()
+	fakeClient.AddResponse("GET", "services/storageservices/account",
+		`<StorageService><ServiceName>account</ServiceName><StorageServiceProperties><Location>West US</Location></StorageServiceProperties></StorageService>`)
+
+	storageService, created, err := NewClientFromManagementClient(fakeClient).EnsureStorageService(CreateStorageServiceParams{ServiceName: "account", Location: "West US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || storageService.ServiceName != "account" {
+		t.Errorf("Expected the existing account, got %+v, created %v", storageService, created)
+	}
+	if requests := fakeClient.Requests(); len(requests) != 1 {
+		t.Errorf("Expected a single lookup, got %+v", requests)
+	}
+}
+
+func TestGetStorageServiceByNameReturnsETag(t *testing.T) {
+	client := fake.NewClient()
+	client.Add("GET", "services/storageservices/account", fake.Response{
+		Body: []byte("<StorageService><ServiceName>account</ServiceName></StorageService>"),
+		ETag: `"etag"`,
+	})
+
+	storageService, err := NewClientFromManagementClient(client).GetStorageServiceByName("account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storageService.ETag != `"etag"` {
+		t.Errorf(`Expected ETag "etag", got %s`, storageService.ETag)
+	}
+}
@@ -0,0 +1,106 @@
+package storageservice
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	blobServiceLabel  = "blob"
+	queueServiceLabel = "queue"
+	tableServiceLabel = "table"
+	fileServiceLabel  = "file"
+
+	errEndpointNotFound   = "The %s endpoint was not found in storage service %s"
+	errNoEndpoints        = "Storage service %s has no endpoints yet (status %s). Wait for it to finish provisioning."
+	errInvalidEndpoint    = "Storage service %s has an invalid endpoint %q: %s"
+	errInvalidEndpointURL = "Invalid storage endpoint %q: %s"
+)
+
+// ServiceEndpoints holds the endpoints of the services of a storage account,
+// such as https://account.blob.core.windows.net/ for the blob service. The
+// endpoints of the services an account does not offer are empty.
+type ServiceEndpoints struct {
+	Blob  string
+	Queue string
+	Table string
+	File  string
+}
+
+// ServiceEndpoints returns the Endpoints of the storage account by service.
+// The service of an endpoint is identified by the second label of its host
+// name rather than by its DNS suffix, so the endpoints of accounts in
+// sovereign clouds, such as account.blob.core.chinacloudapi.cn, and in custom
+// environments are recognized too. Endpoints of other services are ignored.
+func (properties StorageServiceProperties) ServiceEndpoints() (ServiceEndpoints, error) {
+	return parseServiceEndpoints(properties.Endpoints)
+}
+
+// SecondaryServiceEndpoints returns the SecondaryEndpoints of the storage
+// account by service, as ServiceEndpoints does for its Endpoints.
+func (properties StorageServiceProperties) SecondaryServiceEndpoints() (ServiceEndpoints, error) {
+	return parseServiceEndpoints(properties.SecondaryEndpoints)
+}
+
+func parseServiceEndpoints(rawEndpoints []string) (ServiceEndpoints, error) {
+	var endpoints ServiceEndpoints
+	for _, endpoint := range rawEndpoints {
+		service, err := endpointService(endpoint)
+		if err != nil {
+			return ServiceEndpoints{}, fmt.Errorf(errInvalidEndpointURL, endpoint, err)
+		}
+
+		endpoint = strings.TrimSpace(endpoint)
+		switch service {
+		case blobServiceLabel:
+			endpoints.Blob = endpoint
+		case queueServiceLabel:
+			endpoints.Queue = endpoint
+		case tableServiceLabel:
+			endpoints.Table = endpoint
+		case fileServiceLabel:
+			endpoints.File = endpoint
+		}
+	}
+
+	return endpoints, nil
+}
+
+// serviceEndpoint returns the endpoint of storageService for the given
+// storage service. See StorageServiceProperties.ServiceEndpoints.
+func serviceEndpoint(storageService *StorageService, service string) (string, error) {
+	endpoints := storageService.StorageServiceProperties.Endpoints
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf(errNoEndpoints, storageService.ServiceName, storageService.StorageServiceProperties.Status)
+	}
+
+	for _, endpoint := range endpoints {
+		endpointService, err := endpointService(endpoint)
+		if err != nil {
+			return "", fmt.Errorf(errInvalidEndpoint, storageService.ServiceName, endpoint, err)
+		}
+		if endpointService == service {
+			return endpoint, nil
+		}
+	}
+
+	return "", fmt.Errorf(errEndpointNotFound, service, storageService.ServiceName)
+}
+
+// endpointService returns the lower-cased second label of the host name of
+// endpoint, as blob in account.blob.core.windows.net, or an empty string if
+// the host name has fewer than three labels.
+func endpointService(endpoint string) (string, error) {
+	endpointURL, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", err
+	}
+
+	labels := strings.Split(endpointURL.Host, ".")
+	if len(labels) < 3 {
+		return "", nil
+	}
+
+	return strings.ToLower(labels[1]), nil
+}
@@ -0,0 +1,68 @@
+package storageservice
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestServiceEndpoints(t *testing.T) {
+	properties := StorageServiceProperties{Endpoints: []string{
+		"https://account.blob.core.chinacloudapi.cn/",
+		" https://account.Queue.core.chinacloudapi.cn/ ",
+		"https://account.table.core.chinacloudapi.cn/",
+		"https://account.dfs.core.chinacloudapi.cn/",
+	}}
+
+	endpoints, err := properties.ServiceEndpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ServiceEndpoints{
+		Blob:  "https://account.blob.core.chinacloudapi.cn/",
+		Queue: "https://account.Queue.core.chinacloudapi.cn/",
+		Table: "https://account.table.core.chinacloudapi.cn/",
+	}
+	if endpoints != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, endpoints)
+	}
+
+	properties.Endpoints = append(properties.Endpoints, "https://account.file.core.chinacloudapi.cn/", "http://[::1")
+	if _, err := properties.ServiceEndpoints(); err == nil {
+		t.Fatal("Expected an invalid endpoint to be reported")
+	}
+}
+
+func TestGeoReplicationProperties(t *testing.T) {
+	body := `<StorageService xmlns="http://schemas.microsoft.com/windowsazure">
+  <ServiceName>account</ServiceName>
+  <StorageServiceProperties>
+    <Endpoints><Endpoint>https://account.blob.core.windows.net/</Endpoint></Endpoints>
+    <GeoReplicationEnabled>true</GeoReplicationEnabled>
+    <GeoPrimaryRegion>West US</GeoPrimaryRegion>
+    <StatusOfPrimary>Available</StatusOfPrimary>
+    <LastGeoFailoverTime>2015-03-01T10:00:00Z</LastGeoFailoverTime>
+    <GeoSecondaryRegion>East US</GeoSecondaryRegion>
+    <StatusOfSecondary>Unavailable</StatusOfSecondary>
+    <SecondaryEndpoints><Endpoint>https://account-secondary.blob.core.windows.net/</Endpoint></SecondaryEndpoints>
+    <AccountType>Standard_RAGRS</AccountType>
+  </StorageServiceProperties>
+</StorageService>`
+
+	storageService := StorageService{}
+	if err := xml.Unmarshal([]byte(body), &storageService); err != nil {
+		t.Fatal(err)
+	}
+	properties := storageService.StorageServiceProperties
+	if properties.GeoSecondaryRegion != "East US" || properties.StatusOfPrimary != "Available" ||
+		properties.StatusOfSecondary != "Unavailable" || properties.LastGeoFailoverTime != "2015-03-01T10:00:00Z" {
+		t.Errorf("Unexpected geo-replication properties: %+v", properties)
+	}
+
+	endpoints, err := properties.SecondaryServiceEndpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoints.Blob != "https://account-secondary.blob.core.windows.net/" {
+		t.Errorf("Expected the secondary blob endpoint, got %+v", endpoints)
+	}
+}
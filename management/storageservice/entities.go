@@ -2,13 +2,22 @@ package storageservice
 
 import (
 	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/label"
 )
 
-//StorageServiceClient is used to manage operations on Azure Storage
+//StorageServiceClient is used to manage operations on Azure Storage. It is an
+//immutable value that holds nothing but the management client it was created
+//from, so a single StorageServiceClient may be copied freely and shared by
+//multiple goroutines. Any mutable state added to it must live behind a
+//pointer and be synchronized.
 type StorageServiceClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 type StorageServiceList struct {
@@ -21,16 +30,79 @@ type StorageService struct {
 	Url                      string
 	ServiceName              string
 	StorageServiceProperties StorageServiceProperties
+	ExtendedProperties       ExtendedProperties `xml:",omitempty"`
+
+	// ETag is the entity tag of the storage service when it was retrieved,
+	// if the service sent one. See management.Client.WithIfMatch.
+	ETag string `xml:"-"`
 }
 
 type StorageServiceProperties struct {
 	Description           string
+	AffinityGroup         string
 	Location              string
-	Label                 string
+	Label                 label.Label
 	Status                string
 	Endpoints             []string `xml:"Endpoints>Endpoint"`
 	GeoReplicationEnabled string
 	GeoPrimaryRegion      string
+	AccountType           string
+	CustomDomains         []CustomDomain `xml:"CustomDomains>CustomDomain"`
+
+	// The replication state of geo-replicated accounts. StatusOfPrimary and
+	// StatusOfSecondary are Available or Unavailable, and
+	// LastGeoFailoverTime, in RFC 3339 form, is empty for accounts that
+	// never failed over.
+	GeoSecondaryRegion  string
+	StatusOfPrimary     string
+	StatusOfSecondary   string
+	LastGeoFailoverTime string
+
+	// SecondaryEndpoints are the read-only endpoints of the secondary region
+	// of Standard_RAGRS accounts.
+	SecondaryEndpoints []string `xml:"SecondaryEndpoints>Endpoint"`
+}
+
+//CustomDomain is a domain mapped to the blob endpoint of a storage account.
+type CustomDomain struct {
+	Name string
+
+	// UseSubDomainName selects indirect CNAME validation through the
+	// asverify subdomain when the domain is set. It is not returned by the
+	// service.
+	UseSubDomainName bool `xml:",omitempty"`
+}
+
+//StorageServiceKeysResponse is the response of the Get Storage Account Keys
+//operation.
+type StorageServiceKeysResponse struct {
+	XMLName            xml.Name `xml:"StorageService"`
+	Xmlns              string   `xml:"xmlns,attr"`
+	Url                string
+	ServiceName        string
+	StorageServiceKeys StorageServiceKeys
+}
+
+//StorageServiceKeys holds the base64 encoded access keys of a storage
+//account, which authenticate the requests to its data plane.
+type StorageServiceKeys struct {
+	Primary   string
+	Secondary string
+}
+
+//KeyType selects the access key of a storage account regenerated by
+//RegenerateStorageServiceKeys.
+type KeyType string
+
+const (
+	KeyTypePrimary   KeyType = "Primary"
+	KeyTypeSecondary KeyType = "Secondary"
+)
+
+type RegenerateKeys struct {
+	XMLName xml.Name `xml:"RegenerateKeys"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	KeyType KeyType
 }
 
 type StorageServiceDeployment struct {
@@ -38,12 +110,71 @@ type StorageServiceDeployment struct {
 	Xmlns                 string   `xml:"xmlns,attr"`
 	ServiceName           string
 	Description           string
-	Label                 string
+	Label                 label.Label
 	AffinityGroup         string `xml:",omitempty"`
 	Location              string `xml:",omitempty"`
 	GeoReplicationEnabled bool
 	ExtendedProperties    ExtendedPropertyList
 	SecondaryReadEnabled  bool
+	AccountType           string `xml:",omitempty"`
+}
+
+//CreateStorageServiceParams describes a storage account to be created.
+type CreateStorageServiceParams struct {
+	ServiceName string
+	Description string
+
+	// Location is the region of the account. Exactly one of Location and
+	// AffinityGroup must be set.
+	Location      string
+	AffinityGroup string
+
+	ExtendedProperties []ExtendedProperty
+
+	// AccountType is the replication type of the account, one of the
+	// AccountType constants. If empty, the service default, Standard_GRS,
+	// is used.
+	AccountType string
+}
+
+//Account types of storage accounts, for CreateStorageServiceParams and
+//StorageServiceProperties.AccountType.
+const (
+	AccountTypeStandardLRS   = "Standard_LRS"
+	AccountTypeStandardGRS   = "Standard_GRS"
+	AccountTypeStandardRAGRS = "Standard_RAGRS"
+	AccountTypeStandardZRS   = "Standard_ZRS"
+	AccountTypePremiumLRS    = "Premium_LRS"
+)
+
+//UpdateStorageServiceParams describes the changes made to a storage account
+//by UpdateStorageService. Empty fields are left unchanged.
+type UpdateStorageServiceParams struct {
+	Label       string
+	Description string
+
+	// GeoReplicationEnabled turns geo-replication of the account on or off
+	// if it is not nil.
+	GeoReplicationEnabled *bool
+
+	// ExtendedProperties are added to the account, or replace the values of
+	// the properties of the same names.
+	ExtendedProperties []ExtendedProperty
+
+	// ETag, if set, makes the update fail, with management.IsPreconditionFailed
+	// holding for the error, if the account was changed since it was
+	// retrieved with this entity tag.
+	ETag string
+}
+
+type UpdateStorageServiceInput struct {
+	XMLName               xml.Name              `xml:"UpdateStorageServiceInput"`
+	Xmlns                 string                `xml:"xmlns,attr"`
+	Description           string                `xml:",omitempty"`
+	Label                 label.Label           `xml:",omitempty"`
+	GeoReplicationEnabled *bool                 `xml:",omitempty"`
+	ExtendedProperties    *ExtendedPropertyList `xml:",omitempty"`
+	CustomDomains         []CustomDomain        `xml:"CustomDomains>CustomDomain,omitempty"`
 }
 
 type ExtendedPropertyList struct {
@@ -55,9 +186,91 @@ type ExtendedProperty struct {
 	Value string
 }
 
+//ExtendedProperties are the extended properties of a storage account, the
+//name/value pairs tagging it, by name. They are encoded in XML as an
+//ExtendedPropertyList.
+type ExtendedProperties map[string]string
+
+//MarshalXML implements xml.Marshaler.
+func (properties ExtendedProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(properties.list(), start)
+}
+
+//list returns the properties as an ExtendedPropertyList, sorted by name.
+func (properties ExtendedProperties) list() *ExtendedPropertyList {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := &ExtendedPropertyList{}
+	for _, name := range names {
+		list.ExtendedProperty = append(list.ExtendedProperty, ExtendedProperty{Name: name, Value: properties[name]})
+	}
+
+	return list
+}
+
+//UnmarshalXML implements xml.Unmarshaler.
+func (properties *ExtendedProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	list := ExtendedPropertyList{}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+
+	*properties = make(ExtendedProperties, len(list.ExtendedProperty))
+	for _, property := range list.ExtendedProperty {
+		(*properties)[property.Name] = property.Value
+	}
+
+	return nil
+}
+
 type AvailabilityResponse struct {
 	XMLName xml.Name `xml:"AvailabilityResponse"`
 	Xmlns   string   `xml:"xmlns,attr"`
 	Result  bool
 	Reason  string
 }
+
+//ErrExistsWithDifferentProperties is returned by EnsureStorageService when a
+//storage account with the requested name already exists, but its properties
+//do not match the ones that were asked for.
+type ErrExistsWithDifferentProperties struct {
+	ServiceName string
+	Mismatches  []PropertyMismatch
+}
+
+//PropertyMismatch describes a single property of an existing storage account
+//that differs from the requested value.
+type PropertyMismatch struct {
+	Property string
+	Expected string
+	Actual   string
+}
+
+//ErrNoStorageServiceInLocation is returned by GetStorageServiceByLocation when
+//the subscription has no storage service in the location.
+type ErrNoStorageServiceInLocation struct {
+	Location string
+}
+
+func (e *ErrNoStorageServiceInLocation) Error() string {
+	return fmt.Sprintf("No storage service found in location %s", e.Location)
+}
+
+//HTTPStatusCode returns 404 Not Found, so that management.IsNotFound holds for
+//the error.
+func (e *ErrNoStorageServiceInLocation) HTTPStatusCode() int {
+	return http.StatusNotFound
+}
+
+func (e *ErrExistsWithDifferentProperties) Error() string {
+	mismatches := make([]string, len(e.Mismatches))
+	for i, mismatch := range e.Mismatches {
+		mismatches[i] = fmt.Sprintf("%s is %q, expected %q", mismatch.Property, mismatch.Actual, mismatch.Expected)
+	}
+
+	return fmt.Sprintf("Storage service %s already exists with different properties: %s", e.ServiceName, strings.Join(mismatches, "; "))
+}
@@ -0,0 +1,82 @@
+package storageservice
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	maxExtendedPropertyValueLength = 255
+
+	errInvalidExtendedPropertyName  = "Invalid extended property name %q: names must be 1 to 64 letters, numbers and underscores, starting with a letter."
+	errInvalidExtendedPropertyValue = "Invalid value of extended property %s: values must be at most 255 characters."
+)
+
+var extendedPropertyNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+//SetExtendedProperties adds the given extended properties to a storage
+//account, for example to tag it for cost attribution, replacing the values of
+//the properties it already has with the same names. Its other properties are
+//left unchanged. The options control the wait for the update to complete.
+func (self StorageServiceClient) SetExtendedProperties(name string, properties map[string]string, options ...management.WaitOption) error {
+	if name == "" {
+		return wrapError("SetExtendedProperties", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+	if len(properties) == 0 {
+		return wrapError("SetExtendedProperties", name, fmt.Errorf(errParamNotSpecified, "properties"))
+	}
+	for propertyName, value := range properties {
+		if err := validateExtendedProperty(propertyName, value); err != nil {
+			return wrapError("SetExtendedProperties", name, err)
+		}
+	}
+
+	err := self.updateExtendedProperties(name, properties, options)
+	return wrapError("SetExtendedProperties", name, err)
+}
+
+//DeleteExtendedProperties removes the extended properties with the given
+//names from a storage account, by setting them to an empty value, which the
+//service treats as a removal. Names the account does not have are ignored.
+//The options control the wait for the update to complete.
+func (self StorageServiceClient) DeleteExtendedProperties(name string, propertyNames []string, options ...management.WaitOption) error {
+	if name == "" {
+		return wrapError("DeleteExtendedProperties", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+	if len(propertyNames) == 0 {
+		return wrapError("DeleteExtendedProperties", name, fmt.Errorf(errParamNotSpecified, "propertyNames"))
+	}
+
+	properties := make(map[string]string, len(propertyNames))
+	for _, propertyName := range propertyNames {
+		if err := validateExtendedProperty(propertyName, ""); err != nil {
+			return wrapError("DeleteExtendedProperties", name, err)
+		}
+		properties[propertyName] = ""
+	}
+
+	err := self.updateExtendedProperties(name, properties, options)
+	return wrapError("DeleteExtendedProperties", name, err)
+}
+
+func (self StorageServiceClient) updateExtendedProperties(name string, properties map[string]string, options []management.WaitOption) error {
+	updateInput := UpdateStorageServiceInput{
+		Xmlns:              azureXmlns,
+		ExtendedProperties: ExtendedProperties(properties).list(),
+	}
+
+	return self.updateStorageService(name, updateInput, "", options)
+}
+
+func validateExtendedProperty(name, value string) error {
+	if !extendedPropertyNamePattern.MatchString(name) {
+		return fmt.Errorf(errInvalidExtendedPropertyName, name)
+	}
+	if len([]rune(value)) > maxExtendedPropertyValueLength {
+		return fmt.Errorf(errInvalidExtendedPropertyValue, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package storageservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtendedProperties(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "account", ExtendedProperties: ExtendedProperties{"owner": "ops"}})
+	client := newTestClient(t, server)
+
+	if err := client.SetExtendedProperties("account", map[string]string{"costCenter": "42", "owner": "web"}); err != nil {
+		t.Fatal(err)
+	}
+	storageService, err := client.GetStorageServiceByName("account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (ExtendedProperties{"costCenter": "42", "owner": "web"}); !reflect.DeepEqual(storageService.ExtendedProperties, expected) {
+		t.Fatalf("Expected %v, got %v", expected, storageService.ExtendedProperties)
+	}
+
+	if err := client.DeleteExtendedProperties("account", []string{"owner"}); err != nil {
+		t.Fatal(err)
+	}
+	storageService, err = client.GetStorageServiceByName("account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (ExtendedProperties{"costCenter": "42"}); !reflect.DeepEqual(storageService.ExtendedProperties, expected) {
+		t.Fatalf("Expected %v, got %v", expected, storageService.ExtendedProperties)
+	}
+
+	if err := client.SetExtendedProperties("account", map[string]string{"cost center": "42"}); err == nil {
+		t.Fatal("Expected an invalid property name to be rejected")
+	}
+}
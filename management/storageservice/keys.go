@@ -0,0 +1,71 @@
+package storageservice
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	regenerateKeysQuery = "?action=regenerate"
+
+	errInvalidKeyType = "Invalid key type %q: expected Primary or Secondary."
+)
+
+//GetStorageServiceKeys returns the primary and secondary access keys of a
+//storage account, used together with the endpoints returned by
+//GetBlobEndpoint to authenticate to the storage data plane.
+//See https://msdn.microsoft.com/en-us/library/azure/ee460785.aspx
+func (self StorageServiceClient) GetStorageServiceKeys(name string) (*StorageServiceKeys, error) {
+	if name == "" {
+		return nil, wrapError("GetStorageServiceKeys", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+
+	requestURL := self.client.Route(management.RouteStorageServiceKeys, name)
+	response, err := self.client.SendAzureGetRequest(requestURL)
+	if err != nil {
+		return nil, wrapError("GetStorageServiceKeys", name, err)
+	}
+
+	keysResponse := new(StorageServiceKeysResponse)
+	err = xml.Unmarshal(response, keysResponse)
+	if err != nil {
+		return nil, wrapError("GetStorageServiceKeys", name, err)
+	}
+
+	return &keysResponse.StorageServiceKeys, nil
+}
+
+//RegenerateStorageServiceKeys regenerates the primary or secondary access key
+//of a storage account, as selected by keyType, and returns the keys of the
+//account, including the new one. Requests authenticated with the old key fail
+//from then on, so rotate the key that clients are not using.
+//See https://msdn.microsoft.com/en-us/library/azure/ee460795.aspx
+func (self StorageServiceClient) RegenerateStorageServiceKeys(name string, keyType KeyType) (*StorageServiceKeys, error) {
+	if name == "" {
+		return nil, wrapError("RegenerateStorageServiceKeys", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+	if keyType != KeyTypePrimary && keyType != KeyTypeSecondary {
+		return nil, wrapError("RegenerateStorageServiceKeys", name, fmt.Errorf(errInvalidKeyType, keyType))
+	}
+
+	regenerateBytes, err := xml.Marshal(RegenerateKeys{Xmlns: azureXmlns, KeyType: keyType})
+	if err != nil {
+		return nil, wrapError("RegenerateStorageServiceKeys", name, err)
+	}
+
+	requestURL := self.client.Route(management.RouteStorageServiceKeys, name) + regenerateKeysQuery
+	response, err := self.client.SendAzureRequest(requestURL, "POST", "application/xml", regenerateBytes)
+	if err != nil {
+		return nil, wrapError("RegenerateStorageServiceKeys", name, err)
+	}
+
+	keysResponse := new(StorageServiceKeysResponse)
+	err = xml.Unmarshal(response.Body, keysResponse)
+	if err != nil {
+		return nil, wrapError("RegenerateStorageServiceKeys", name, err)
+	}
+
+	return &keysResponse.StorageServiceKeys, nil
+}
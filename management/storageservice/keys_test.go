@@ -0,0 +1,65 @@
+package storageservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+)
+
+func TestGetStorageServiceKeys(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("GET", "services/storageservices/myaccount/keys", `<StorageService xmlns="http://schemas.microsoft.com/windowsazure">
+  <Url>https://management.core.windows.net/subscriptionID/services/storageservices/myaccount</Url>
+  <StorageServiceKeys>
+    <Primary>cHJpbWFyeQ==</Primary>
+    <Secondary>c2Vjb25kYXJ5</Secondary>
+  </StorageServiceKeys>
+</StorageService>`)
+
+	keys, err := NewClientFromManagementClient(client).GetStorageServiceKeys("myaccount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Primary != "cHJpbWFyeQ==" || keys.Secondary != "c2Vjb25kYXJ5" {
+		t.Fatalf("Unexpected keys: %+v", keys)
+	}
+
+	_, err = NewClientFromManagementClient(client).GetStorageServiceKeys("missing")
+	if !management.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestRegenerateStorageServiceKeys(t *testing.T) {
+	client := fake.NewClient()
+	url := "services/storageservices/myaccount/keys?action=regenerate"
+	client.Add("POST", url, fake.Response{Body: []byte(`<StorageService xmlns="http://schemas.microsoft.com/windowsazure">
+  <StorageServiceKeys>
+    <Primary>cHJpbWFyeQ==</Primary>
+    <Secondary>bmV3</Secondary>
+  </StorageServiceKeys>
+</StorageService>`)})
+
+	keys, err := NewClientFromManagementClient(client).RegenerateStorageServiceKeys("myaccount", KeyTypeSecondary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Secondary != "bmV3" {
+		t.Fatalf("Expected the new secondary key, got %+v", keys)
+	}
+
+	requests := client.Requests()
+	if len(requests) != 1 || requests[0].URL != url {
+		t.Fatalf("Expected a POST to %s, got %v", url, requests)
+	}
+	if body := string(requests[0].Body); !strings.Contains(body, "<KeyType>Secondary</KeyType>") {
+		t.Errorf("Expected the secondary key to be regenerated, got %s", body)
+	}
+
+	_, err = NewClientFromManagementClient(client).RegenerateStorageServiceKeys("myaccount", "Tertiary")
+	if err == nil || len(client.Requests()) != 1 {
+		t.Fatalf("Expected an invalid key type to be rejected before sending, got %v", err)
+	}
+}
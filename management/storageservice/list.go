@@ -0,0 +1,235 @@
+package storageservice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	errMissingServiceName = "Storage service entry has no ServiceName"
+	errPartialList        = "%d of %d storage service entries could not be decoded: %s"
+)
+
+//ListOptions control how list operations decode and order the response.
+type ListOptions struct {
+	// Strict makes a list operation fail as a whole when any of its entries
+	// cannot be decoded. By default the entries that decoded are returned
+	// along with a *PartialListError describing the ones that did not.
+	Strict bool
+
+	// SortBy lists the keys the entries are ordered by before their name,
+	// for example management.SortByLocation. Entries are always sorted by
+	// name, case-insensitively.
+	SortBy []management.SortKey
+}
+
+//SortValue implements management.Sortable.
+func (storageService StorageService) SortValue(key management.SortKey) string {
+	switch key {
+	case management.SortByName:
+		return storageService.ServiceName
+	case management.SortByLocation:
+		return storageService.StorageServiceProperties.Location
+	}
+	return ""
+}
+
+//sortStorageServices orders storageServices by keys, then by name.
+func sortStorageServices(storageServices []StorageService, keys []management.SortKey) {
+	sort.SliceStable(storageServices, func(i, j int) bool {
+		return management.Less(storageServices[i], storageServices[j], keys...)
+	})
+}
+
+//PartialListError is returned by GetStorageServiceList when some entries of
+//the list could not be decoded. StorageServices holds the entries that were
+//decoded, so callers may choose to proceed with them.
+type PartialListError struct {
+	StorageServices []StorageService
+	Errors          []ListEntryError
+}
+
+//ListEntryError describes an entry of a list that could not be decoded.
+//Index is the position of the entry in the response and ServiceName is set
+//when it could be read.
+type ListEntryError struct {
+	Index       int
+	ServiceName string
+	Err         error
+}
+
+func (e *PartialListError) Error() string {
+	entryErrors := make([]string, len(e.Errors))
+	for i, entryError := range e.Errors {
+		entryErrors[i] = entryError.Error()
+	}
+
+	return fmt.Sprintf(errPartialList, len(e.Errors), len(e.Errors)+len(e.StorageServices), strings.Join(entryErrors, "; "))
+}
+
+func (e ListEntryError) Error() string {
+	if e.ServiceName == "" {
+		return fmt.Sprintf("entry %d: %s", e.Index, e.Err)
+	}
+	return fmt.Sprintf("entry %d (%s): %s", e.Index, e.ServiceName, e.Err)
+}
+
+func (e ListEntryError) Unwrap() error {
+	return e.Err
+}
+
+//ListStorageServices returns an iterator over the storage services of the
+//subscription, in the order of the service, which fetches them a page at a
+//time. Entries that cannot be decoded are skipped and reported by Err.
+func (self StorageServiceClient) ListStorageServices() *StorageServiceIterator {
+	requestURL := self.client.Route(management.RouteStorageServiceList)
+	return &StorageServiceIterator{pager: management.NewPager(self.client, requestURL)}
+}
+
+//StorageServiceIterator iterates over storage services. Call Next before each
+//Value, and Err once Next returns false.
+type StorageServiceIterator struct {
+	pager      *management.Pager
+	list       *StorageServiceList
+	index      int
+	entries    int
+	partialErr *PartialListError
+}
+
+//Next advances to the next storage service, fetching the next page if
+//needed. It returns false at the end of the list or on error.
+func (iterator *StorageServiceIterator) Next() bool {
+	for iterator.list == nil || iterator.index >= len(iterator.list.StorageServices) {
+		if !iterator.pager.Next() {
+			return false
+		}
+
+		page, err := decodeStorageServiceList(iterator.pager.Page())
+		var partialErr *PartialListError
+		if errors.As(err, &partialErr) {
+			if iterator.partialErr == nil {
+				iterator.partialErr = new(PartialListError)
+			}
+			for _, entryError := range partialErr.Errors {
+				entryError.Index += iterator.entries
+				iterator.partialErr.Errors = append(iterator.partialErr.Errors, entryError)
+			}
+			iterator.entries += len(partialErr.Errors)
+		}
+		iterator.entries += len(page.StorageServices)
+		iterator.list, iterator.index = page, 0
+	}
+
+	iterator.index++
+	return true
+}
+
+//Value returns the current storage service.
+func (iterator *StorageServiceIterator) Value() StorageService {
+	return iterator.list.StorageServices[iterator.index-1]
+}
+
+//Err returns the error that stopped Next, if any. Once Next has returned
+//false, it is a *PartialListError, without StorageServices, if entries were
+//skipped.
+func (iterator *StorageServiceIterator) Err() error {
+	if err := iterator.pager.Err(); err != nil {
+		return err
+	}
+	if iterator.partialErr != nil {
+		return iterator.partialErr
+	}
+
+	return nil
+}
+
+//decodeStorageServiceList decodes the entries of a storage service list one
+//by one. The list itself is read leniently, so that a malformed entry can be
+//skipped, while each entry is decoded strictly.
+func decodeStorageServiceList(data []byte) (*StorageServiceList, error) {
+	storageServiceList := new(StorageServiceList)
+	partialErr := new(PartialListError)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	index := 0
+decode:
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break decode
+		}
+		if err != nil {
+			partialErr.Errors = append(partialErr.Errors, ListEntryError{Index: index, Err: err})
+			break decode
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "StorageServices":
+			storageServiceList.XMLName = start.Name
+			for _, attr := range start.Attr {
+				if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					storageServiceList.Xmlns = attr.Value
+				}
+			}
+		case "StorageService":
+			storageService, err := decodeStorageServiceEntry(decoder, start)
+			if err != nil {
+				partialErr.Errors = append(partialErr.Errors, ListEntryError{Index: index, ServiceName: storageService.ServiceName, Err: err})
+			} else {
+				storageServiceList.StorageServices = append(storageServiceList.StorageServices, storageService)
+			}
+			index++
+		default:
+			err = decoder.Skip()
+			if err != nil {
+				partialErr.Errors = append(partialErr.Errors, ListEntryError{Index: index, Err: err})
+				break decode
+			}
+		}
+	}
+
+	if len(partialErr.Errors) > 0 {
+		partialErr.StorageServices = storageServiceList.StorageServices
+		return storageServiceList, partialErr
+	}
+
+	return storageServiceList, nil
+}
+
+//decodeStorageServiceEntry decodes the StorageService element that starts
+//with start. The element is always consumed, even if it cannot be decoded.
+func decodeStorageServiceEntry(decoder *xml.Decoder, start xml.StartElement) (StorageService, error) {
+	var storageService StorageService
+
+	var entry struct {
+		InnerXML []byte `xml:",innerxml"`
+	}
+	err := decoder.DecodeElement(&entry, &start)
+	if err != nil {
+		return storageService, err
+	}
+
+	err = xml.Unmarshal(append(append([]byte("<StorageService>"), entry.InnerXML...), "</StorageService>"...), &storageService)
+	if err != nil {
+		return storageService, err
+	}
+	if storageService.ServiceName == "" {
+		return storageService, errors.New(errMissingServiceName)
+	}
+
+	return storageService, nil
+}
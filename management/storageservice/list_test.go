@@ -0,0 +1,207 @@
+package storageservice
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+)
+
+const (
+	goodEntryA = `<StorageService><ServiceName>a</ServiceName><StorageServiceProperties><Location>West US</Location></StorageServiceProperties></StorageService>`
+	goodEntryB = `<StorageService><ServiceName>b</ServiceName><StorageServiceProperties i:nil="true"/></StorageService>`
+	badEntity  = `<StorageService><ServiceName>bad</ServiceName><StorageServiceProperties><Label>&bogus;</Label></StorageServiceProperties></StorageService>`
+	badNesting = `<StorageService><ServiceName>bad</ServiceName><StorageServiceProperties><Label>x</StorageServiceProperties></StorageService>`
+	noName     = `<StorageService><StorageServiceProperties><Location>West US</Location></StorageServiceProperties></StorageService>`
+)
+
+func storageServiceListFixture(entries ...string) []byte {
+	return []byte(`<StorageServices xmlns="` + azureXmlns + `" xmlns:i="http://www.w3.org/2001/XMLSchema-instance">` + strings.Join(entries, "") + `</StorageServices>`)
+}
+
+func serviceNames(storageServices []StorageService) string {
+	names := make([]string, len(storageServices))
+	for i, storageService := range storageServices {
+		names[i] = storageService.ServiceName
+	}
+	return strings.Join(names, ",")
+}
+
+func TestDecodeStorageServiceListSkipsMalformedEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []string
+		badIndex    int
+		badName     string
+		expectNames string
+	}{
+		{"corrupted entity in the middle", []string{goodEntryA, badEntity, goodEntryB}, 1, "bad", "a,b"},
+		{"corrupted nesting first", []string{badNesting, goodEntryA, goodEntryB}, 0, "", "a,b"},
+		{"corrupted entity last", []string{goodEntryA, goodEntryB, badEntity}, 2, "bad", "a,b"},
+		{"missing service name", []string{goodEntryA, noName, goodEntryB}, 1, "", "a,b"},
+	}
+
+	for _, test := range tests {
+		list, err := decodeStorageServiceList(storageServiceListFixture(test.entries...))
+
+		var partialErr *PartialListError
+		if !errors.As(err, &partialErr) {
+			t.Errorf("%s: expected a PartialListError, got %v", test.name, err)
+			continue
+		}
+		if len(partialErr.Errors) != 1 || partialErr.Errors[0].Index != test.badIndex {
+			t.Errorf("%s: expected one error for entry %d, got %v", test.name, test.badIndex, partialErr.Errors)
+		} else if test.badName != "" && partialErr.Errors[0].ServiceName != test.badName {
+			t.Errorf("%s: expected the error to name %q, got %q", test.name, test.badName, partialErr.Errors[0].ServiceName)
+		}
+		if names := serviceNames(partialErr.StorageServices); names != test.expectNames {
+			t.Errorf("%s: expected error to carry %s, got %s", test.name, test.expectNames, names)
+		}
+		if names := serviceNames(list.StorageServices); names != test.expectNames {
+			t.Errorf("%s: expected list %s, got %s", test.name, test.expectNames, names)
+		}
+	}
+}
+
+func TestDecodeStorageServiceListWellFormed(t *testing.T) {
+	list, err := decodeStorageServiceList(storageServiceListFixture(goodEntryA, goodEntryB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Xmlns != azureXmlns || list.XMLName.Local != "StorageServices" {
+		t.Errorf("Expected the list element to be decoded, got %v %q", list.XMLName, list.Xmlns)
+	}
+	if names := serviceNames(list.StorageServices); names != "a,b" {
+		t.Errorf("Expected a,b, got %s", names)
+	}
+	if location := list.StorageServices[0].StorageServiceProperties.Location; location != "West US" {
+		t.Errorf("Expected location West US, got %q", location)
+	}
+}
+
+func TestGetStorageServiceListStrict(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.rawList = string(storageServiceListFixture(goodEntryA, badEntity, goodEntryB))
+	client := newTestClient(t, server)
+
+	list, err := client.GetStorageServiceListWithOptions(ListOptions{Strict: true})
+	if err == nil || list != nil {
+		t.Errorf("Expected strict decoding to fail as a whole, got %v, %v", list, err)
+	}
+	var partialErr *PartialListError
+	if errors.As(err, &partialErr) {
+		t.Errorf("Expected strict decoding not to return a PartialListError")
+	}
+
+	list, err = client.GetStorageServiceList()
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Expected a PartialListError, got %v", err)
+	}
+	if names := serviceNames(list.StorageServices); names != "a,b" {
+		t.Errorf("Expected a,b, got %s", names)
+	}
+
+	storageService, err := client.GetStorageServiceByLocation("West US")
+	if err != nil || storageService == nil || storageService.ServiceName != "a" {
+		t.Errorf("Expected lookup by location to skip the malformed entry, got %v, %v", storageService, err)
+	}
+}
+
+func TestGetStorageServiceListIsSorted(t *testing.T) {
+	entries := []string{
+		`<StorageService><ServiceName>abc</ServiceName><StorageServiceProperties><Location>West US</Location></StorageServiceProperties></StorageService>`,
+		`<StorageService><ServiceName>Abc</ServiceName><StorageServiceProperties><Location>East US</Location></StorageServiceProperties></StorageService>`,
+		`<StorageService><ServiceName>b</ServiceName><StorageServiceProperties><Location>East US</Location></StorageServiceProperties></StorageService>`,
+		`<StorageService><ServiceName>a</ServiceName><StorageServiceProperties><Location>west us</Location></StorageServiceProperties></StorageService>`,
+	}
+
+	tests := []struct {
+		options  ListOptions
+		expected string
+	}{
+		{ListOptions{}, "a,Abc,abc,b"},
+		{ListOptions{Strict: true}, "a,Abc,abc,b"},
+		{ListOptions{SortBy: []management.SortKey{management.SortByLocation}}, "Abc,b,a,abc"},
+	}
+
+	server := newFakeServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	random := rand.New(rand.NewSource(1))
+	for _, test := range tests {
+		for i := 0; i < 5; i++ {
+			random.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+			server.mu.Lock()
+			server.rawList = string(storageServiceListFixture(entries...))
+			server.mu.Unlock()
+
+			list, err := client.GetStorageServiceListWithOptions(test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := serviceNames(list.StorageServices); names != test.expected {
+				t.Fatalf("%+v: expected %s, got %s", test.options, test.expected, names)
+			}
+		}
+	}
+}
+
+func TestListStorageServicesFollowsPages(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("GET", "services/storageservices",
+		string(storageServiceListFixture(goodEntryA, "<ContinuationToken>next</ContinuationToken>")))
+	client.AddResponse("GET", "services/storageservices?ContinuationToken=next",
+		string(storageServiceListFixture(badEntity, goodEntryB)))
+
+	storageServices := NewClientFromManagementClient(client).ListStorageServices()
+	var names []string
+	for storageServices.Next() {
+		names = append(names, storageServices.Value().ServiceName)
+	}
+	if strings.Join(names, ",") != "a,b" {
+		t.Errorf("Expected a,b, got %v", names)
+	}
+
+	var partialErr *PartialListError
+	if !errors.As(storageServices.Err(), &partialErr) {
+		t.Fatalf("Expected a PartialListError, got %v", storageServices.Err())
+	}
+	if len(partialErr.Errors) != 1 || partialErr.Errors[0].Index != 1 || partialErr.Errors[0].ServiceName != "bad" {
+		t.Errorf("Expected the second entry to be reported, got %+v", partialErr.Errors)
+	}
+}
+
+func TestGetStorageServicesByLocation(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.rawList = string(storageServiceListFixture(
+		`<StorageService><ServiceName>b</ServiceName><StorageServiceProperties><Location>West US</Location></StorageServiceProperties></StorageService>`,
+		`<StorageService><ServiceName>c</ServiceName><StorageServiceProperties><Location>East US</Location></StorageServiceProperties></StorageService>`,
+		`<StorageService><ServiceName>a</ServiceName><StorageServiceProperties><Location>west us</Location></StorageServiceProperties></StorageService>`,
+	))
+	client := newTestClient(t, server)
+
+	storageServices, err := client.GetStorageServicesByLocation("West US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := serviceNames(storageServices); names != "a,b" {
+		t.Errorf("Expected a,b, got %s", names)
+	}
+
+	storageServices, err = client.GetStorageServicesByLocation("North Europe")
+	if err != nil || storageServices == nil || len(storageServices) != 0 {
+		t.Errorf("Expected an empty slice, got %v, %v", storageServices, err)
+	}
+
+	storageService, err := client.GetStorageServiceByLocation("North Europe")
+	var notFoundErr *ErrNoStorageServiceInLocation
+	if storageService != nil || !errors.As(err, &notFoundErr) || !management.IsNotFound(err) {
+		t.Errorf("Expected an ErrNoStorageServiceInLocation, got %v, %v", storageService, err)
+	}
+}
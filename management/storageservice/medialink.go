@@ -0,0 +1,71 @@
+package storageservice
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxBlobNameLength = 1024
+
+	errInvalidContainerName = "Invalid container name %q: names must be 3 to 63 lowercase letters, numbers and single hyphens, starting with a letter or number."
+	errInvalidBlobName      = "Invalid blob name %q: names must be 1 to 1024 characters and must not end with a dot or a slash."
+)
+
+var containerNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9]|-[a-z0-9])+$`)
+
+// MediaLinkPrefix returns the https URL of the given container in the blob
+// service of storageService, with a trailing slash, for example
+// https://account.blob.core.windows.net/vhds/. The blob endpoint is taken from
+// the storage service itself, so accounts in sovereign clouds and custom
+// environments get their own DNS suffix. The container name is trimmed of
+// slashes, lower-cased and validated.
+func MediaLinkPrefix(storageService *StorageService, container string) (string, error) {
+	if storageService == nil {
+		return "", fmt.Errorf(errParamNotSpecified, "storageService")
+	}
+
+	container = strings.ToLower(strings.Trim(container, "/"))
+	if len(container) < 3 || len(container) > 63 || !containerNamePattern.MatchString(container) {
+		return "", fmt.Errorf(errInvalidContainerName, container)
+	}
+
+	rawEndpoint, err := serviceEndpoint(storageService, blobServiceLabel)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint.Scheme = "https"
+	endpoint.Path = "/" + container + "/"
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+
+	return endpoint.String(), nil
+}
+
+// NewMediaLink returns the https URL of a blob in the given container of
+// storageService, suitable as the MediaLink of a VHD. The container is
+// validated as in MediaLinkPrefix, and the blob name is validated and escaped.
+func NewMediaLink(storageService *StorageService, container, blobName string) (string, error) {
+	if len(blobName) == 0 || len(blobName) > maxBlobNameLength || strings.HasSuffix(blobName, ".") || strings.HasSuffix(blobName, "/") {
+		return "", fmt.Errorf(errInvalidBlobName, blobName)
+	}
+
+	prefix, err := MediaLinkPrefix(storageService, container)
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(blobName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return prefix + strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,93 @@
+package storageservice
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestStorageService(endpoints ...string) *StorageService {
+	return &StorageService{
+		ServiceName: "account",
+		StorageServiceProperties: StorageServiceProperties{
+			Status:    "Created",
+			Endpoints: endpoints,
+		},
+	}
+}
+
+func TestMediaLinkPrefix(t *testing.T) {
+	tests := []struct {
+		endpoints []string
+		container string
+		expected  string
+	}{
+		{
+			endpoints: []string{"http://account.blob.core.windows.net/", "http://account.queue.core.windows.net/", "http://account.table.core.windows.net/"},
+			container: "vhds",
+			expected:  "https://account.blob.core.windows.net/vhds/",
+		},
+		{
+			endpoints: []string{"https://account.queue.core.chinacloudapi.cn/", "https://account.blob.core.chinacloudapi.cn/"},
+			container: "/VHDs/",
+			expected:  "https://account.blob.core.chinacloudapi.cn/vhds/",
+		},
+		{
+			endpoints: []string{"https://account.blob.local.azurestack.external"},
+			container: "my-images",
+			expected:  "https://account.blob.local.azurestack.external/my-images/",
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := MediaLinkPrefix(newTestStorageService(test.endpoints...), test.container)
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v", test.endpoints, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("Wrong media link prefix. Expected: '%s', got: '%s'", test.expected, actual)
+		}
+	}
+}
+
+func TestMediaLinkPrefixErrors(t *testing.T) {
+	tests := []struct {
+		storageService *StorageService
+		container      string
+		expected       string
+	}{
+		{nil, "vhds", "storageService"},
+		{newTestStorageService("http://account.blob.core.windows.net/"), "a", "Invalid container name"},
+		{newTestStorageService("http://account.blob.core.windows.net/"), "double--hyphen", "Invalid container name"},
+		{newTestStorageService("http://account.blob.core.windows.net/"), "-vhds", "Invalid container name"},
+		{newTestStorageService("http://account.blob.core.windows.net/"), "vhds/nested", "Invalid container name"},
+		{newTestStorageService("http://account.blob.core.windows.net/"), strings.Repeat("a", 64), "Invalid container name"},
+		{newTestStorageService("http://account.queue.core.windows.net/"), "vhds", "blob endpoint was not found"},
+		{&StorageService{ServiceName: "account", StorageServiceProperties: StorageServiceProperties{Status: "ResolvingDns"}}, "vhds", "no endpoints yet (status ResolvingDns)"},
+	}
+
+	for _, test := range tests {
+		_, err := MediaLinkPrefix(test.storageService, test.container)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected error containing '%s' for container '%s', got %v", test.expected, test.container, err)
+		}
+	}
+}
+
+func TestNewMediaLink(t *testing.T) {
+	storageService := newTestStorageService("http://account.blob.core.windows.net/")
+
+	actual, err := NewMediaLink(storageService, "vhds", "disks/my disk.vhd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "https://account.blob.core.windows.net/vhds/disks/my%20disk.vhd"; actual != expected {
+		t.Fatalf("Wrong media link. Expected: '%s', got: '%s'", expected, actual)
+	}
+
+	for _, blobName := range []string{"", "disk.", "disk/", strings.Repeat("a", 1025)} {
+		if _, err := NewMediaLink(storageService, "vhds", blobName); err == nil || !strings.Contains(err.Error(), "Invalid blob name") {
+			t.Errorf("Expected an invalid blob name error for '%s', got %v", blobName, err)
+		}
+	}
+}
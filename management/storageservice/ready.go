@@ -0,0 +1,85 @@
+package storageservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	//StatusCreated is the Status of a storage account that finished
+	//provisioning.
+	StatusCreated = "Created"
+
+	statusDeleting = "Deleting"
+
+	errAccountDeleting = "Storage service is being deleted"
+)
+
+//ErrNotReady is returned by WaitForStorageAccountReady when the storage account
+//did not reach the Created status.
+type ErrNotReady struct {
+	ServiceName string
+
+	// Status is the last status of the account seen by the wait.
+	Status string
+	Err    error
+}
+
+func (e *ErrNotReady) Error() string {
+	return fmt.Sprintf("Storage service %s is not ready (status %s): %s", e.ServiceName, e.Status, e.Err)
+}
+
+func (e *ErrNotReady) Unwrap() error {
+	return e.Err
+}
+
+//WaitForStorageAccountReady polls the storage account until its Status is
+//Created, which can take minutes after the creation operation completed while
+//it is Creating or ResolvingDns, and returns it. It checks every pollInterval,
+//or management.DefaultPollInterval if pollInterval is zero, until ctx is done;
+//use context.WithTimeout to bound the wait. The requests polling the account
+//are bound to ctx too, so that a hung request does not outlast it. If ctx is
+//done first, or the account is being deleted, the error is an *ErrNotReady.
+func (self StorageServiceClient) WaitForStorageAccountReady(ctx context.Context, name string, pollInterval time.Duration) (*StorageService, error) {
+	if name == "" {
+		return nil, wrapError("WaitForStorageAccountReady", name, fmt.Errorf(errParamNotSpecified, "name"))
+	}
+	if pollInterval <= 0 {
+		pollInterval = management.DefaultPollInterval
+	}
+
+	bound := StorageServiceClient{client: management.BindContext(self.client, ctx)}
+	var status string
+	for {
+		if ctx.Err() != nil {
+			return nil, wrapError("WaitForStorageAccountReady", name, &ErrNotReady{ServiceName: name, Status: status, Err: ctx.Err()})
+		}
+
+		storageService, err := bound.GetStorageServiceByName(name)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = &ErrNotReady{ServiceName: name, Status: status, Err: ctx.Err()}
+			}
+			return nil, wrapError("WaitForStorageAccountReady", name, err)
+		}
+
+		status = storageService.StorageServiceProperties.Status
+		switch status {
+		case StatusCreated:
+			return storageService, nil
+		case statusDeleting:
+			return nil, wrapError("WaitForStorageAccountReady", name, &ErrNotReady{ServiceName: name, Status: status, Err: errors.New(errAccountDeleting)})
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+}
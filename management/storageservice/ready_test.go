@@ -0,0 +1,81 @@
+package storageservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForStorageAccountReady(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "account", StorageServiceProperties: StorageServiceProperties{Status: "ResolvingDns"}})
+	client := newTestClient(t, server)
+
+	time.AfterFunc(30*time.Millisecond, func() {
+		server.addService(StorageService{ServiceName: "account", StorageServiceProperties: StorageServiceProperties{Status: StatusCreated}})
+	})
+
+	storageService, err := client.WaitForStorageAccountReady(context.Background(), "account", 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storageService.StorageServiceProperties.Status != StatusCreated {
+		t.Fatalf("Expected a created account, got %+v", storageService)
+	}
+	if count := server.countRequests("GET", "services/storageservices/account"); count < 2 {
+		t.Errorf("Expected the account to be polled until created, got %d requests", count)
+	}
+}
+
+func TestWaitForStorageAccountReadyHonorsContext(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.addService(StorageService{ServiceName: "account", StorageServiceProperties: StorageServiceProperties{Status: "Creating"}})
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitForStorageAccountReady(ctx, "account", 5*time.Millisecond)
+	var notReadyErr *ErrNotReady
+	if !errors.As(err, &notReadyErr) || notReadyErr.Status != "Creating" {
+		t.Fatalf("Expected an ErrNotReady with status Creating, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the error to wrap the context error, got %v", err)
+	}
+}
+
+func TestWaitForStorageAccountReadyCancelsHungRequest(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var requests int32
+	server := &fakeServer{Server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))}
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.WaitForStorageAccountReady(ctx, "account", 5*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the hung request to be cancelled with the context, returned after %v", elapsed)
+	}
+	var notReadyErr *ErrNotReady
+	if !errors.As(err, &notReadyErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected an ErrNotReady wrapping the context error, got %v", err)
+	}
+	if count := atomic.LoadInt32(&requests); count != 1 {
+		t.Errorf("Expected no request after the context was done, got %d requests", count)
+	}
+}
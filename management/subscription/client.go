@@ -0,0 +1,66 @@
+package subscription
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	apiVersion = "2014-05-01"
+
+	errParamNotSpecified = "Parameter %s is not specified."
+	errInsufficientQuota = "Subscription %s does not have enough %s left: %d needed, %d available"
+)
+
+//NewClient is used to instantiate a new SubscriptionClient from an Azure client
+func NewClient(client management.Client) SubscriptionClient {
+	return NewClientFromManagementClient(&client)
+}
+
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) SubscriptionClient {
+	return SubscriptionClient{client: management.RequireAPIVersion(client, apiVersion)}
+}
+
+//GetSubscription returns the subscription of the client, with its limits and
+//current usage.
+//See https://msdn.microsoft.com/en-us/library/azure/hh403995.aspx
+func (self SubscriptionClient) GetSubscription() (*Subscription, error) {
+	response, err := self.client.SendAzureGetRequest(management.SubscriptionURL)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := new(Subscription)
+	err = xml.Unmarshal(response, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+//CheckQuota returns an error if the subscription does not have the given
+//numbers of cores, storage accounts and hosted services available, so that
+//tools can fail before provisioning part of a deployment.
+func (self SubscriptionClient) CheckQuota(cores, storageAccounts, hostedServices int) error {
+	subscription, err := self.GetSubscription()
+	if err != nil {
+		return err
+	}
+
+	if available := subscription.AvailableCores(); cores > available {
+		return fmt.Errorf(errInsufficientQuota, subscription.SubscriptionID, "cores", cores, available)
+	}
+	if available := subscription.AvailableStorageAccounts(); storageAccounts > available {
+		return fmt.Errorf(errInsufficientQuota, subscription.SubscriptionID, "storage accounts", storageAccounts, available)
+	}
+	if available := subscription.AvailableHostedServices(); hostedServices > available {
+		return fmt.Errorf(errInsufficientQuota, subscription.SubscriptionID, "hosted services", hostedServices, available)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package subscription
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+)
+
+const subscriptionXML = `<Subscription xmlns="http://schemas.microsoft.com/windowsazure">
+  <SubscriptionID>subscriptionID</SubscriptionID>
+  <SubscriptionName>Production</SubscriptionName>
+  <SubscriptionStatus>Active</SubscriptionStatus>
+  <AccountAdminLiveEmailId>admin@example.com</AccountAdminLiveEmailId>
+  <ServiceAdminLiveEmailId>admin@example.com</ServiceAdminLiveEmailId>
+  <MaxCoreCount>20</MaxCoreCount>
+  <MaxStorageAccounts>100</MaxStorageAccounts>
+  <MaxHostedServices>20</MaxHostedServices>
+  <CurrentCoreCount>18</CurrentCoreCount>
+  <CurrentHostedServices>3</CurrentHostedServices>
+  <CurrentStorageAccounts>100</CurrentStorageAccounts>
+</Subscription>`
+
+func TestGetSubscription(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("GET", management.SubscriptionURL, subscriptionXML)
+
+	subscription, err := NewClientFromManagementClient(client).GetSubscription()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subscription.SubscriptionName != "Production" || subscription.AccountAdminLiveEmailId != "admin@example.com" {
+		t.Errorf("Unexpected subscription %+v", subscription)
+	}
+	if subscription.MaxCoreCount != 20 || subscription.CurrentCoreCount != 18 || subscription.MaxStorageAccounts != 100 || subscription.MaxHostedServices != 20 {
+		t.Errorf("Unexpected limits %+v", subscription)
+	}
+	if cores, storageAccounts, hostedServices := subscription.AvailableCores(), subscription.AvailableStorageAccounts(), subscription.AvailableHostedServices(); cores != 2 || storageAccounts != 0 || hostedServices != 17 {
+		t.Errorf("Unexpected availability: %d cores, %d storage accounts, %d hosted services", cores, storageAccounts, hostedServices)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	client := fake.NewClient()
+	client.AddResponse("GET", management.SubscriptionURL, subscriptionXML)
+	subscriptionClient := NewClientFromManagementClient(client)
+
+	if err := subscriptionClient.CheckQuota(2, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	err := subscriptionClient.CheckQuota(4, 0, 1)
+	if err == nil || !strings.Contains(err.Error(), "4 needed, 2 available") {
+		t.Errorf("Expected a quota error for the cores, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package subscription
+
+import (
+	"encoding/xml"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+//SubscriptionClient is used to query the subscription of an Azure client.
+//It is safe for concurrent use by multiple goroutines.
+type SubscriptionClient struct {
+	client management.ManagementClient
+}
+
+//Subscription describes a subscription, with its limits and how much of them
+//is in use.
+type Subscription struct {
+	XMLName                    xml.Name `xml:"Subscription"`
+	SubscriptionID             string
+	SubscriptionName           string
+	SubscriptionStatus         string
+	AccountAdminLiveEmailId    string
+	ServiceAdminLiveEmailId    string
+	MaxCoreCount               int
+	MaxStorageAccounts         int
+	MaxHostedServices          int
+	CurrentCoreCount           int
+	CurrentHostedServices      int
+	CurrentStorageAccounts     int
+	MaxVirtualNetworkSites     int
+	CurrentVirtualNetworkSites int
+	MaxLocalNetworkSites       int
+	MaxDnsServers              int
+	AADTenantID                string
+	CreatedTime                string
+}
+
+//AvailableCores returns the number of cores that can still be allocated to
+//the virtual machines and roles of the subscription.
+func (subscription Subscription) AvailableCores() int {
+	return available(subscription.MaxCoreCount, subscription.CurrentCoreCount)
+}
+
+//AvailableStorageAccounts returns the number of storage accounts that can
+//still be created in the subscription.
+func (subscription Subscription) AvailableStorageAccounts() int {
+	return available(subscription.MaxStorageAccounts, subscription.CurrentStorageAccounts)
+}
+
+//AvailableHostedServices returns the number of hosted services that can
+//still be created in the subscription.
+func (subscription Subscription) AvailableHostedServices() int {
+	return available(subscription.MaxHostedServices, subscription.CurrentHostedServices)
+}
+
+func available(max, current int) int {
+	if current >= max {
+		return 0
+	}
+	return max - current
+}
+
+//SubscriptionOperationList is a page of the operations performed on a
+//subscription.
+type SubscriptionOperationList struct {
+	XMLName           xml.Name                `xml:"SubscriptionOperationCollection"`
+	Operations        []SubscriptionOperation `xml:"SubscriptionOperations>SubscriptionOperation"`
+	ContinuationToken string
+}
+
+//SubscriptionOperation describes an operation performed on a subscription:
+//what was done, to which object, and by whom.
+type SubscriptionOperation struct {
+	OperationId            string
+	OperationObjectId      string
+	OperationName          string
+	OperationParameters    []OperationParameter `xml:"OperationParameters>OperationParameter"`
+	OperationCaller        OperationCaller
+	OperationStatus        OperationStatus
+	OperationStartedTime   string
+	OperationCompletedTime string
+	OperationKind          string
+}
+
+//OperationParameter is a parameter of a SubscriptionOperation. Value holds the
+//raw value, which may be an XML document.
+type OperationParameter struct {
+	Name  string
+	Value string
+}
+
+//OperationCaller identifies who performed a SubscriptionOperation.
+type OperationCaller struct {
+	UsedServiceManagementApi          bool
+	UserEmailAddress                  string
+	SubscriptionCertificateThumbprint string
+	ClientIP                          string
+}
+
+//OperationStatus is the status of a SubscriptionOperation.
+type OperationStatus struct {
+	ID             string
+	Status         string
+	HttpStatusCode string
+}
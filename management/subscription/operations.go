@@ -0,0 +1,120 @@
+package subscription
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+const (
+	azureOperationListURL = "operations"
+
+	//Values of ListOperationsParams.OperationResultFilter.
+	OperationResultSucceeded  = "Succeeded"
+	OperationResultFailed     = "Failed"
+	OperationResultInProgress = "InProgress"
+)
+
+//ListOperationsParams selects the operations returned by ListOperations.
+type ListOperationsParams struct {
+	//StartTime and EndTime bound the time the operations were started. Both
+	//are required; the service keeps 90 days of history.
+	StartTime time.Time
+	EndTime   time.Time
+
+	//ObjectIdFilter, if set, keeps the operations performed on one object,
+	//identified by its URL path, such as the path
+	///<subscription-id>/services/hostedservices/<service-name> of a hosted
+	//service.
+	ObjectIdFilter string
+
+	//OperationResultFilter, if set, keeps the operations with the given
+	//result, such as OperationResultFailed.
+	OperationResultFilter string
+}
+
+//ListOperations returns an iterator over the operations performed on the
+//subscription, for auditing who created or deleted resources. The pages of
+//the list are fetched as the iterator advances.
+//See https://msdn.microsoft.com/en-us/library/azure/gg715318.aspx
+func (self SubscriptionClient) ListOperations(params ListOperationsParams) *OperationIterator {
+	if params.StartTime.IsZero() {
+		return &OperationIterator{err: fmt.Errorf(errParamNotSpecified, "StartTime")}
+	}
+	if params.EndTime.IsZero() {
+		return &OperationIterator{err: fmt.Errorf(errParamNotSpecified, "EndTime")}
+	}
+
+	requestURL := management.NewURLBuilder(azureOperationListURL).
+		Query("StartTime", params.StartTime.UTC().Format(time.RFC3339)).
+		Query("EndTime", params.EndTime.UTC().Format(time.RFC3339))
+	if params.ObjectIdFilter != "" {
+		requestURL.Query("ObjectIdFilter", params.ObjectIdFilter)
+	}
+	if params.OperationResultFilter != "" {
+		requestURL.Query("OperationResultFilter", params.OperationResultFilter)
+	}
+
+	return &OperationIterator{pager: management.NewPager(self.client, requestURL.String())}
+}
+
+//GetOperationList returns all the operations selected by params. Use
+//ListOperations to avoid holding all of them in memory.
+func (self SubscriptionClient) GetOperationList(params ListOperationsParams) ([]SubscriptionOperation, error) {
+	var operationList []SubscriptionOperation
+
+	operations := self.ListOperations(params)
+	for operations.Next() {
+		operationList = append(operationList, operations.Value())
+	}
+	if err := operations.Err(); err != nil {
+		return nil, err
+	}
+
+	return operationList, nil
+}
+
+//OperationIterator iterates over subscription operations. Call Next before
+//each Value, and Err once Next returns false.
+type OperationIterator struct {
+	pager      *management.Pager
+	operations []SubscriptionOperation
+	index      int
+	err        error
+}
+
+//Next advances to the next operation, fetching the next page if needed. It
+//returns false at the end of the list or on error.
+func (iterator *OperationIterator) Next() bool {
+	for iterator.index >= len(iterator.operations) {
+		if iterator.err != nil || !iterator.pager.Next() {
+			return false
+		}
+
+		page := SubscriptionOperationList{}
+		if err := xml.Unmarshal(iterator.pager.Page(), &page); err != nil {
+			iterator.err = err
+			return false
+		}
+		iterator.operations, iterator.index = page.Operations, 0
+	}
+
+	iterator.index++
+	return true
+}
+
+//Value returns the current operation.
+func (iterator *OperationIterator) Value() SubscriptionOperation {
+	return iterator.operations[iterator.index-1]
+}
+
+//Err returns the error that stopped Next, if any.
+func (iterator *OperationIterator) Err() error {
+	if iterator.err != nil {
+		return iterator.err
+	}
+
+	return iterator.pager.Err()
+}
@@ -0,0 +1,70 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management/fake"
+)
+
+func TestListOperationsFollowsPages(t *testing.T) {
+	baseURL := "operations?EndTime=2015-03-02T00%3A00%3A00Z&ObjectIdFilter=%2FsubscriptionID%2Fservices%2Fhostedservices%2Fmyservice&OperationResultFilter=Succeeded&StartTime=2015-03-01T00%3A00%3A00Z"
+	client := fake.NewClient()
+	client.AddResponse("GET", baseURL, `<SubscriptionOperationCollection xmlns="http://schemas.microsoft.com/windowsazure">
+  <SubscriptionOperations>
+    <SubscriptionOperation>
+      <OperationId>operation-1</OperationId>
+      <OperationObjectId>/subscriptionID/services/hostedservices/myservice</OperationObjectId>
+      <OperationName>CreateHostedService</OperationName>
+      <OperationParameters>
+        <OperationParameter><Name>serviceName</Name><Value>myservice</Value></OperationParameter>
+      </OperationParameters>
+      <OperationCaller>
+        <UsedServiceManagementApi>true</UsedServiceManagementApi>
+        <SubscriptionCertificateThumbprint>THUMBPRINT</SubscriptionCertificateThumbprint>
+        <ClientIP>10.0.0.1</ClientIP>
+      </OperationCaller>
+      <OperationStatus><ID>operation-1</ID><Status>Succeeded</Status><HttpStatusCode>200</HttpStatusCode></OperationStatus>
+    </SubscriptionOperation>
+  </SubscriptionOperations>
+  <ContinuationToken>token</ContinuationToken>
+</SubscriptionOperationCollection>`)
+	client.AddResponse("GET", baseURL+"&ContinuationToken=token", `<SubscriptionOperationCollection xmlns="http://schemas.microsoft.com/windowsazure">
+  <SubscriptionOperations>
+    <SubscriptionOperation><OperationId>operation-2</OperationId><OperationName>DeleteHostedService</OperationName></SubscriptionOperation>
+  </SubscriptionOperations>
+</SubscriptionOperationCollection>`)
+
+	operations, err := NewClientFromManagementClient(client).GetOperationList(ListOperationsParams{
+		StartTime:             time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:               time.Date(2015, 3, 2, 0, 0, 0, 0, time.UTC),
+		ObjectIdFilter:        "/subscriptionID/services/hostedservices/myservice",
+		OperationResultFilter: OperationResultSucceeded,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(operations) != 2 || operations[0].OperationId != "operation-1" || operations[1].OperationName != "DeleteHostedService" {
+		t.Fatalf("Unexpected operations %+v", operations)
+	}
+	first := operations[0]
+	if first.OperationCaller.ClientIP != "10.0.0.1" || !first.OperationCaller.UsedServiceManagementApi || first.OperationStatus.Status != "Succeeded" {
+		t.Errorf("Unexpected caller or status %+v", first)
+	}
+	if len(first.OperationParameters) != 1 || first.OperationParameters[0].Value != "myservice" {
+		t.Errorf("Unexpected parameters %+v", first.OperationParameters)
+	}
+}
+
+func TestListOperationsRequiresTimeRange(t *testing.T) {
+	client := fake.NewClient()
+
+	_, err := NewClientFromManagementClient(client).GetOperationList(ListOperationsParams{StartTime: time.Now()})
+	if err == nil {
+		t.Fatal("Expected an error for the missing EndTime")
+	}
+	if requests := client.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no request to be sent, got %v", requests)
+	}
+}
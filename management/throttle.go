@@ -0,0 +1,125 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statusTooManyRequests = 429
+	retryAfterHeader      = "Retry-After"
+
+	defaultMaxThrottleRetries = 5
+	defaultMaxThrottleWait    = 2 * time.Minute
+
+	// defaultThrottleDelay is used when a throttled response does not say
+	// how long to wait and the retry policy has no backoff.
+	defaultThrottleDelay = time.Second
+)
+
+// ThrottledError is returned when the service kept throttling a request,
+// with status 429 or 503, after the throttling budget of the RetryPolicy was
+// spent. Err is the error of the last response.
+type ThrottledError struct {
+	StatusCode int
+
+	// RetryAfter is the delay the service asked for in its last response,
+	// or zero if it did not send a Retry-After header.
+	RetryAfter time.Duration
+
+	// Retries is the number of times the request was retried after being
+	// throttled, and Waited the total time spent waiting before them.
+	Retries int
+	Waited  time.Duration
+
+	Err error
+}
+
+// Error implements the error interface for the ThrottledError type.
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("Request throttled by Azure (status %d) after %d retries over %s: %s", e.StatusCode, e.Retries, e.Waited, e.Err)
+}
+
+// Unwrap returns the error of the last throttled response.
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottled reports whether err, or any error it wraps, is a
+// ThrottledError, or an error response with a throttling status, such as the
+// ones returned when RetryPolicy.MaxThrottleRetries disables the retries of
+// throttled requests.
+func IsThrottled(err error) bool {
+	var throttledErr *ThrottledError
+	return errors.As(err, &throttledErr) || isThrottled(HTTPStatusCode(err))
+}
+
+func isThrottled(statusCode int) bool {
+	return statusCode == statusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// throttleState tracks the throttling retries of a single request.
+type throttleState struct {
+	retries int
+	waited  time.Duration
+}
+
+// next returns how long to wait before retrying a throttled request, or a
+// *ThrottledError if the throttling budget of policy is spent.
+func (state *throttleState) next(policy RetryPolicy, statusCode int, header http.Header, err error) (time.Duration, error) {
+	delay, ok := parseRetryAfter(header.Get(retryAfterHeader), time.Now())
+	throttledErr := &ThrottledError{
+		StatusCode: statusCode,
+		Retries:    state.retries,
+		Waited:     state.waited,
+		Err:        err,
+	}
+	if ok {
+		throttledErr.RetryAfter = delay
+	} else {
+		delay = policy.backoff(state.retries + 1)
+		if delay <= 0 {
+			delay = defaultThrottleDelay
+		}
+	}
+
+	if state.retries >= policy.maxThrottleRetries() {
+		return 0, throttledErr
+	}
+	if maxWait := policy.maxThrottleWait(); maxWait > 0 && state.waited+delay > maxWait {
+		return 0, throttledErr
+	}
+
+	state.retries++
+	state.waited += delay
+	return delay, nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date, into the delay it asks for.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
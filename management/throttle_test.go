@@ -0,0 +1,89 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestThrottledRequestsAreRetriedWithoutSpendingAttempts(t *testing.T) {
+	var requests int64
+	server := newThrottlingServer(3, 429, "0", &requests)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxAttempts: 1}})
+
+	if _, err := client.SendAzureGetRequest("locations"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 4 {
+		t.Errorf("Expected 3 throttled requests and a successful one, got %d requests", requests)
+	}
+}
+
+func TestThrottledErrorWhenBudgetIsSpent(t *testing.T) {
+	var requests int64
+	server := newThrottlingServer(100, http.StatusServiceUnavailable, "0", &requests)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxThrottleRetries: 2}})
+
+	_, err := client.SendAzureGetRequest("locations")
+	var throttledErr *ThrottledError
+	if !errors.As(err, &throttledErr) || !IsThrottled(err) {
+		t.Fatalf("Expected a ThrottledError, got %v", err)
+	}
+	if throttledErr.StatusCode != http.StatusServiceUnavailable || throttledErr.Retries != 2 {
+		t.Errorf("Expected 2 retries of status 503, got %+v", throttledErr)
+	}
+	var azureErr *AzureError
+	if !errors.As(err, &azureErr) || azureErr.Code != "InternalError" {
+		t.Errorf("Expected the ThrottledError to wrap the last AzureError, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestThrottleWaitBudget(t *testing.T) {
+	var requests int64
+	server := newThrottlingServer(100, 429, "60", &requests)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{RetryPolicy: &RetryPolicy{MaxThrottleWait: 30 * time.Second}})
+
+	start := time.Now()
+	_, err := client.SendAzureGetRequest("locations")
+	var throttledErr *ThrottledError
+	if !errors.As(err, &throttledErr) || throttledErr.RetryAfter != time.Minute {
+		t.Fatalf("Expected a ThrottledError asking for a minute, got %v", err)
+	}
+	if requests != 1 || time.Since(start) > time.Second {
+		t.Errorf("Expected the request not to be retried past the wait budget")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"Thu, 01 Jan 2015 12:00:30 GMT", 30 * time.Second, true},
+		{"Thu, 01 Jan 2015 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.value, now)
+		if delay != test.expected || ok != test.ok {
+			t.Errorf("%q: expected %v, %v, got %v, %v", test.value, test.expected, test.ok, delay, ok)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package management
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	// DefaultDialTimeout is the maximum time the client waits for a
+	// connection to be established when ClientConfig.DialTimeout is zero.
+	DefaultDialTimeout = 30 * time.Second
+
+	// DefaultTLSHandshakeTimeout is the maximum time the client waits for
+	// the TLS handshake of a connection when ClientConfig.TLSHandshakeTimeout
+	// is zero.
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+
+	// DefaultResponseHeaderTimeout is the maximum time the client waits for
+	// the headers of a response once a request is sent when
+	// ClientConfig.ResponseHeaderTimeout is zero. The management API answers
+	// quickly even to the requests starting long-running operations, whose
+	// completion is waited on by WaitAsyncOperation instead.
+	DefaultResponseHeaderTimeout = 2 * time.Minute
+
+	// NoTimeout disables a timeout of ClientConfig that has a default.
+	NoTimeout time.Duration = -1
+
+	// keepAlive is the keep-alive period of the connections of the client,
+	// the one of http.DefaultTransport.
+	keepAlive = 30 * time.Second
+)
+
+// transportTimeout returns the timeout to configure the transport with for
+// the configured one: defaultTimeout if it is zero, and none if it is
+// negative.
+func transportTimeout(configured time.Duration, defaultTimeout time.Duration) time.Duration {
+	switch {
+	case configured == 0:
+		return defaultTimeout
+	case configured < 0:
+		return 0
+	default:
+		return configured
+	}
+}
+
+// newDialer returns the dialer of the transport of the client.
+func newDialer(config ClientConfig) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   transportTimeout(config.DialTimeout, DefaultDialTimeout),
+		KeepAlive: keepAlive,
+	}
+}
@@ -0,0 +1,75 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransportTimeout(t *testing.T) {
+	tests := []struct {
+		configured time.Duration
+		expected   time.Duration
+	}{
+		{0, DefaultResponseHeaderTimeout},
+		{NoTimeout, 0},
+		{time.Second, time.Second},
+	}
+
+	for _, test := range tests {
+		if actual := transportTimeout(test.configured, DefaultResponseHeaderTimeout); actual != test.expected {
+			t.Errorf("Wrong timeout for %s. Expected: %s, got: %s", test.configured, test.expected, actual)
+		}
+	}
+}
+
+// newHangingServer returns a server that hangs until release is closed, after
+// sending the headers of its response if headers is true.
+func newHangingServer(release chan struct{}, headers bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if headers {
+			w.Header().Set("Content-Length", "10")
+			w.Write([]byte("<"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+func TestClientTimesOutHungRequests(t *testing.T) {
+	tests := map[string]struct {
+		headers bool
+		config  ClientConfig
+	}{
+		"ResponseHeaderTimeout": {false, ClientConfig{ResponseHeaderTimeout: 50 * time.Millisecond}},
+		"RequestTimeout":        {true, ClientConfig{RequestTimeout: 50 * time.Millisecond}},
+	}
+
+	for name, test := range tests {
+		release := make(chan struct{})
+		server := newHangingServer(release, test.headers)
+
+		test.config.RetryPolicy = &RetryPolicy{MaxAttempts: 1}
+		client := newTestClient(t, server.URL, test.config)
+		start := time.Now()
+		_, err := client.SendAzureGetRequest("locations")
+		elapsed := time.Since(start)
+
+		close(release)
+		server.Close()
+
+		if err == nil {
+			t.Fatalf("%s: expected the request to time out", name)
+		}
+		if !IsTransientNetworkError(err) {
+			t.Errorf("%s: expected a retryable timeout, got %v", name, err)
+		}
+		if elapsed > 5*time.Second {
+			t.Errorf("%s: expected the request to fail quickly, took %s", name, elapsed)
+		}
+	}
+}
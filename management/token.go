@@ -0,0 +1,195 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before its expiry a cached token is
+// replaced, so that it does not expire while a request is in flight.
+const tokenRefreshMargin = 5 * time.Minute
+
+// Token is an Azure Active Directory access token.
+type Token struct {
+	AccessToken string
+	ExpiresOn   time.Time
+}
+
+// TokenSource provides the bearer tokens that authenticate the requests of a
+// client created with NewClientFromTokenSource. Token is called for every
+// request, from multiple goroutines, so implementations should cache tokens;
+// NewClientSecretTokenSource does.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenError is returned when Azure Active Directory refuses to issue a
+// token.
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Error implements the error interface for the TokenError type.
+func (e *TokenError) Error() string {
+	return fmt.Sprintf("Azure Active Directory refused to issue a token (HTTP %d). Code: %s, Description: %s", e.StatusCode, e.Code, e.Description)
+}
+
+// clientSecretTokenSource acquires tokens with the OAuth 2.0 client
+// credentials grant of a service principal.
+type clientSecretTokenSource struct {
+	tokenURL   string
+	form       url.Values
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token Token
+}
+
+// NewClientSecretTokenSource returns a TokenSource for the service principal
+// with the given client ID and secret in the Azure Active Directory tenant
+// tenantID, issuing tokens for the management API of env. Tokens are cached
+// until shortly before they expire.
+func NewClientSecretTokenSource(env Environment, tenantID, clientID, clientSecret string) (TokenSource, error) {
+	if env == (Environment{}) {
+		env = PublicCloud
+	}
+	if env.ActiveDirectoryEndpoint == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "env.ActiveDirectoryEndpoint")
+	}
+	if tenantID == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "tenantID")
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "clientID")
+	}
+	if clientSecret == "" {
+		return nil, fmt.Errorf(errParamNotSpecified, "clientSecret")
+	}
+
+	return &clientSecretTokenSource{
+		tokenURL: fmt.Sprintf("%s/%s/oauth2/token", strings.TrimSuffix(env.ActiveDirectoryEndpoint, "/"), url.PathEscape(tenantID)),
+		form: url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {clientSecret},
+			"resource":      {strings.TrimSuffix(env.ManagementURL, "/") + "/"},
+		},
+		httpClient: &http.Client{Transport: &http.Transport{Proxy: ProxyFromEnvironment}},
+	}, nil
+}
+
+// Token implements the TokenSource interface.
+func (source *clientSecretTokenSource) Token(ctx context.Context) (Token, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	if source.token.AccessToken != "" && time.Now().Add(tokenRefreshMargin).Before(source.token.ExpiresOn) {
+		return source.token, nil
+	}
+
+	token, err := source.acquire(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+
+	source.token = token
+	return token, nil
+}
+
+// acquire requests a new token from Azure Active Directory.
+func (source *clientSecretTokenSource) acquire(ctx context.Context) (Token, error) {
+	if err := ctx.Err(); err != nil {
+		return Token{}, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", source.tokenURL, strings.NewReader(source.form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	requested := time.Now()
+	response, err := source.httpClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Token{}, ctx.Err()
+		}
+		return Token{}, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{StatusCode: response.StatusCode}
+		json.Unmarshal(body, tokenErr)
+		return Token{}, tokenErr
+	}
+
+	var tokenResponse struct {
+		AccessToken string      `json:"access_token"`
+		TokenType   string      `json:"token_type"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	err = json.Unmarshal(body, &tokenResponse)
+	if err != nil {
+		return Token{}, err
+	}
+	if tokenResponse.AccessToken == "" {
+		return Token{}, errors.New("azure: token response has no access token")
+	}
+	if tokenResponse.TokenType != "" && !strings.EqualFold(tokenResponse.TokenType, "Bearer") {
+		return Token{}, fmt.Errorf("azure: unsupported token type %q", tokenResponse.TokenType)
+	}
+
+	expiresIn, err := tokenResponse.ExpiresIn.Int64()
+	if err != nil {
+		return Token{}, fmt.Errorf("azure: invalid token lifetime %q", tokenResponse.ExpiresIn)
+	}
+
+	return Token{
+		AccessToken: tokenResponse.AccessToken,
+		ExpiresOn:   requested.Add(time.Duration(expiresIn) * time.Second),
+	}, nil
+}
+
+// NewClientFromTokenSource creates a new Client for the given subscription
+// that authenticates its requests with bearer tokens from tokens instead of a
+// management certificate. The management endpoint is that of
+// config.Environment unless config.ManagementURL is set.
+func NewClientFromTokenSource(subscriptionID string, tokens TokenSource, config ClientConfig) (Client, error) {
+	if tokens == nil {
+		return Client{}, fmt.Errorf(errParamNotSpecified, "tokens")
+	}
+
+	return makeClient(subscriptionID, nil, tokens, config)
+}
+
+// authorize sets the Authorization header of request if the client
+// authenticates with tokens.
+func (client *Client) authorize(ctx context.Context, request *http.Request) error {
+	if client.tokens == nil {
+		return nil
+	}
+
+	token, err := client.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
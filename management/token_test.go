@@ -0,0 +1,121 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTokenServer issues the access token "token-N" for the Nth request with
+// the expected client credentials, valid for expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn string, requests *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(requests, 1)
+		if r.URL.Path != "/tenant/oauth2/token" {
+			t.Errorf("Unexpected token request path %s", r.URL.Path)
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_id") != "app" || r.FormValue("resource") != "https://management.core.windows.net/" {
+			t.Errorf("Unexpected token request form %v", r.Form)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS70002: Invalid client secret."}`))
+			return
+		}
+		w.Write([]byte(`{"token_type":"Bearer","expires_in":"` + expiresIn + `","access_token":"token-` + string(rune('0'+n)) + `"}`))
+	}))
+}
+
+func newTokenEnvironment(tokenServerURL string) Environment {
+	env := PublicCloud
+	env.ActiveDirectoryEndpoint = tokenServerURL
+	return env
+}
+
+func TestClientFromTokenSourceSendsBearerToken(t *testing.T) {
+	var tokenRequests int64
+	tokenServer := newTokenServer(t, "3599", &tokenRequests)
+	defer tokenServer.Close()
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	tokens, err := NewClientSecretTokenSource(newTokenEnvironment(tokenServer.URL), "tenant", "app", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientFromTokenSource("subscriptionID", tokens, ClientConfig{ManagementURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendAzureGetRequest("locations"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(authorizations) != 2 || authorizations[0] != "Bearer token-1" || authorizations[1] != "Bearer token-1" {
+		t.Errorf("Expected both requests to carry the cached token, got %v", authorizations)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected 1 token request, got %d", tokenRequests)
+	}
+}
+
+func TestClientSecretTokenSourceRefreshesExpiringTokens(t *testing.T) {
+	var tokenRequests int64
+	tokenServer := newTokenServer(t, "60", &tokenRequests)
+	defer tokenServer.Close()
+
+	tokens, err := NewClientSecretTokenSource(newTokenEnvironment(tokenServer.URL), "tenant", "app", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewAnonymousClient()
+	first, err := tokens.Token(client.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := tokens.Token(client.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.AccessToken != "token-1" || second.AccessToken != "token-2" {
+		t.Errorf("Expected a token expiring within the refresh margin to be replaced, got %s and %s", first.AccessToken, second.AccessToken)
+	}
+}
+
+func TestClientSecretTokenSourceReportsRefusal(t *testing.T) {
+	var tokenRequests int64
+	tokenServer := newTokenServer(t, "3599", &tokenRequests)
+	defer tokenServer.Close()
+
+	tokens, err := NewClientSecretTokenSource(newTokenEnvironment(tokenServer.URL), "tenant", "app", "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientFromTokenSource("subscriptionID", tokens, ClientConfig{ManagementURL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.SendAzureGetRequest("locations")
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.StatusCode != http.StatusUnauthorized || tokenErr.Code != "invalid_client" {
+		t.Errorf("Expected a TokenError for the invalid client, got %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected the refused token not to be retried, got %d token requests", tokenRequests)
+	}
+}
@@ -0,0 +1,142 @@
+package management
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// debugEnvironmentVariable turns on wire tracing to standard error for
+// clients that do not set ClientConfig.TraceWriter.
+const debugEnvironmentVariable = "AZURE_SDK_DEBUG"
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are the headers whose values are never traced.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+}
+
+// sensitiveElements are the XML elements of request and response bodies
+// whose content is never traced, because they hold certificates, keys or
+// passwords.
+var sensitiveElements = []string{
+	"Data",
+	"Password",
+	"UserPassword",
+	"AdminPassword",
+	"Primary",
+	"Secondary",
+	"PrimaryKey",
+	"SecondaryKey",
+	"ManagementCertificate",
+	"CertificateData",
+	"PrivateConfigurationValue",
+	"CustomData",
+}
+
+var sensitiveElementPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(sensitiveElements))
+	for i, element := range sensitiveElements {
+		patterns[i] = regexp.MustCompile(`(?s)(<` + element + `(?:\s[^>]*)?>).*?(</` + element + `>)`)
+	}
+	return patterns
+}()
+
+// tracer dumps requests and responses, with their secrets redacted, to a
+// writer. It is shared by all copies of a Client and is safe for concurrent
+// use.
+type tracer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newTracer returns a tracer writing to w, or to standard error if w is nil
+// and the AZURE_SDK_DEBUG environment variable is set to a value other than
+// "0" or "false". Otherwise it returns nil, which traces nothing.
+func newTracer(w io.Writer) *tracer {
+	if w == nil {
+		switch strings.ToLower(os.Getenv(debugEnvironmentVariable)) {
+		case "", "0", "false":
+			return nil
+		}
+		w = os.Stderr
+	}
+
+	return &tracer{w: w}
+}
+
+func (t *tracer) traceRequest(request *http.Request, body []byte) {
+	if t == nil {
+		return
+	}
+
+	var dump bytes.Buffer
+	fmt.Fprintf(&dump, "> %s %s\n", request.Method, request.URL)
+	writeHeaders(&dump, "> ", request.Header)
+	writeBody(&dump, body)
+	t.write(dump.Bytes())
+}
+
+func (t *tracer) traceResponse(request *http.Request, response *http.Response, body []byte, streamed bool) {
+	if t == nil {
+		return
+	}
+
+	var dump bytes.Buffer
+	fmt.Fprintf(&dump, "< %s %s: %s\n", request.Method, request.URL, response.Status)
+	writeHeaders(&dump, "< ", response.Header)
+	if streamed {
+		dump.WriteString("(streamed body not traced)\n\n")
+	} else {
+		writeBody(&dump, body)
+	}
+	t.write(dump.Bytes())
+}
+
+func (t *tracer) write(dump []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(dump)
+}
+
+func writeHeaders(dump *bytes.Buffer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			fmt.Fprintf(dump, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+func writeBody(dump *bytes.Buffer, body []byte) {
+	dump.WriteString("\n")
+	if len(body) > 0 {
+		dump.Write(redactBody(body))
+		dump.WriteString("\n")
+	}
+	dump.WriteString("\n")
+}
+
+// redactBody replaces the content of the sensitive elements of body.
+func redactBody(body []byte) []byte {
+	for _, pattern := range sensitiveElementPatterns {
+		body = pattern.ReplaceAll(body, []byte("${1}"+redacted+"${2}"))
+	}
+	return body
+}
@@ -0,0 +1,71 @@
+package management
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceRedactsSecrets(t *testing.T) {
+	responseBody := `<StorageServiceKeys><StorageServiceKeys><Primary>cHJpbWFyeQ==</Primary><Secondary>c2Vjb25kYXJ5</Secondary></StorageServiceKeys></StorageServiceKeys>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-request-id", "request-1")
+		w.Header().Set("Content-Length", fmt.Sprint(len(responseBody)))
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	var trace bytes.Buffer
+	client := newTestClient(t, server.URL, ClientConfig{
+		TraceWriter: &trace,
+		RequestHooks: []RequestHook{func(request *http.Request) error {
+			request.Header.Set("Authorization", "Bearer token")
+			return nil
+		}},
+	})
+
+	requestBody := `<CertificateFile><Data>c2VjcmV0</Data><CertificateFormat>pfx</CertificateFormat><Password>hunter2</Password></CertificateFile>`
+	if _, err := client.SendAzurePostRequest("services/hostedservices/service/certificates", []byte(requestBody)); err != nil {
+		t.Fatal(err)
+	}
+
+	dump := trace.String()
+	for _, secret := range []string{"c2VjcmV0", "hunter2", "Bearer token", "cHJpbWFyeQ==", "c2Vjb25kYXJ5"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Expected %q to be redacted from the trace:\n%s", secret, dump)
+		}
+	}
+	for _, expected := range []string{
+		"> POST " + server.URL + "/subscriptionID/services/hostedservices/service/certificates\n",
+		"> X-Ms-Version: " + msVersionHeaderValue + "\n",
+		"> Authorization: REDACTED\n",
+		"<Data>REDACTED</Data><CertificateFormat>pfx</CertificateFormat><Password>REDACTED</Password>",
+		"< POST " + server.URL + "/subscriptionID/services/hostedservices/service/certificates: 200 OK\n",
+		"< X-Ms-Request-Id: request-1\n",
+		"<Primary>REDACTED</Primary><Secondary>REDACTED</Secondary>",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected the trace to contain %q:\n%s", expected, dump)
+		}
+	}
+}
+
+func TestTraceEnvironmentVariable(t *testing.T) {
+	t.Setenv(debugEnvironmentVariable, "")
+	if newTracer(nil) != nil {
+		t.Errorf("Expected tracing to be off by default")
+	}
+
+	t.Setenv(debugEnvironmentVariable, "false")
+	if newTracer(nil) != nil {
+		t.Errorf("Expected AZURE_SDK_DEBUG=false to leave tracing off")
+	}
+
+	t.Setenv(debugEnvironmentVariable, "1")
+	if newTracer(nil) == nil {
+		t.Errorf("Expected AZURE_SDK_DEBUG=1 to turn tracing on")
+	}
+}
@@ -0,0 +1,68 @@
+package management
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Values of the comp query parameter selecting an operation on a resource.
+const (
+	CompMedia       = "media"
+	CompUpdateLbSet = "UpdateLbSet"
+)
+
+// URLBuilder builds the URL of a management API request, relative to the
+// subscription, from a path template and the names of the resources it
+// addresses. Names are escaped, so that reserved characters in them cannot
+// change the path or start a query:
+//
+//	requestURL := management.NewURLBuilder("services/hostedservices/%s/deployments/%s", service, deployment).
+//		Comp(management.CompMedia).
+//		String()
+type URLBuilder struct {
+	path  string
+	query url.Values
+}
+
+// NewURLBuilder returns a URLBuilder for the path template, a URL template as
+// for RouteTable, formatted with the escaped names.
+func NewURLBuilder(template string, names ...string) *URLBuilder {
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+
+	return &URLBuilder{path: FormatRoute(template, args...), query: url.Values{}}
+}
+
+// Query adds the query parameter name with value. Both are escaped.
+func (builder *URLBuilder) Query(name, value string) *URLBuilder {
+	builder.query.Add(name, value)
+	return builder
+}
+
+// Comp adds the comp query parameter, which selects an operation on the
+// resource, such as CompMedia to delete a resource with its disks.
+func (builder *URLBuilder) Comp(operation string) *URLBuilder {
+	return builder.Query("comp", operation)
+}
+
+// EmbedDetail adds the embed-detail query parameter, which asks for the
+// details of the children of a resource, such as the deployments of a hosted
+// service, to be included in the response.
+func (builder *URLBuilder) EmbedDetail() *URLBuilder {
+	return builder.Query("embed-detail", "true")
+}
+
+// String returns the URL, for the Send methods of ManagementClient.
+func (builder *URLBuilder) String() string {
+	if len(builder.query) == 0 {
+		return builder.path
+	}
+
+	separator := "?"
+	if strings.Contains(builder.path, "?") {
+		separator = "&"
+	}
+	return builder.path + separator + builder.query.Encode()
+}
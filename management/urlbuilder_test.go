@@ -0,0 +1,46 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLBuilder(t *testing.T) {
+	tests := []struct {
+		builder  *URLBuilder
+		expected string
+	}{
+		{NewURLBuilder("services/hostedservices"), "services/hostedservices"},
+		{NewURLBuilder("services/hostedservices/%s", "my service"), "services/hostedservices/my%20service"},
+		{NewURLBuilder("services/hostedservices/%s/deployments/%s", "a/b", "c?d"), "services/hostedservices/a%2Fb/deployments/c%3Fd"},
+		{NewURLBuilder("services/hostedservices/%s", "service").Comp(CompMedia), "services/hostedservices/service?comp=media"},
+		{NewURLBuilder("services/hostedservices/%s", "service").EmbedDetail(), "services/hostedservices/service?embed-detail=true"},
+		{NewURLBuilder("services/disks/%s", "disk").Comp(CompMedia).Query("note", "a&b"), "services/disks/disk?comp=media&note=a%26b"},
+		{NewURLBuilder("services/images?location=%s", "West US").Query("category", "Public"), "services/images?location=West%20US&category=Public"},
+	}
+
+	for _, test := range tests {
+		if actual := test.builder.String(); actual != test.expected {
+			t.Errorf("Wrong URL. Expected: '%s', got: '%s'", test.expected, actual)
+		}
+	}
+}
+
+func TestURLBuilderNamesReachServerIntact(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	if _, err := client.SendAzureGetRequest(NewURLBuilder("services/disks/%s", "a/b c").String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "/subscriptionID/services/disks/a%2Fb%20c"; path != expected {
+		t.Errorf("Expected the request to %s, got %s", expected, path)
+	}
+}
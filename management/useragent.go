@@ -0,0 +1,40 @@
+package management
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// SDKVersion is the version of this SDK, reported in the User-Agent header of
+// every request.
+const SDKVersion = "0.1.0"
+
+const userAgentHeader = "User-Agent"
+
+// DefaultUserAgent is the User-Agent sent by clients that were not given
+// extensions with ClientConfig.UserAgent or AddToUserAgent.
+var DefaultUserAgent = fmt.Sprintf("Azure-SDK-For-Go/%s (%s; %s/%s)", SDKVersion, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+// UserAgent returns the User-Agent the client sends.
+func (client *Client) UserAgent() string {
+	if client.userAgent == "" {
+		return DefaultUserAgent
+	}
+
+	return client.userAgent
+}
+
+// AddToUserAgent appends extension, for example "docker-machine/0.2", to the
+// User-Agent sent by the client and the copies made of it afterwards, so that
+// the requests of tools built on the SDK can be told apart by Azure support.
+// It must not be called while the client is in use by other goroutines.
+func (client *Client) AddToUserAgent(extension string) error {
+	extension = strings.TrimSpace(extension)
+	if extension == "" {
+		return fmt.Errorf(errParamNotSpecified, "extension")
+	}
+
+	client.userAgent = client.UserAgent() + " " + extension
+	return nil
+}
@@ -0,0 +1,46 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Length", "12")
+		w.Write([]byte("<Locations/>"))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, ClientConfig{})
+	configured := newTestClient(t, server.URL, ClientConfig{UserAgent: "docker-machine/0.2"})
+	extended := configured
+	if err := extended.AddToUserAgent("azure-driver/1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := extended.AddToUserAgent(" "); err == nil {
+		t.Errorf("Expected an empty extension to be rejected")
+	}
+
+	for _, client := range []Client{client, configured, extended} {
+		if _, err := client.SendAzureGetRequest("locations"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		DefaultUserAgent,
+		DefaultUserAgent + " docker-machine/0.2",
+		DefaultUserAgent + " docker-machine/0.2 azure-driver/1.0",
+	}
+	if strings.Join(userAgents, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected user agents %q, got %q", expected, userAgents)
+	}
+	if !strings.HasPrefix(DefaultUserAgent, "Azure-SDK-For-Go/"+SDKVersion+" ") {
+		t.Errorf("Expected the default user agent to carry the SDK version, got %s", DefaultUserAgent)
+	}
+}
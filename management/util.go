@@ -2,14 +2,23 @@ package management
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
-	"github.com/MSOpenTech/azure-sdk-for-go/core/http"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
 	"os/exec"
 	"strings"
 )
 
 func executeCommand(command string, input []byte) ([]byte, error) {
+	return executeCommandWithEnv(command, nil, input)
+}
+
+//executeCommandWithEnv runs command with the given variables added to its
+//environment.
+func executeCommandWithEnv(command string, env []string, input []byte) ([]byte, error) {
 	if command == "" {
 		return nil, fmt.Errorf(errParamNotSpecified, "command")
 	}
@@ -19,6 +28,9 @@ func executeCommand(command string, input []byte) ([]byte, error) {
 	parts = parts[1:len(parts)]
 
 	cmd := exec.Command(head, parts...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	if input != nil {
 		cmd.Stdin = bytes.NewReader(input)
 	}
@@ -31,8 +43,46 @@ func executeCommand(command string, input []byte) ([]byte, error) {
 	return out, nil
 }
 
-func getResponseBody(response *http.Response) []byte {
-	responseBody := make([]byte, response.ContentLength)
-	io.ReadFull(response.Body, responseBody)
-	return responseBody
+//getResponseBody reads the whole body of response, whether or not its length
+//is known in advance.
+func getResponseBody(response *http.Response) ([]byte, error) {
+	return ioutil.ReadAll(response.Body)
+}
+
+//decompressResponse makes the body of response read decompressed if the
+//service compressed it with gzip. The transport of the http package does so
+//itself only for the requests to which it added Accept-Encoding.
+func decompressResponse(response *http.Response) error {
+	if !strings.EqualFold(response.Header.Get(contentEncodingHeader), "gzip") {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(response.Body)
+	if err == io.EOF {
+		// An empty body, as sent with many error responses.
+		reader, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if reader != nil {
+		response.Body = &gzipBody{Reader: reader, body: response.Body}
+	}
+	response.Header.Del(contentEncodingHeader)
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return nil
+}
+
+//gzipBody is the decompressed body of a response.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (body *gzipBody) Close() error {
+	body.Reader.Close()
+	return body.body.Close()
 }
@@ -0,0 +1,123 @@
+// Package validation checks the names and labels of Azure resources against
+// the rules of the Service Management API, so that invalid values are
+// reported with a descriptive error before a request is sent, rather than
+// rejected by the service with a generic 400 Bad Request.
+package validation
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management/label"
+)
+
+const (
+	errEmpty       = "must not be empty"
+	errLength      = "must be between %d and %d characters long"
+	errMaxLength   = "must be at most %d characters long"
+	errLowerAlnum  = "may only contain lowercase letters and numbers"
+	errDNSChars    = "may only contain letters, numbers and hyphens"
+	errDNSStart    = "must start with a letter"
+	errDNSEnd      = "must end with a letter or a number"
+	errInvalidRune = "must be valid UTF-8"
+)
+
+// Error reports a name or label rejected by one of the checks of the package.
+type Error struct {
+	// Kind is what was checked, for example "storage account name".
+	Kind   string
+	Value  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Invalid %s '%s': %s.", e.Kind, e.Value, e.Reason)
+}
+
+// StorageAccountName checks the name of a storage account: 3 to 24
+// lowercase letters and numbers. The name is the DNS prefix of the endpoints
+// of the account.
+func StorageAccountName(name string) error {
+	const kind = "storage account name"
+
+	if err := checkLength(kind, name, 3, 24); err != nil {
+		return err
+	}
+	for _, r := range name {
+		if !isLower(r) && !isDigit(r) {
+			return &Error{Kind: kind, Value: name, Reason: errLowerAlnum}
+		}
+	}
+
+	return nil
+}
+
+// CloudServiceName checks the name of a cloud (hosted) service, which is also
+// the DNS prefix of its cloudapp.net domain: 1 to 63 letters, numbers and
+// hyphens, starting with a letter and ending with a letter or a number.
+func CloudServiceName(name string) error {
+	return dnsLabel("cloud service name", name, 63)
+}
+
+// RoleName checks the name of a role, such as the virtual machine of a
+// deployment: 1 to 64 letters, numbers and hyphens, starting with a letter and
+// ending with a letter or a number.
+func RoleName(name string) error {
+	return dnsLabel("role name", name, 64)
+}
+
+// Label checks the label of a resource, such as a deployment or a storage
+// account, before it is encoded: at most label.MaxLength characters. An empty
+// label is valid; the operations creating resources default it to the name of
+// the resource.
+func Label(value string) error {
+	const kind = "label"
+
+	if !utf8.ValidString(value) {
+		return &Error{Kind: kind, Value: value, Reason: errInvalidRune}
+	}
+	if utf8.RuneCountInString(value) > label.MaxLength {
+		return &Error{Kind: kind, Value: value, Reason: fmt.Sprintf(errMaxLength, label.MaxLength)}
+	}
+
+	return nil
+}
+
+// dnsLabel checks a name that is used as a DNS label.
+func dnsLabel(kind, name string, maxLength int) error {
+	if err := checkLength(kind, name, 1, maxLength); err != nil {
+		return err
+	}
+	for _, r := range name {
+		if !isLower(r) && !isUpper(r) && !isDigit(r) && r != '-' {
+			return &Error{Kind: kind, Value: name, Reason: errDNSChars}
+		}
+	}
+
+	first, last := rune(name[0]), rune(name[len(name)-1])
+	if !isLower(first) && !isUpper(first) {
+		return &Error{Kind: kind, Value: name, Reason: errDNSStart}
+	}
+	if last == '-' {
+		return &Error{Kind: kind, Value: name, Reason: errDNSEnd}
+	}
+
+	return nil
+}
+
+func checkLength(kind, value string, minLength, maxLength int) error {
+	if value == "" {
+		return &Error{Kind: kind, Value: value, Reason: errEmpty}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if length < minLength || length > maxLength {
+		return &Error{Kind: kind, Value: value, Reason: fmt.Sprintf(errLength, minLength, maxLength)}
+	}
+
+	return nil
+}
+
+func isLower(r rune) bool { return 'a' <= r && r <= 'z' }
+func isUpper(r rune) bool { return 'A' <= r && r <= 'Z' }
+func isDigit(r rune) bool { return '0' <= r && r <= '9' }
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStorageAccountName(t *testing.T) {
+	valid := []string{"abc", "mystorage01", strings.Repeat("a", 24)}
+	invalid := map[string]string{
+		"":                      errEmpty,
+		"ab":                    "between 3 and 24",
+		strings.Repeat("a", 25): "between 3 and 24",
+		"MyStorage":             errLowerAlnum,
+		"my-storage":            errLowerAlnum,
+		"my_storage":            errLowerAlnum,
+	}
+
+	for _, name := range valid {
+		if err := StorageAccountName(name); err != nil {
+			t.Fatalf("Expected '%s' to be valid, got: %v", name, err)
+		}
+	}
+	for name, reason := range invalid {
+		checkInvalid(t, StorageAccountName(name), "storage account name", reason)
+	}
+}
+
+func TestCloudServiceName(t *testing.T) {
+	valid := []string{"a", "MyService", "my-service-2", strings.Repeat("a", 63)}
+	invalid := map[string]string{
+		"":                      errEmpty,
+		strings.Repeat("a", 64): "between 1 and 63",
+		"my_service":            errDNSChars,
+		"my.service":            errDNSChars,
+		"2service":              errDNSStart,
+		"-service":              errDNSStart,
+		"service-":              errDNSEnd,
+	}
+
+	for _, name := range valid {
+		if err := CloudServiceName(name); err != nil {
+			t.Fatalf("Expected '%s' to be valid, got: %v", name, err)
+		}
+	}
+	for name, reason := range invalid {
+		checkInvalid(t, CloudServiceName(name), "cloud service name", reason)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	for _, value := range []string{"", "my deployment", strings.Repeat("ü", 100)} {
+		if err := Label(value); err != nil {
+			t.Fatalf("Expected '%s' to be valid, got: %v", value, err)
+		}
+	}
+
+	checkInvalid(t, Label(strings.Repeat("a", 101)), "label", "at most 100")
+	checkInvalid(t, Label("\xff"), "label", errInvalidRune)
+}
+
+func checkInvalid(t *testing.T, err error, kind, reason string) {
+	t.Helper()
+
+	var validationErr *Error
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a *validation.Error, got: %v", err)
+	}
+	if validationErr.Kind != kind {
+		t.Fatalf("Unexpected kind. Expected: '%s', got: '%s'", kind, validationErr.Kind)
+	}
+	if !strings.Contains(err.Error(), reason) {
+		t.Fatalf("Expected the error to mention '%s', got: %v", reason, err)
+	}
+}
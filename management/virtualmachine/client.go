@@ -20,17 +20,17 @@ import (
 	hostedserviceclient "github.com/MSOpenTech/azure-sdk-for-go/management/hostedservice"
 	locationclient "github.com/MSOpenTech/azure-sdk-for-go/management/location"
 	storageserviceclient "github.com/MSOpenTech/azure-sdk-for-go/management/storageservice"
+	"github.com/MSOpenTech/azure-sdk-for-go/management/validation"
 	imageclient "github.com/MSOpenTech/azure-sdk-for-go/management/virtualmachineimage"
 )
 
 const (
+	apiVersion                        = "2014-05-01"
 	azureXmlns                        = "http://schemas.microsoft.com/windowsazure"
 	azureDeploymentListURL            = "services/hostedservices/%s/deployments"
 	azureHostedServiceListURL         = "services/hostedservices"
-	deleteAzureHostedServiceURL       = "services/hostedservices/%s?comp=media"
 	azureHostedServiceAvailabilityURL = "services/hostedservices/operations/isavailable/%s"
 	azureDeploymentURL                = "services/hostedservices/%s/deployments/%s"
-	deleteAzureDeploymentURL          = "services/hostedservices/%s/deployments/%s?comp=media"
 	azureRoleURL                      = "services/hostedservices/%s/deployments/%s/roles/%s"
 	azureOperationsURL                = "services/hostedservices/%s/deployments/%s/roleinstances/%s/Operations"
 	azureCertificatListURL            = "services/hostedservices/%s/certificates"
@@ -40,6 +40,11 @@ const (
 	osWindows                 = "Windows"
 	dockerPublicConfigVersion = 2
 
+	probeProtocolHttp    = "http"
+	probeProtocolTcp     = "tcp"
+	defaultProbeInterval = 15
+	defaultProbeTimeout  = 31
+
 	errParamNotSpecified            = "Parameter %s is not specified."
 	errProvisioningConfDoesNotExist = "You should set azure VM provisioning config first"
 	errInvalidCertExtension         = "Certificate %s is invalid. Please specify %s certificate."
@@ -49,14 +54,24 @@ const (
 	errInvalidRoleSize              = "Invalid role size: %s. Available role sizes: %s"
 	errInvalidRoleSizeInLocation    = "Role size: %s not available in location: %s."
 	errInvalidDnsLength             = "The DNS name must be between 3 and 25 characters."
+	errInvalidProbeProtocol         = "Invalid probe protocol for endpoint %s: %s. Valid values are 'http' and 'tcp'"
+	errProbePathNotSpecified        = "The http probe of endpoint %s must specify a path."
+	errProbePathNotAllowed          = "The tcp probe of endpoint %s must not specify a path."
+	errInvalidProbeTiming           = "The probe timeout of endpoint %s (%ds) must be greater than its interval (%ds)."
 )
 
 //NewClient is used to instantiate a new VmClient from an Azure client
 func NewClient(client management.Client) VirtualMachineClient {
-	return VirtualMachineClient{client: client}
+	return NewClientFromManagementClient(&client)
+}
+
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) VirtualMachineClient {
+	return VirtualMachineClient{client: management.RequireAPIVersion(client, apiVersion)}
 }
 
-func (self VirtualMachineClient) CreateAzureVM(azureVMConfiguration *Role, dnsName, location string) error {
+func (self VirtualMachineClient) CreateAzureVM(azureVMConfiguration *Role, dnsName, location string, options ...management.WaitOption) error {
 	if azureVMConfiguration == nil {
 		return fmt.Errorf(errParamNotSpecified, "azureVMConfiguration")
 	}
@@ -66,22 +81,29 @@ func (self VirtualMachineClient) CreateAzureVM(azureVMConfiguration *Role, dnsNa
 	if location == "" {
 		return fmt.Errorf(errParamNotSpecified, "location")
 	}
+	for _, configurationSet := range azureVMConfiguration.ConfigurationSets.ConfigurationSet {
+		for _, endpoint := range configurationSet.InputEndpoints.InputEndpoint {
+			if err := self.verifyInputEndpoint(endpoint); err != nil {
+				return err
+			}
+		}
+	}
 
-	hostedServiceClient := hostedserviceclient.NewClient(self.client)
+	hostedServiceClient := hostedserviceclient.NewClientFromManagementClient(self.client)
 	requestId, err := hostedServiceClient.CreateHostedService(dnsName, location, "", dnsName, "")
 	if err != nil {
 		return err
 	}
 
-	err = self.client.WaitAsyncOperation(requestId)
+	err = self.client.WaitAsyncOperation(requestId, options...)
 	if err != nil {
 		return err
 	}
 
 	if azureVMConfiguration.UseCertAuth {
-		err = self.uploadServiceCert(dnsName, azureVMConfiguration.CertPath)
+		err = self.uploadServiceCert(dnsName, azureVMConfiguration.CertPath, options...)
 		if err != nil {
-			hostedServiceClient.DeleteHostedService(dnsName)
+			hostedServiceClient.DeleteHostedService(dnsName, options...)
 			return err
 		}
 	}
@@ -89,18 +111,18 @@ func (self VirtualMachineClient) CreateAzureVM(azureVMConfiguration *Role, dnsNa
 	vMDeployment := self.createVMDeploymentConfig(azureVMConfiguration)
 	vMDeploymentBytes, err := xml.Marshal(vMDeployment)
 	if err != nil {
-		hostedServiceClient.DeleteHostedService(dnsName)
+		hostedServiceClient.DeleteHostedService(dnsName, options...)
 		return err
 	}
 
-	requestURL := fmt.Sprintf(azureDeploymentListURL, azureVMConfiguration.RoleName)
+	requestURL := management.NewURLBuilder(azureDeploymentListURL, azureVMConfiguration.RoleName).String()
 	requestId, err = self.client.SendAzurePostRequest(requestURL, vMDeploymentBytes)
 	if err != nil {
-		hostedServiceClient.DeleteHostedService(dnsName)
+		hostedServiceClient.DeleteHostedService(dnsName, options...)
 		return err
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
 func (self VirtualMachineClient) CreateAzureVMConfiguration(dnsName, instanceSize, imageName, location string) (*Role, error) {
@@ -116,8 +138,11 @@ func (self VirtualMachineClient) CreateAzureVMConfiguration(dnsName, instanceSiz
 	if location == "" {
 		return nil, fmt.Errorf(errParamNotSpecified, "location")
 	}
+	if err := validation.CloudServiceName(dnsName); err != nil {
+		return nil, err
+	}
 
-	locationClient := locationclient.NewClient(self.client)
+	locationClient := locationclient.NewClientFromManagementClient(self.client)
 	locationInfo, err := locationClient.GetLocation(location)
 	if err != nil {
 		return nil, err
@@ -255,21 +280,22 @@ func (self VirtualMachineClient) GetVMDeployment(cloudserviceName, deploymentNam
 
 	deployment := new(VMDeployment)
 
-	requestURL := fmt.Sprintf(azureDeploymentURL, cloudserviceName, deploymentName)
-	response, azureErr := self.client.SendAzureGetRequest(requestURL)
+	requestURL := management.NewURLBuilder(azureDeploymentURL, cloudserviceName, deploymentName).String()
+	response, azureErr := self.client.SendAzureRequest(requestURL, "GET", "", nil)
 	if azureErr != nil {
 		return nil, azureErr
 	}
 
-	err := xml.Unmarshal(response, deployment)
+	err := xml.Unmarshal(response.Body, deployment)
 	if err != nil {
 		return nil, err
 	}
+	deployment.ETag = response.ETag()
 
 	return deployment, nil
 }
 
-func (self VirtualMachineClient) DeleteVMDeployment(cloudserviceName, deploymentName string) error {
+func (self VirtualMachineClient) DeleteVMDeployment(cloudserviceName, deploymentName string, options ...management.WaitOption) error {
 	if cloudserviceName == "" {
 		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
 	}
@@ -277,18 +303,8 @@ func (self VirtualMachineClient) DeleteVMDeployment(cloudserviceName, deployment
 		return fmt.Errorf(errParamNotSpecified, "deploymentName")
 	}
 
-	requestURL := fmt.Sprintf(deleteAzureDeploymentURL, cloudserviceName, deploymentName)
-	requestId, err := self.client.SendAzureDeleteRequest(requestURL)
-	if err != nil {
-		return err
-	}
-
-	err = self.client.WaitAsyncOperation(requestId)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	requestURL := management.NewURLBuilder(azureDeploymentURL, cloudserviceName, deploymentName).Comp(management.CompMedia).String()
+	return self.client.SendAzureDeleteRequestAndWait(requestURL, options...)
 }
 
 func (self VirtualMachineClient) GetRole(cloudserviceName, deploymentName, roleName string) (*Role, error) {
@@ -304,7 +320,7 @@ func (self VirtualMachineClient) GetRole(cloudserviceName, deploymentName, roleN
 
 	role := new(Role)
 
-	requestURL := fmt.Sprintf(azureRoleURL, cloudserviceName, deploymentName, roleName)
+	requestURL := management.NewURLBuilder(azureRoleURL, cloudserviceName, deploymentName, roleName).String()
 	response, azureErr := self.client.SendAzureGetRequest(requestURL)
 	if azureErr != nil {
 		return nil, azureErr
@@ -318,7 +334,7 @@ func (self VirtualMachineClient) GetRole(cloudserviceName, deploymentName, roleN
 	return role, nil
 }
 
-func (self VirtualMachineClient) StartRole(cloudserviceName, deploymentName, roleName string) error {
+func (self VirtualMachineClient) StartRole(cloudserviceName, deploymentName, roleName string, options ...management.WaitOption) error {
 	if cloudserviceName == "" {
 		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
 	}
@@ -336,16 +352,16 @@ func (self VirtualMachineClient) StartRole(cloudserviceName, deploymentName, rol
 		return err
 	}
 
-	requestURL := fmt.Sprintf(azureOperationsURL, cloudserviceName, deploymentName, roleName)
+	requestURL := management.NewURLBuilder(azureOperationsURL, cloudserviceName, deploymentName, roleName).String()
 	requestId, azureErr := self.client.SendAzurePostRequest(requestURL, startRoleOperationBytes)
 	if azureErr != nil {
 		return azureErr
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
-func (self VirtualMachineClient) ShutdownRole(cloudserviceName, deploymentName, roleName string) error {
+func (self VirtualMachineClient) ShutdownRole(cloudserviceName, deploymentName, roleName string, options ...management.WaitOption) error {
 	if cloudserviceName == "" {
 		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
 	}
@@ -363,16 +379,16 @@ func (self VirtualMachineClient) ShutdownRole(cloudserviceName, deploymentName,
 		return err
 	}
 
-	requestURL := fmt.Sprintf(azureOperationsURL, cloudserviceName, deploymentName, roleName)
+	requestURL := management.NewURLBuilder(azureOperationsURL, cloudserviceName, deploymentName, roleName).String()
 	requestId, azureErr := self.client.SendAzurePostRequest(requestURL, shutdownRoleOperationBytes)
 	if azureErr != nil {
 		return azureErr
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
-func (self VirtualMachineClient) RestartRole(cloudserviceName, deploymentName, roleName string) error {
+func (self VirtualMachineClient) RestartRole(cloudserviceName, deploymentName, roleName string, options ...management.WaitOption) error {
 	if cloudserviceName == "" {
 		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
 	}
@@ -390,16 +406,16 @@ func (self VirtualMachineClient) RestartRole(cloudserviceName, deploymentName, r
 		return err
 	}
 
-	requestURL := fmt.Sprintf(azureOperationsURL, cloudserviceName, deploymentName, roleName)
+	requestURL := management.NewURLBuilder(azureOperationsURL, cloudserviceName, deploymentName, roleName).String()
 	requestId, azureErr := self.client.SendAzurePostRequest(requestURL, restartRoleOperationBytes)
 	if azureErr != nil {
 		return azureErr
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
-func (self VirtualMachineClient) DeleteRole(cloudserviceName, deploymentName, roleName string) error {
+func (self VirtualMachineClient) DeleteRole(cloudserviceName, deploymentName, roleName string, options ...management.WaitOption) error {
 	if cloudserviceName == "" {
 		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
 	}
@@ -410,29 +426,112 @@ func (self VirtualMachineClient) DeleteRole(cloudserviceName, deploymentName, ro
 		return fmt.Errorf(errParamNotSpecified, "roleName")
 	}
 
-	requestURL := fmt.Sprintf(azureRoleURL, cloudserviceName, deploymentName, roleName)
-	requestId, azureErr := self.client.SendAzureDeleteRequest(requestURL)
+	requestURL := management.NewURLBuilder(azureRoleURL, cloudserviceName, deploymentName, roleName).String()
+	return self.client.SendAzureDeleteRequestAndWait(requestURL, options...)
+}
+
+//UpdateLoadBalancedEndpointSet updates the load-balanced set setName of a
+//deployment in a single operation, instead of updating every role that has an
+//endpoint in the set.
+func (self VirtualMachineClient) UpdateLoadBalancedEndpointSet(cloudserviceName, deploymentName, setName string, endpoint InputEndpoint, options ...management.WaitOption) error {
+	if cloudserviceName == "" {
+		return fmt.Errorf(errParamNotSpecified, "cloudserviceName")
+	}
+	if deploymentName == "" {
+		return fmt.Errorf(errParamNotSpecified, "deploymentName")
+	}
+	if setName == "" {
+		return fmt.Errorf(errParamNotSpecified, "setName")
+	}
+
+	endpoint.LoadBalancedEndpointSetName = setName
+	err := self.verifyInputEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+
+	endpointList := LoadBalancedEndpointList{
+		Xmlns:         azureXmlns,
+		InputEndpoint: []InputEndpoint{endpoint},
+	}
+	endpointListBytes, err := xml.Marshal(endpointList)
+	if err != nil {
+		return err
+	}
+
+	requestURL := management.NewURLBuilder(azureDeploymentURL, cloudserviceName, deploymentName).Comp(management.CompUpdateLbSet).String()
+	requestId, azureErr := self.client.SendAzurePostRequest(requestURL, endpointListBytes)
 	if azureErr != nil {
 		return azureErr
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
+//GetRoleSizeList returns the role sizes available to the subscription. Use
+//ListRoleSizes to avoid holding all of them in memory.
 func (self VirtualMachineClient) GetRoleSizeList() (RoleSizeList, error) {
 	roleSizeList := RoleSizeList{}
 
-	response, err := self.client.SendAzureGetRequest(azureRoleSizeListURL)
-	if err != nil {
+	roleSizes := self.ListRoleSizes()
+	for roleSizes.Next() {
+		roleSizeList.RoleSizes = append(roleSizeList.RoleSizes, roleSizes.Value())
+	}
+	if err := roleSizes.Err(); err != nil {
 		return roleSizeList, err
 	}
 
-	err = xml.Unmarshal(response, &roleSizeList)
-	if err != nil {
-		return roleSizeList, err
+	return roleSizeList, nil
+}
+
+//ListRoleSizes returns an iterator over the role sizes available to the
+//subscription, in the order of the service, which fetches them a page at a
+//time.
+func (self VirtualMachineClient) ListRoleSizes() *RoleSizeIterator {
+	return &RoleSizeIterator{pager: management.NewPager(self.client, azureRoleSizeListURL)}
+}
+
+//RoleSizeIterator iterates over role sizes. Call Next before each Value, and Err
+//once Next returns false.
+type RoleSizeIterator struct {
+	pager     *management.Pager
+	roleSizes []RoleSize
+	index     int
+	err       error
+}
+
+//Next advances to the next role size, fetching the next page if needed. It
+//returns false at the end of the list or on error.
+func (iterator *RoleSizeIterator) Next() bool {
+	for iterator.index >= len(iterator.roleSizes) {
+		if iterator.err != nil || !iterator.pager.Next() {
+			return false
+		}
+
+		page := RoleSizeList{}
+		if err := xml.Unmarshal(iterator.pager.Page(), &page); err != nil {
+			iterator.err = err
+			return false
+		}
+		iterator.roleSizes, iterator.index = page.RoleSizes, 0
+	}
+
+	iterator.index++
+	return true
+}
+
+//Value returns the current role size.
+func (iterator *RoleSizeIterator) Value() RoleSize {
+	return iterator.roleSizes[iterator.index-1]
+}
+
+//Err returns the error that stopped Next, if any.
+func (iterator *RoleSizeIterator) Err() error {
+	if iterator.err != nil {
+		return iterator.err
 	}
 
-	return roleSizeList, err
+	return iterator.pager.Err()
 }
 
 func (self VirtualMachineClient) ResolveRoleSize(roleSizeName string) error {
@@ -541,7 +640,7 @@ func (self VirtualMachineClient) createAzureVMRole(name, instanceSize, imageName
 func (self VirtualMachineClient) createOSVirtualHardDisk(dnsName, imageName, location string) (OSVirtualHardDisk, error) {
 	oSVirtualHardDisk := OSVirtualHardDisk{}
 
-	imageClient := imageclient.NewClient(self.client)
+	imageClient := imageclient.NewClientFromManagementClient(self.client)
 	err := imageClient.ResolveImageName(imageName)
 	if err != nil {
 		return oSVirtualHardDisk, err
@@ -557,14 +656,14 @@ func (self VirtualMachineClient) createOSVirtualHardDisk(dnsName, imageName, loc
 }
 
 func (self VirtualMachineClient) getVHDMediaLink(dnsName, location string) (string, error) {
-	storageServiceClient := storageserviceclient.NewClient(self.client)
+	storageServiceClient := storageserviceclient.NewClientFromManagementClient(self.client)
 
 	storageService, err := storageServiceClient.GetStorageServiceByLocation(location)
-	if err != nil {
+	if err != nil && !management.IsNotFound(err) {
 		return "", err
 	}
 
-	if storageService == nil {
+	if err != nil {
 		uuid, err := newUUID()
 		if err != nil {
 			return "", err
@@ -577,13 +676,8 @@ func (self VirtualMachineClient) getVHDMediaLink(dnsName, location string) (stri
 		}
 	}
 
-	blobEndpoint, err := storageServiceClient.GetBlobEndpoint(storageService)
-	if err != nil {
-		return "", err
-	}
-
-	vhdMediaLink := blobEndpoint + "vhds/" + dnsName + "-" + time.Now().Local().Format("20060102150405") + ".vhd"
-	return vhdMediaLink, nil
+	vhdName := dnsName + "-" + time.Now().Local().Format("20060102150405") + ".vhd"
+	return storageserviceclient.NewMediaLink(storageService, "vhds", vhdName)
 }
 
 func (self VirtualMachineClient) createLinuxProvisioningConfig(dnsName, userName, userPassword, certPath string) (ConfigurationSet, error) {
@@ -618,7 +712,7 @@ func (self VirtualMachineClient) createLinuxProvisioningConfig(dnsName, userName
 	return provisioningConfig, nil
 }
 
-func (self VirtualMachineClient) uploadServiceCert(dnsName, certPath string) error {
+func (self VirtualMachineClient) uploadServiceCert(dnsName, certPath string, options ...management.WaitOption) error {
 	certificateConfig, err := self.createServiceCertDeploymentConf(certPath)
 	if err != nil {
 		return err
@@ -629,13 +723,13 @@ func (self VirtualMachineClient) uploadServiceCert(dnsName, certPath string) err
 		return err
 	}
 
-	requestURL := fmt.Sprintf(azureCertificatListURL, dnsName)
+	requestURL := management.NewURLBuilder(azureCertificatListURL, dnsName).String()
 	requestId, azureErr := self.client.SendAzurePostRequest(requestURL, certificateConfigBytes)
 	if azureErr != nil {
 		return azureErr
 	}
 
-	return self.client.WaitAsyncOperation(requestId)
+	return self.client.WaitAsyncOperation(requestId, options...)
 }
 
 func (self VirtualMachineClient) createServiceCertDeploymentConf(certPath string) (ServiceCertificate, error) {
@@ -751,6 +845,44 @@ next:
 	return nil
 }
 
+func (self VirtualMachineClient) verifyInputEndpoint(endpoint InputEndpoint) error {
+	probe := endpoint.LoadBalancerProbe
+	if probe == nil {
+		return nil
+	}
+
+	switch strings.ToLower(probe.Protocol) {
+	case probeProtocolHttp:
+		if probe.Path == "" {
+			return fmt.Errorf(errProbePathNotSpecified, endpoint.Name)
+		}
+	case probeProtocolTcp:
+		if probe.Path != "" {
+			return fmt.Errorf(errProbePathNotAllowed, endpoint.Name)
+		}
+	default:
+		return fmt.Errorf(errInvalidProbeProtocol, endpoint.Name, probe.Protocol)
+	}
+
+	if probe.Port == 0 {
+		return fmt.Errorf(errParamNotSpecified, "LoadBalancerProbe.Port")
+	}
+
+	//Azure fills in omitted values with its defaults, so check those too
+	interval, timeout := probe.IntervalInSeconds, probe.TimeoutInSeconds
+	if interval == 0 {
+		interval = defaultProbeInterval
+	}
+	if timeout == 0 {
+		timeout = defaultProbeTimeout
+	}
+	if interval >= timeout {
+		return fmt.Errorf(errInvalidProbeTiming, endpoint.Name, timeout, interval)
+	}
+
+	return nil
+}
+
 func (self VirtualMachineClient) isInstanceSizeAvailableInLocation(location *locationclient.Location, instanceSize string) (bool, error) {
 	if instanceSize == "" {
 		return false, fmt.Errorf(errParamNotSpecified, "vmSize")
@@ -0,0 +1,158 @@
+package virtualmachine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MSOpenTech/azure-sdk-for-go/management"
+)
+
+// recordingServer accepts every asynchronous request, reports its
+// operation as succeeded and records what it was sent.
+type recordingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	bodies   []string
+}
+
+func newRecordingServer() *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+
+		if r.Method == "GET" && strings.Contains(r.URL.Path, "/operations/") {
+			response := `<Operation xmlns="http://schemas.microsoft.com/windowsazure"><ID>operation</ID><Status>Succeeded</Status></Operation>`
+			w.Header().Set("Content-Length", fmt.Sprint(len(response)))
+			w.Write([]byte(response))
+			return
+		}
+		w.Header().Set("x-ms-request-id", "operation")
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return s
+}
+
+func newTestClient(t *testing.T, server *recordingServer) VirtualMachineClient {
+	client, err := management.NewClientFromConfig("subscriptionID", []byte("cert"), management.ClientConfig{
+		ManagementURL:       server.URL,
+		DefaultPollInterval: 10 * time.Millisecond,
+		HTTPClient:          &http.Client{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(client)
+}
+
+func TestUpdateLoadBalancedEndpointSet(t *testing.T) {
+	server := newRecordingServer()
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	err := client.UpdateLoadBalancedEndpointSet("service", "deployment", "web", InputEndpoint{
+		LocalPort: 8080,
+		Port:      80,
+		Protocol:  "tcp",
+		LoadBalancerProbe: &LoadBalancerProbe{
+			Path:              "/health",
+			Port:              8080,
+			Protocol:          "http",
+			IntervalInSeconds: 5,
+			TimeoutInSeconds:  11,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRequests := []string{
+		"POST /subscriptionID/services/hostedservices/service/deployments/deployment?comp=UpdateLbSet",
+		"GET /subscriptionID/operations/operation",
+	}
+	if strings.Join(server.requests, "\n") != strings.Join(expectedRequests, "\n") {
+		t.Fatalf("Expected requests\n%s\ngot\n%s", strings.Join(expectedRequests, "\n"), strings.Join(server.requests, "\n"))
+	}
+
+	expectedBody := `<LoadBalancedEndpointList xmlns="http://schemas.microsoft.com/windowsazure">` +
+		`<InputEndpoint>` +
+		`<LoadBalancedEndpointSetName>web</LoadBalancedEndpointSetName>` +
+		`<LocalPort>8080</LocalPort>` +
+		`<Port>80</Port>` +
+		`<LoadBalancerProbe><Path>/health</Path><Port>8080</Port><Protocol>http</Protocol>` +
+		`<IntervalInSeconds>5</IntervalInSeconds><TimeoutInSeconds>11</TimeoutInSeconds></LoadBalancerProbe>` +
+		`<Protocol>tcp</Protocol>` +
+		`</InputEndpoint>` +
+		`</LoadBalancedEndpointList>`
+	if server.bodies[0] != expectedBody {
+		t.Errorf("Expected body\n%s\ngot\n%s", expectedBody, server.bodies[0])
+	}
+}
+
+func TestNetworkConfigurationMarshalsProbe(t *testing.T) {
+	client := VirtualMachineClient{}
+	endpoint := client.createEndpoint("ssh", "tcp", 22, 22)
+	endpoint.LoadBalancedEndpointSetName = "ssh"
+	endpoint.LoadBalancerProbe = &LoadBalancerProbe{Port: 22, Protocol: "tcp"}
+
+	configurationSet := ConfigurationSet{ConfigurationSetType: "NetworkConfiguration"}
+	configurationSet.InputEndpoints.InputEndpoint = []InputEndpoint{endpoint}
+
+	body, err := xml.Marshal(configurationSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<InputEndpoints><InputEndpoint>` +
+		`<LoadBalancedEndpointSetName>ssh</LoadBalancedEndpointSetName>` +
+		`<LocalPort>22</LocalPort><Name>ssh</Name><Port>22</Port>` +
+		`<LoadBalancerProbe><Port>22</Port><Protocol>tcp</Protocol></LoadBalancerProbe>` +
+		`<Protocol>tcp</Protocol>` +
+		`</InputEndpoint></InputEndpoints>`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("Expected configuration set to contain\n%s\ngot\n%s", expected, body)
+	}
+}
+
+func TestVerifyInputEndpoint(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe *LoadBalancerProbe
+		valid bool
+	}{
+		{"no probe", nil, true},
+		{"http probe", &LoadBalancerProbe{Path: "/", Port: 80, Protocol: "http"}, true},
+		{"tcp probe", &LoadBalancerProbe{Port: 80, Protocol: "TCP", IntervalInSeconds: 5, TimeoutInSeconds: 11}, true},
+		{"http probe without path", &LoadBalancerProbe{Port: 80, Protocol: "http"}, false},
+		{"tcp probe with path", &LoadBalancerProbe{Path: "/", Port: 80, Protocol: "tcp"}, false},
+		{"unknown protocol", &LoadBalancerProbe{Port: 80, Protocol: "udp"}, false},
+		{"missing port", &LoadBalancerProbe{Protocol: "tcp"}, false},
+		{"timeout below interval", &LoadBalancerProbe{Port: 80, Protocol: "tcp", IntervalInSeconds: 30, TimeoutInSeconds: 20}, false},
+		{"interval above default timeout", &LoadBalancerProbe{Port: 80, Protocol: "tcp", IntervalInSeconds: 60}, false},
+	}
+
+	client := VirtualMachineClient{}
+	for _, test := range tests {
+		err := client.verifyInputEndpoint(InputEndpoint{Name: "web", LoadBalancerProbe: test.probe})
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
 )
 
-//VmClient is used to manage operations on Azure Virtual Machines
+//VmClient is used to manage operations on Azure Virtual Machines.
+//It is safe for concurrent use by multiple goroutines.
 type VirtualMachineClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 type VMDeployment struct {
@@ -22,6 +23,10 @@ type VMDeployment struct {
 	RoleList         RoleList
 	RoleInstanceList RoleInstanceList `xml:",omitempty"`
 	VirtualIPs       VirtualIPs       `xml:",omitempty"`
+
+	// ETag is the entity tag of the deployment when it was retrieved, if the
+	// service sent one.
+	ETag string `xml:"-"`
 }
 
 type RoleList struct {
@@ -129,12 +134,32 @@ type PublicKey struct {
 	Path        string
 }
 
+//InputEndpoint elements are marshalled in the order the Service Management
+//API expects them, do not reorder the fields
 type InputEndpoint struct {
-	LocalPort int
-	Name      string
-	Port      int
-	Protocol  string
-	Vip       string
+	LoadBalancedEndpointSetName string `xml:",omitempty"`
+	LocalPort                   int
+	Name                        string `xml:",omitempty"`
+	Port                        int
+	LoadBalancerProbe           *LoadBalancerProbe `xml:",omitempty"`
+	Protocol                    string
+	Vip                         string `xml:",omitempty"`
+}
+
+//LoadBalancerProbe is used by the load balancer to decide whether a role
+//instance in a load-balanced set should receive traffic
+type LoadBalancerProbe struct {
+	Path              string `xml:",omitempty"`
+	Port              int
+	Protocol          string
+	IntervalInSeconds int `xml:",omitempty"`
+	TimeoutInSeconds  int `xml:",omitempty"`
+}
+
+type LoadBalancedEndpointList struct {
+	XMLName       xml.Name `xml:"LoadBalancedEndpointList"`
+	Xmlns         string   `xml:"xmlns,attr"`
+	InputEndpoint []InputEndpoint
 }
 
 type ServiceCertificate struct {
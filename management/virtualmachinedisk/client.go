@@ -7,25 +7,27 @@ import (
 )
 
 const (
+	apiVersion           = "2014-05-01"
 	azureVMDiskURL       = "services/disks/%s"
 	errParamNotSpecified = "Parameter %s is not specified."
 )
 
 //NewClient is used to instantiate a new DiskClient from an Azure client
 func NewClient(client management.Client) DiskClient {
-	return DiskClient{client: client}
+	return NewClientFromManagementClient(&client)
 }
 
-func (self DiskClient) DeleteDisk(diskName string) error {
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) DiskClient {
+	return DiskClient{client: management.RequireAPIVersion(client, apiVersion)}
+}
+
+func (self DiskClient) DeleteDisk(diskName string, options ...management.WaitOption) error {
 	if diskName == "" {
 		return fmt.Errorf(errParamNotSpecified, "diskName")
 	}
 
-	requestURL := fmt.Sprintf(azureVMDiskURL, diskName)
-	requestId, err := self.client.SendAzureDeleteRequest(requestURL)
-	if err != nil {
-		return err
-	}
-
-	return self.client.WaitAsyncOperation(requestId)
+	requestURL := management.NewURLBuilder(azureVMDiskURL, diskName).String()
+	return self.client.SendAzureDeleteRequestAndWait(requestURL, options...)
 }
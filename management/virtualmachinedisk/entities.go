@@ -2,7 +2,8 @@ package virtualmachinedisk
 
 import "github.com/MSOpenTech/azure-sdk-for-go/management"
 
-//DiskClient is used to manage operations on Azure Disks
+//DiskClient is used to manage operations on Azure Disks.
+//It is safe for concurrent use by multiple goroutines.
 type DiskClient struct {
-	client management.Client
+	client management.ManagementClient
 }
@@ -4,11 +4,13 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
 )
 
 const (
+	apiVersion           = "2014-05-01"
 	azureImageListURL    = "services/images"
 	errInvalidImage      = "Can not find image %s in specified subscription, please specify another image name."
 	errParamNotSpecified = "Parameter %s is not specified."
@@ -16,23 +18,83 @@ const (
 
 //NewClient is used to instantiate a new ImageClient from an Azure client
 func NewClient(client management.Client) ImageClient {
-	return ImageClient{client: client}
+	return NewClientFromManagementClient(&client)
 }
 
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) ImageClient {
+	return ImageClient{client: management.RequireAPIVersion(client, apiVersion)}
+}
+
+//GetImageList returns the OS images available to the subscription, sorted by
+//name. Use ListImages to avoid holding all of them in memory.
 func (self ImageClient) GetImageList() (ImageList, error) {
 	imageList := ImageList{}
 
-	response, err := self.client.SendAzureGetRequest(azureImageListURL)
-	if err != nil {
+	images := self.ListImages()
+	for images.Next() {
+		imageList.OSImages = append(imageList.OSImages, images.Value())
+	}
+	if err := images.Err(); err != nil {
 		return imageList, err
 	}
 
-	err = xml.Unmarshal(response, &imageList)
-	if err != nil {
-		return imageList, err
+	sort.SliceStable(imageList.OSImages, func(i, j int) bool {
+		return management.Less(imageList.OSImages[i], imageList.OSImages[j])
+	})
+
+	return imageList, nil
+}
+
+//ListImages returns an iterator over the OS images available to the
+//subscription, in the order of the service, which fetches them a page at a
+//time.
+func (self ImageClient) ListImages() *ImageIterator {
+	return &ImageIterator{pager: management.NewPager(self.client, azureImageListURL)}
+}
+
+//ImageIterator iterates over OS images. Call Next before each Value, and Err
+//once Next returns false.
+type ImageIterator struct {
+	pager  *management.Pager
+	images []OSImage
+	index  int
+	err    error
+}
+
+//Next advances to the next image, fetching the next page if needed. It
+//returns false at the end of the list or on error.
+func (iterator *ImageIterator) Next() bool {
+	for iterator.index >= len(iterator.images) {
+		if iterator.err != nil || !iterator.pager.Next() {
+			return false
+		}
+
+		page := ImageList{}
+		if err := xml.Unmarshal(iterator.pager.Page(), &page); err != nil {
+			iterator.err = err
+			return false
+		}
+		iterator.images, iterator.index = page.OSImages, 0
+	}
+
+	iterator.index++
+	return true
+}
+
+//Value returns the current image.
+func (iterator *ImageIterator) Value() OSImage {
+	return iterator.images[iterator.index-1]
+}
+
+//Err returns the error that stopped Next, if any.
+func (iterator *ImageIterator) Err() error {
+	if iterator.err != nil {
+		return iterator.err
 	}
 
-	return imageList, err
+	return iterator.pager.Err()
 }
 
 func (self ImageClient) ResolveImageName(imageName string) error {
@@ -6,9 +6,10 @@ import (
 	"github.com/MSOpenTech/azure-sdk-for-go/management"
 )
 
-//ImageClient is used to manage operations on Azure Locations
+//ImageClient is used to manage operations on Azure Locations.
+//It is safe for concurrent use by multiple goroutines.
 type ImageClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 type ImageList struct {
@@ -27,3 +28,14 @@ type OSImage struct {
 	Description     string
 	Location        string
 }
+
+//SortValue implements management.Sortable.
+func (image OSImage) SortValue(key management.SortKey) string {
+	switch key {
+	case management.SortByName:
+		return image.Name
+	case management.SortByLocation:
+		return image.Location
+	}
+	return ""
+}
@@ -7,12 +7,19 @@ import (
 )
 
 const (
+	apiVersion                   = "2014-05-01"
 	azureNetworkConfigurationURL = "services/networking/media"
 )
 
 //VnetClient is used to return a handle to the VnetClient API
 func NewClient(client management.Client) VirtualNetworkClient {
-	return VirtualNetworkClient{client: client}
+	return NewClientFromManagementClient(&client)
+}
+
+//NewClientFromManagementClient is NewClient for any implementation of
+//management.ManagementClient, such as the one of package fake.
+func NewClientFromManagementClient(client management.ManagementClient) VirtualNetworkClient {
+	return VirtualNetworkClient{client: management.RequireAPIVersion(client, apiVersion)}
 }
 
 //GetVirtualNetworkConfiguration retreives the current virtual network
@@ -21,15 +28,16 @@ func NewClient(client management.Client) VirtualNetworkClient {
 //for running concurrently.
 func (self VirtualNetworkClient) GetVirtualNetworkConfiguration() (NetworkConfiguration, error) {
 	networkConfiguration := self.NewNetworkConfiguration()
-	response, err := self.client.SendAzureGetRequest(azureNetworkConfigurationURL)
+	response, err := self.client.SendAzureRequest(azureNetworkConfigurationURL, "GET", "", nil)
 	if err != nil {
 		return networkConfiguration, err
 	}
 
-	err = xml.Unmarshal(response, &networkConfiguration)
+	err = xml.Unmarshal(response.Body, &networkConfiguration)
 	if err != nil {
 		return networkConfiguration, err
 	}
+	networkConfiguration.ETag = response.ETag()
 
 	return networkConfiguration, nil
 }
@@ -37,19 +45,17 @@ func (self VirtualNetworkClient) GetVirtualNetworkConfiguration() (NetworkConfig
 //SetVirtualNetworkConfiguration configures the virtual networks for the
 //currently active subscription according to the NetworkConfiguration given.
 //Note that the underlying Azure API means that network related operations
-//are not safe for running concurrently.
-func (self VirtualNetworkClient) SetVirtualNetworkConfiguration(networkConfiguration NetworkConfiguration) error {
+//are not safe for running concurrently. If networkConfiguration has the ETag
+//of the configuration it was retrieved as, the update fails, with
+//management.IsPreconditionFailed holding for the error, if the configuration
+//was changed since.
+func (self VirtualNetworkClient) SetVirtualNetworkConfiguration(networkConfiguration NetworkConfiguration, options ...management.WaitOption) error {
 	networkConfiguration.setXmlNamespaces()
 	networkConfigurationBytes, err := xml.Marshal(networkConfiguration)
 	if err != nil {
 		return err
 	}
 
-	requestId, err := self.client.SendAzurePutRequest(azureNetworkConfigurationURL, "text/plain", networkConfigurationBytes)
-	if err != nil {
-		return err
-	}
-
-	err = self.client.WaitAsyncOperation(requestId)
-	return err
+	client := management.IfMatch(self.client, networkConfiguration.ETag)
+	return client.SendAzurePutRequestAndWait(azureNetworkConfigurationURL, "text/plain", networkConfigurationBytes, options...)
 }
@@ -10,9 +10,10 @@ const xmlNamespace = "http://schemas.microsoft.com/ServiceHosting/2011/07/Networ
 const xmlNamespaceXsd = "http://www.w3.org/2001/XMLSchema"
 const xmlNamespaceXsi = "http://www.w3.org/2001/XMLSchema-instance"
 
-//VnetClient is used to manage operations on Azure Virtual Networks
+//VnetClient is used to manage operations on Azure Virtual Networks.
+//It is safe for concurrent use by multiple goroutines.
 type VirtualNetworkClient struct {
-	client management.Client
+	client management.ManagementClient
 }
 
 //NetworkConfiguration represents the network configuration for an entire Azure
@@ -24,6 +25,10 @@ type NetworkConfiguration struct {
 	XmlNamespaceXsi string                      `xml:"xmlns:xsi,attr"`
 	Xmlns           string                      `xml:"xmlns,attr"`
 	Configuration   VirtualNetworkConfiguration `xml:"VirtualNetworkConfiguration"`
+
+	// ETag is the entity tag of the configuration when it was retrieved, if
+	// the service sent one.
+	ETag string `xml:"-"`
 }
 
 //NewNetworkConfiguration creates a new empty NetworkConfiguration structure for
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// accountSASVersion is the storage service version of account SAS tokens,
+// the first version supporting them, independently of the API version of
+// the client.
+const accountSASVersion = "2015-04-05"
+
+// AccountSASOptions are the constraints of an account Shared Access
+// Signature, which grants access to one or more services of the storage
+// account rather than to a single resource. See
+// https://msdn.microsoft.com/en-us/library/azure/mt584140.aspx
+type AccountSASOptions struct {
+	// Services are the letters of the services the signature is valid
+	// for: b(lob), q(ueue), t(able) and f(ile).
+	Services string
+
+	// ResourceTypes are the letters of the types of resources accessible
+	// with the signature: s(ervice), c(ontainer) and o(bject).
+	ResourceTypes string
+
+	// Permissions are the letters of the allowed operations: r(ead),
+	// w(rite), d(elete), l(ist), a(dd), c(reate), u(pdate) and p(rocess).
+	Permissions string
+
+	Start  time.Time
+	Expiry time.Time
+
+	// IP optionally restricts the signature to an IP address or an IP
+	// range such as "168.1.5.60-168.1.5.70".
+	IP string
+
+	// Protocol optionally restricts the signature to "https" or allows
+	// "https,http".
+	Protocol string
+}
+
+// GetAccountSASToken returns the query parameters of an account Shared
+// Access Signature with the given constraints, to be added to the URL of any
+// request to the services and resources it grants access to.
+func (c StorageClient) GetAccountSASToken(options AccountSASOptions) (url.Values, error) {
+	for _, f := range []struct {
+		name, value, allowed string
+	}{
+		{"services", options.Services, "bqtf"},
+		{"resource types", options.ResourceTypes, "sco"},
+		{"permissions", options.Permissions, "rwdlacup"},
+	} {
+		if f.value == "" {
+			return nil, fmt.Errorf("storage: account SAS %s are not specified", f.name)
+		}
+		if i := strings.IndexFunc(f.value, func(r rune) bool { return !strings.ContainsRune(f.allowed, r) }); i >= 0 {
+			return nil, fmt.Errorf("storage: invalid account SAS %s %q: allowed are %q", f.name, f.value, f.allowed)
+		}
+	}
+	if options.Expiry.IsZero() {
+		return nil, errors.New("storage: account SAS expiry time is not specified")
+	}
+
+	var signedStart string
+	if !options.Start.IsZero() {
+		signedStart = options.Start.UTC().Format(time.RFC3339)
+	}
+	signedExpiry := options.Expiry.UTC().Format(time.RFC3339)
+
+	stringToSign := accountSASStringToSign(c.accountName, options.Permissions, options.Services, options.ResourceTypes, signedStart, signedExpiry, options.IP, options.Protocol, accountSASVersion)
+
+	out := url.Values{
+		"sv":  {accountSASVersion},
+		"ss":  {options.Services},
+		"srt": {options.ResourceTypes},
+		"sp":  {options.Permissions},
+		"se":  {signedExpiry},
+		"sig": {c.computeHmac256(stringToSign)},
+	}
+	for k, v := range map[string]string{
+		"st":  signedStart,
+		"sip": options.IP,
+		"spr": options.Protocol,
+	} {
+		if v != "" {
+			out.Set(k, v)
+		}
+	}
+	return out, nil
+}
+
+func accountSASStringToSign(accountName, signedPermissions, signedServices, signedResourceTypes, signedStart, signedExpiry, signedIP, signedProtocol, signedVersion string) string {
+	return fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n", accountName, signedPermissions, signedServices, signedResourceTypes, signedStart, signedExpiry, signedIP, signedProtocol, signedVersion)
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestGetAccountSASTokenOffline(t *testing.T) {
+	cli, err := NewBasicClient("foo", "YmFy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := cli.GetAccountSASToken(AccountSASOptions{
+		Services:      "bq",
+		ResourceTypes: "co",
+		Permissions:   "rl",
+		Expiry:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+		Protocol:      "https",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := hmac.New(sha256.New, []byte("bar"))
+	h.Write([]byte("foo\nrl\nbq\nco\n\n2016-01-01T00:00:00Z\n\nhttps\n2015-04-05\n"))
+	expected := map[string]string{
+		"sv":  "2015-04-05",
+		"ss":  "bq",
+		"srt": "co",
+		"sp":  "rl",
+		"se":  "2016-01-01T00:00:00Z",
+		"spr": "https",
+		"sig": base64.StdEncoding.EncodeToString(h.Sum(nil)),
+	}
+	if len(token) != len(expected) {
+		t.Errorf("Expected %d SAS parameters, got %v", len(expected), token)
+	}
+	for k, v := range expected {
+		if got := token.Get(k); got != v {
+			t.Errorf("Expected SAS parameter %s to be %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestGetAccountSASTokenValidationOffline(t *testing.T) {
+	cli, err := NewBasicClient("foo", "YmFy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := AccountSASOptions{Services: "b", ResourceTypes: "o", Permissions: "r", Expiry: time.Now()}
+
+	for _, tc := range []func(*AccountSASOptions){
+		func(o *AccountSASOptions) { o.Services = "" },
+		func(o *AccountSASOptions) { o.Services = "x" },
+		func(o *AccountSASOptions) { o.ResourceTypes = "b" },
+		func(o *AccountSASOptions) { o.Permissions = "rx" },
+		func(o *AccountSASOptions) { o.Expiry = time.Time{} },
+	} {
+		options := valid
+		tc(&options)
+		if _, err := cli.GetAccountSASToken(options); err == nil {
+			t.Errorf("Expected invalid options %+v to be rejected", options)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"bytes"
+	"context"
+	"crypto/md5"
 	"encoding/base64"
 	"encoding/xml"
 	"errors"
@@ -16,6 +18,38 @@ import (
 
 type BlobStorageClient struct {
 	client StorageClient
+
+	// skipContentMD5 disables computing and validating the Content-MD5 of
+	// the data transferred, see WithoutContentMD5.
+	skipContentMD5 bool
+}
+
+// WithoutContentMD5 returns a copy of the client that does not send the MD5
+// hash of uploaded data nor validate the MD5 hash of downloaded data. By
+// default, the service verifies the hash of every uploaded block and page,
+// and downloads fail with ErrContentMD5Mismatch on corrupt data.
+func (b BlobStorageClient) WithoutContentMD5() *BlobStorageClient {
+	b.skipContentMD5 = true
+	return &b
+}
+
+// setContentMD5 sets the Content-MD5 header of a request sending data,
+// unless disabled.
+func (b BlobStorageClient) setContentMD5(headers map[string]string, data []byte) {
+	if !b.skipContentMD5 {
+		headers["Content-MD5"] = contentMD5(data)
+	}
+}
+
+// verifyContentMD5 wraps the body of a response to fail with
+// ErrContentMD5Mismatch at its end if it does not match the Content-MD5
+// header of the response, unless disabled or there is no such header.
+func (b BlobStorageClient) verifyContentMD5(resp *storageResponse) io.ReadCloser {
+	expected := resp.headers.Get("Content-MD5")
+	if b.skipContentMD5 || expected == "" {
+		return resp.body
+	}
+	return &md5VerifyingReader{body: resp.body, hash: md5.New(), expected: expected}
 }
 
 // A Container is an entry in ContainerListResponse.
@@ -50,7 +84,11 @@ type ContainerListResponse struct {
 
 // A Blob is an entry in BlobListResponse.
 type Blob struct {
-	Name       string         `xml:"Name"`
+	Name string `xml:"Name"`
+
+	// Snapshot identifies the snapshot of the blob the entry is, in
+	// listings including snapshots. It is empty for the blob itself.
+	Snapshot   string         `xml:"Snapshot"`
 	Properties BlobProperties `xml:"Properties"`
 	// TODO (ahmetalpbalkan) Metadata
 }
@@ -64,6 +102,8 @@ type BlobProperties struct {
 	ContentLength         int64    `xml:"Content-Length"`
 	ContentType           string   `xml:"Content-Type"`
 	ContentEncoding       string   `xml:"Content-Encoding"`
+	ContentLanguage       string   `xml:"Content-Language"`
+	CacheControl          string   `xml:"Cache-Control"`
 	BlobType              BlobType `xml:"x-ms-blob-blob-type"`
 	SequenceNumber        int64    `xml:"x-ms-blob-sequence-number"`
 	CopyId                string   `xml:"CopyId"`
@@ -83,7 +123,13 @@ type BlobListResponse struct {
 	Marker     string   `xml:"Marker"`
 	NextMarker string   `xml:"NextMarker"`
 	MaxResults int64    `xml:"MaxResults"`
+	Delimiter  string   `xml:"Delimiter"`
 	Blobs      []Blob   `xml:"Blobs>Blob"`
+
+	// BlobPrefixes are the names of the virtual directories of the
+	// listing, up to and including the delimiter, when it was listed
+	// with a delimiter.
+	BlobPrefixes []string `xml:"Blobs>BlobPrefix>Name"`
 }
 
 // ListContainersParameters defines the set of customizable
@@ -121,9 +167,12 @@ func (p ListContainersParameters) getParameters() url.Values {
 // ListBlobsParameters defines the set of customizable
 // parameters to make a List Blobs call. https://msdn.microsoft.com/en-us/library/azure/dd135734.aspx
 type ListBlobsParameters struct {
-	Prefix     string
-	Delimiter  string
-	Marker     string
+	Prefix    string
+	Delimiter string
+	Marker    string
+
+	// Include is a comma separated list of the datasets to include in
+	// the listing, such as "snapshots" to list the snapshots of blobs.
 	Include    string
 	MaxResults uint
 	Timeout    uint
@@ -171,13 +220,33 @@ const (
 	PageWriteTypeClear  PageWriteType = "clear"
 )
 
+// Statuses of a blob copy operation, as in BlobCopyStatus.
 const (
-	blobCopyStatusPending = "pending"
-	blobCopyStatusSuccess = "success"
-	blobCopyStatusAborted = "aborted"
-	blobCopyStatusFailed  = "failed"
+	BlobCopyStatusPending = "pending"
+	BlobCopyStatusSuccess = "success"
+	BlobCopyStatusAborted = "aborted"
+	BlobCopyStatusFailed  = "failed"
 )
 
+// defaultCopyPollInterval is the interval at which CopyBlob polls the status
+// of the copy operation.
+const defaultCopyPollInterval = time.Second
+
+// BlobCopyStatus is the status of the last copy operation to a blob, as
+// returned by GetCopyStatus.
+type BlobCopyStatus struct {
+	CopyId            string
+	Status            string
+	Source            string
+	StatusDescription string
+	CompletionTime    string
+
+	// BytesCopied and BytesTotal are the progress of the copy, which are
+	// zero if the service has not reported it.
+	BytesCopied int64
+	BytesTotal  int64
+}
+
 // BlockListType is used to filter out types of blocks
 // in a Get Blocks List call for a block blob. See
 // https://msdn.microsoft.com/en-us/library/azure/dd179400.aspx
@@ -201,9 +270,147 @@ const (
 	ContainerAccessTypeContainer ContainerAccessType = "container"
 )
 
+// ContainerACL is the access control list of a container: its public access
+// level and its stored access policies, which shared access signatures can
+// refer to by Id. See https://msdn.microsoft.com/en-us/library/azure/dd179391.aspx
+type ContainerACL struct {
+	PublicAccess      ContainerAccessType
+	SignedIdentifiers []SignedIdentifier
+}
+
+// SignedIdentifier is a stored access policy of a container.
+type SignedIdentifier struct {
+	Id           string       `xml:"Id"`
+	AccessPolicy AccessPolicy `xml:"AccessPolicy"`
+}
+
+// AccessPolicy defines the time window and permissions, such as "rwdl", of
+// the shared access signatures referring to a stored access policy.
+type AccessPolicy struct {
+	Start      time.Time `xml:"Start"`
+	Expiry     time.Time `xml:"Expiry"`
+	Permission string    `xml:"Permission"`
+}
+
+type signedIdentifiers struct {
+	XMLName           xml.Name           `xml:"SignedIdentifiers"`
+	SignedIdentifiers []SignedIdentifier `xml:"SignedIdentifier"`
+}
+
+// AccessConditions make a request conditional on the state of the blob it
+// addresses, as known from an earlier response. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179371.aspx
+type AccessConditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   time.Time
+	IfUnmodifiedSince time.Time
+}
+
+func (c AccessConditions) addHeaders(headers map[string]string) {
+	if c.IfMatch != "" {
+		headers["If-Match"] = c.IfMatch
+	}
+	if c.IfNoneMatch != "" {
+		headers["If-None-Match"] = c.IfNoneMatch
+	}
+	if !c.IfModifiedSince.IsZero() {
+		headers["If-Modified-Since"] = timeRfc1123Formatted(c.IfModifiedSince.UTC())
+	}
+	if !c.IfUnmodifiedSince.IsZero() {
+		headers["If-Unmodified-Since"] = timeRfc1123Formatted(c.IfUnmodifiedSince.UTC())
+	}
+}
+
+// BlobHeaders are the HTTP headers served with a blob, as set with
+// SetBlobProperties. ContentMD5 is the base64 encoded MD5 hash of the blob.
+type BlobHeaders struct {
+	ContentType     string
+	ContentEncoding string
+	ContentLanguage string
+	ContentMD5      string
+	CacheControl    string
+}
+
+func (h BlobHeaders) headers() map[string]string {
+	headers := map[string]string{}
+	if h.ContentType != "" {
+		headers["x-ms-blob-content-type"] = h.ContentType
+	}
+	if h.ContentEncoding != "" {
+		headers["x-ms-blob-content-encoding"] = h.ContentEncoding
+	}
+	if h.ContentLanguage != "" {
+		headers["x-ms-blob-content-language"] = h.ContentLanguage
+	}
+	if h.ContentMD5 != "" {
+		headers["x-ms-blob-content-md5"] = h.ContentMD5
+	}
+	if h.CacheControl != "" {
+		headers["x-ms-blob-cache-control"] = h.CacheControl
+	}
+	return headers
+}
+
+// DeleteSnapshotsOption says which of a blob and its snapshots are deleted
+// by DeleteBlobWithOptions.
+type DeleteSnapshotsOption string
+
+const (
+	// DeleteSnapshotsInclude deletes the blob and all of its snapshots.
+	DeleteSnapshotsInclude DeleteSnapshotsOption = "include"
+
+	// DeleteSnapshotsOnly deletes all snapshots of the blob but keeps
+	// the blob.
+	DeleteSnapshotsOnly DeleteSnapshotsOption = "only"
+)
+
+// DeleteBlobOptions configures DeleteBlobWithOptions. Without
+// DeleteSnapshots, only a blob without snapshots can be deleted. LeaseID is
+// required to delete a blob with an active lease.
+type DeleteBlobOptions struct {
+	DeleteSnapshots DeleteSnapshotsOption
+	LeaseID         string
+	Conditions      AccessConditions
+}
+
+// PutBlobOptions sets the properties and metadata of a blob uploaded with
+// PutBlob, and the conditions of the upload. Use IfNoneMatch "*" to fail
+// instead of overwriting an existing blob.
+type PutBlobOptions struct {
+	ContentType     string
+	ContentEncoding string
+	ContentLanguage string
+	CacheControl    string
+	Metadata        map[string]string
+	Conditions      AccessConditions
+}
+
+func (o PutBlobOptions) headers() map[string]string {
+	headers := BlobHeaders{
+		ContentType:     o.ContentType,
+		ContentEncoding: o.ContentEncoding,
+		ContentLanguage: o.ContentLanguage,
+		CacheControl:    o.CacheControl,
+	}.headers()
+	for k, v := range o.Metadata {
+		headers[userDefinedMetadataHeaderPrefix+k] = v
+	}
+	o.Conditions.addHeaders(headers)
+	return headers
+}
+
+// MaxStoredAccessPolicies is the maximum number of stored access policies
+// of a container.
+const MaxStoredAccessPolicies = 5
+
 const (
 	MaxBlobBlockSize = 4 * 1024 * 1024
 	MaxBlobPageSize  = 4 * 1024 * 1024
+
+	// pageSize is the size of the pages of a page blob, to which its size
+	// and written ranges are aligned.
+	pageSize = 512
 )
 
 // BlockStatus defines states a block for a block blob can
@@ -255,6 +462,11 @@ type PageRange struct {
 var (
 	ErrNotCreated  = errors.New("storage: operation has returned a successful error code other than 201 Created.")
 	ErrNotAccepted = errors.New("storage: operation has returned a successful error code other than 202 Accepted.")
+	ErrNotModified = errors.New("storage: blob was not modified since the given access conditions.")
+
+	// ErrContentMD5Mismatch is returned when downloaded data does not match
+	// its MD5 hash as stored or computed by the service.
+	ErrContentMD5Mismatch = errors.New("storage: downloaded data does not match its Content-MD5")
 
 	errBlobCopyAborted    = errors.New("storage: blob copy is aborted")
 	errBlobCopyIdMismatch = errors.New("storage: blob copy id is a mismatch")
@@ -279,6 +491,25 @@ func (b BlobStorageClient) ListContainers(params ListContainersParameters) (Cont
 	return out, err
 }
 
+// ListAllContainers returns the containers in a storage account matching
+// params, following the NextMarker of every page of ListContainers until the
+// last page. params.Marker, if set, is the marker of the first page.
+func (b BlobStorageClient) ListAllContainers(params ListContainersParameters) ([]Container, error) {
+	var containers []Container
+	for {
+		resp, err := b.ListContainers(params)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, resp.Containers...)
+
+		if resp.NextMarker == "" {
+			return containers, nil
+		}
+		params.Marker = resp.NextMarker
+	}
+}
+
 // CreateContainer creates a blob container within the storage account
 // with given name and access level. See https://msdn.microsoft.com/en-us/library/azure/dd179468.aspx
 // Returns error if container already exists.
@@ -329,6 +560,64 @@ func (b BlobStorageClient) ContainerExists(name string) (bool, error) {
 	return false, err
 }
 
+// GetContainerACL returns the public access level and the stored access
+// policies of the container with given name. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179469.aspx
+func (b BlobStorageClient) GetContainerACL(name string) (ContainerACL, error) {
+	verb := "GET"
+	uri := b.client.getEndpoint(blobServiceName, pathForContainer(name), url.Values{"restype": {"container"}, "comp": {"acl"}})
+	headers := b.client.getStandardHeaders()
+
+	var acl ContainerACL
+	resp, err := b.client.exec(verb, uri, headers, nil)
+	if err != nil {
+		return acl, err
+	}
+
+	var out signedIdentifiers
+	if err := xmlUnmarshal(resp.body, &out); err != nil {
+		return acl, err
+	}
+
+	acl.PublicAccess = ContainerAccessType(resp.headers.Get("x-ms-blob-public-access"))
+	acl.SignedIdentifiers = out.SignedIdentifiers
+	return acl, nil
+}
+
+// SetContainerACL replaces the public access level and the stored access
+// policies of the container with given name with the ones of acl. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179391.aspx
+func (b BlobStorageClient) SetContainerACL(name string, acl ContainerACL) error {
+	if len(acl.SignedIdentifiers) > MaxStoredAccessPolicies {
+		return fmt.Errorf("storage: a container can have at most %d stored access policies, got %d", MaxStoredAccessPolicies, len(acl.SignedIdentifiers))
+	}
+
+	body, err := xml.Marshal(signedIdentifiers{SignedIdentifiers: acl.SignedIdentifiers})
+	if err != nil {
+		return err
+	}
+
+	verb := "PUT"
+	uri := b.client.getEndpoint(blobServiceName, pathForContainer(name), url.Values{"restype": {"container"}, "comp": {"acl"}})
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = fmt.Sprintf("%v", len(body))
+	headers["Content-Type"] = "application/xml"
+	if acl.PublicAccess != ContainerAccessTypePrivate {
+		headers["x-ms-blob-public-access"] = string(acl.PublicAccess)
+	}
+
+	resp, err := b.client.exec(verb, uri, headers, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	return nil
+}
+
 // DeleteContainer deletes the container with given name on the storage
 // account. See https://msdn.microsoft.com/en-us/library/azure/dd179408.aspx
 // If the container does not exist returns error.
@@ -418,7 +707,32 @@ func (b BlobStorageClient) GetBlob(container, name string) (io.ReadCloser, error
 	if resp.statusCode != http.StatusOK {
 		return nil, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
 	}
-	return resp.body, nil
+	return b.verifyContentMD5(resp), nil
+}
+
+// GetBlobTo downloads a blob to w if it satisfies conditions, and returns the
+// number of bytes written. If the blob does not satisfy the If-None-Match or
+// If-Modified-Since conditions, ErrNotModified is returned. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179440.aspx
+func (b BlobStorageClient) GetBlobTo(container, name string, w io.Writer, conditions AccessConditions) (int64, error) {
+	verb := "GET"
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{})
+
+	headers := b.client.getStandardHeaders()
+	conditions.addHeaders(headers)
+	resp, err := b.client.exec(verb, uri, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode == http.StatusNotModified {
+		return 0, ErrNotModified
+	}
+	if resp.statusCode != http.StatusOK {
+		return 0, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	return io.Copy(w, b.verifyContentMD5(resp))
 }
 
 // GetBlobRange reads the specified range of a blob to a stream.
@@ -490,7 +804,10 @@ func (b BlobStorageClient) GetBlobProperties(container, name string) (*BlobPrope
 		Etag:                  resp.headers.Get("Etag"),
 		ContentMD5:            resp.headers.Get("Content-MD5"),
 		ContentLength:         contentLength,
+		ContentType:           resp.headers.Get("Content-Type"),
 		ContentEncoding:       resp.headers.Get("Content-Encoding"),
+		ContentLanguage:       resp.headers.Get("Content-Language"),
+		CacheControl:          resp.headers.Get("Cache-Control"),
 		SequenceNumber:        sequenceNum,
 		CopyCompletionTime:    resp.headers.Get("x-ms-copy-completion-time"),
 		CopyStatusDescription: resp.headers.Get("x-ms-copy-status-description"),
@@ -502,6 +819,85 @@ func (b BlobStorageClient) GetBlobProperties(container, name string) (*BlobPrope
 	}, nil
 }
 
+// SetBlobProperties replaces the HTTP headers served with a blob by the ones
+// in blobHeaders. Headers left empty are removed from the blob. See
+// https://msdn.microsoft.com/en-us/library/azure/ee691966.aspx
+func (b BlobStorageClient) SetBlobProperties(container, name string, blobHeaders BlobHeaders) error {
+	params := url.Values{"comp": {"properties"}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+	for k, v := range blobHeaders.headers() {
+		headers[k] = v
+	}
+
+	resp, err := b.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	return nil
+}
+
+// GetBlobMetadata returns the metadata of a blob, with names in lower case.
+// See https://msdn.microsoft.com/en-us/library/azure/dd179350.aspx
+func (b BlobStorageClient) GetBlobMetadata(container, name string) (map[string]string, error) {
+	params := url.Values{"comp": {"metadata"}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+
+	headers := b.client.getStandardHeaders()
+	resp, err := b.client.exec("GET", uri, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return nil, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+
+	metadata := make(map[string]string)
+	for k, v := range resp.headers {
+		// Header names are canonicalized, so x-ms-meta-name arrives as
+		// X-Ms-Meta-Name.
+		k = strings.ToLower(k)
+		if len(v) == 0 || !strings.HasPrefix(k, userDefinedMetadataHeaderPrefix) {
+			continue
+		}
+		metadata[k[len(userDefinedMetadataHeaderPrefix):]] = v[len(v)-1]
+	}
+	return metadata, nil
+}
+
+// SetBlobMetadata replaces the metadata of a blob by metadata. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179414.aspx
+func (b BlobStorageClient) SetBlobMetadata(container, name string, metadata map[string]string) error {
+	params := url.Values{"comp": {"metadata"}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+	for k, v := range metadata {
+		headers[userDefinedMetadataHeaderPrefix+k] = v
+	}
+
+	resp, err := b.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	return nil
+}
+
 // CreateBlockBlob initializes an empty block blob with no blocks.
 // See https://msdn.microsoft.com/en-us/library/azure/dd179451.aspx
 func (b BlobStorageClient) CreateBlockBlob(container, name string) error {
@@ -529,28 +925,45 @@ func (b BlobStorageClient) PutBlockBlob(container, name string, blob io.Reader)
 	return b.putBlockBlob(container, name, blob, MaxBlobBlockSize)
 }
 
+// PutBlob uploads blob into a block blob, as PutBlockBlob does, setting the
+// content type, other properties and metadata of the blob given in options,
+// and only if the blob satisfies the conditions of options. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179451.aspx
+func (b BlobStorageClient) PutBlob(container, name string, blob io.Reader, options PutBlobOptions) error {
+	return b.putBlockBlobWithHeaders(container, name, blob, MaxBlobBlockSize, options.headers())
+}
+
 func (b BlobStorageClient) putBlockBlob(container, name string, blob io.Reader, chunkSize int) error {
+	return b.putBlockBlobWithHeaders(container, name, blob, chunkSize, nil)
+}
+
+// putBlockBlobWithHeaders is putBlockBlob sending extraHeaders with the
+// request creating the blob: the Put Blob request of a blob fitting into one
+// block, or else the Put Block List request.
+func (b BlobStorageClient) putBlockBlobWithHeaders(container, name string, blob io.Reader, chunkSize int, extraHeaders map[string]string) error {
 	if chunkSize <= 0 || chunkSize > MaxBlobBlockSize {
 		chunkSize = MaxBlobBlockSize
 	}
 
 	chunk := make([]byte, chunkSize)
-	n, err := blob.Read(chunk)
-	if err != nil && err != io.EOF {
+	n, err := io.ReadFull(blob, chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return err
 	}
 
-	if err == io.EOF {
+	if err != nil {
 		// Fits into one block
-		return b.putSingleBlockBlob(container, name, chunk[:n])
+		return b.putSingleBlockBlob(container, name, chunk[:n], extraHeaders)
 	} else {
 		// Does not fit into one block. Upload block by block then commit the block list
 		blockList := []Block{}
+		blobHash := md5.New()
 
 		// Put blocks
-		for blockNum := 0; ; blockNum++ {
-			id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%011d", blockNum)))
+		for blockNum := 0; n > 0; blockNum++ {
+			id := blockId(blockNum)
 			data := chunk[:n]
+			blobHash.Write(data)
 			err = b.PutBlock(container, name, id, data)
 			if err != nil {
 				return err
@@ -558,21 +971,23 @@ func (b BlobStorageClient) putBlockBlob(container, name string, blob io.Reader,
 			blockList = append(blockList, Block{id, BlockStatusLatest})
 
 			// Read next block
-			n, err = blob.Read(chunk)
-			if err != nil && err != io.EOF {
+			n, err = io.ReadFull(blob, chunk)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 				return err
 			}
-			if err == io.EOF {
-				break
-			}
 		}
 
-		// Commit block list
-		return b.PutBlockList(container, name, blockList)
+		// Commit block list, storing the MD5 of the whole blob as a single
+		// block blob gets it
+		if !b.skipContentMD5 {
+			extraHeaders = copyHeaders(extraHeaders)
+			extraHeaders["x-ms-blob-content-md5"] = base64.StdEncoding.EncodeToString(blobHash.Sum(nil))
+		}
+		return b.putBlockList(container, name, blockList, extraHeaders)
 	}
 }
 
-func (b BlobStorageClient) putSingleBlockBlob(container, name string, chunk []byte) error {
+func (b BlobStorageClient) putSingleBlockBlob(container, name string, chunk []byte, extraHeaders map[string]string) error {
 	if len(chunk) > MaxBlobBlockSize {
 		return fmt.Errorf("storage: provided chunk (%d bytes) cannot fit into single-block blob (max %d bytes)", len(chunk), MaxBlobBlockSize)
 	}
@@ -581,6 +996,10 @@ func (b BlobStorageClient) putSingleBlockBlob(container, name string, chunk []by
 	headers := b.client.getStandardHeaders()
 	headers["x-ms-blob-type"] = string(BlobTypeBlock)
 	headers["Content-Length"] = fmt.Sprintf("%v", len(chunk))
+	b.setContentMD5(headers, chunk)
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
 
 	resp, err := b.client.exec("PUT", uri, headers, bytes.NewReader(chunk))
 	if err != nil {
@@ -596,7 +1015,9 @@ func (b BlobStorageClient) putSingleBlockBlob(container, name string, chunk []by
 // PutBlock saves the given data chunk to the specified block blob with
 // given ID. See https://msdn.microsoft.com/en-us/library/azure/dd135726.aspx
 func (b BlobStorageClient) PutBlock(container, name, blockId string, chunk []byte) error {
-	return b.PutBlockWithLength(container, name, blockId, uint64(len(chunk)), bytes.NewReader(chunk))
+	headers := map[string]string{}
+	b.setContentMD5(headers, chunk)
+	return b.putBlock(container, name, blockId, uint64(len(chunk)), bytes.NewReader(chunk), headers)
 }
 
 // PutBlockWithLength saves the given data stream of exactly specified size to the block blob
@@ -604,10 +1025,17 @@ func (b BlobStorageClient) PutBlock(container, name, blockId string, chunk []byt
 // It is an alternative to PutBlocks where data comes as stream but the length is
 // known in advance.
 func (b BlobStorageClient) PutBlockWithLength(container, name, blockId string, size uint64, blob io.Reader) error {
+	return b.putBlock(container, name, blockId, size, blob, nil)
+}
+
+func (b BlobStorageClient) putBlock(container, name, blockId string, size uint64, blob io.Reader, extraHeaders map[string]string) error {
 	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{"comp": {"block"}, "blockid": {blockId}})
 	headers := b.client.getStandardHeaders()
 	headers["x-ms-blob-type"] = string(BlobTypeBlock)
 	headers["Content-Length"] = fmt.Sprintf("%v", size)
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
 
 	resp, err := b.client.exec("PUT", uri, headers, blob)
 	if err != nil {
@@ -623,11 +1051,18 @@ func (b BlobStorageClient) PutBlockWithLength(container, name, blockId string, s
 // PutBlockList saves list of blocks to the specified block blob. See
 // https://msdn.microsoft.com/en-us/library/azure/dd179467.aspx
 func (b BlobStorageClient) PutBlockList(container, name string, blocks []Block) error {
+	return b.putBlockList(container, name, blocks, nil)
+}
+
+func (b BlobStorageClient) putBlockList(container, name string, blocks []Block, extraHeaders map[string]string) error {
 	blockListXml := prepareBlockListRequest(blocks)
 
 	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{"comp": {"blocklist"}})
 	headers := b.client.getStandardHeaders()
 	headers["Content-Length"] = fmt.Sprintf("%v", len(blockListXml))
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
 
 	resp, err := b.client.exec("PUT", uri, headers, strings.NewReader(blockListXml))
 	if err != nil {
@@ -661,6 +1096,10 @@ func (b BlobStorageClient) GetBlockList(container, name string, blockType BlockL
 // be created using this method before writing pages.
 // See https://msdn.microsoft.com/en-us/library/azure/dd179451.aspx
 func (b BlobStorageClient) PutPageBlob(container, name string, size int64) error {
+	if size < 0 || size%pageSize != 0 {
+		return fmt.Errorf("storage: page blob size (%d bytes) must be a multiple of %d bytes", size, pageSize)
+	}
+
 	path := fmt.Sprintf("%s/%s", container, name)
 	uri := b.client.getEndpoint(blobServiceName, path, url.Values{})
 	headers := b.client.getStandardHeaders()
@@ -683,6 +1122,13 @@ func (b BlobStorageClient) PutPageBlob(container, name string, size int64) error
 // with 512-byte boundaries and chunk must be of size multiplies by 512.
 // See https://msdn.microsoft.com/en-us/library/ee691975.aspx
 func (b BlobStorageClient) PutPage(container, name string, startByte, endByte int64, writeType PageWriteType, chunk []byte) error {
+	if startByte < 0 || startByte%pageSize != 0 || (endByte+1)%pageSize != 0 || endByte < startByte {
+		return fmt.Errorf("storage: page range %d-%d is not aligned to %d-byte pages", startByte, endByte, pageSize)
+	}
+	if writeType == PageWriteTypeUpdate && int64(len(chunk)) != endByte-startByte+1 {
+		return fmt.Errorf("storage: page range %d-%d does not match chunk of %d bytes", startByte, endByte, len(chunk))
+	}
+
 	path := fmt.Sprintf("%s/%s", container, name)
 	uri := b.client.getEndpoint(blobServiceName, path, url.Values{"comp": {"page"}})
 	headers := b.client.getStandardHeaders()
@@ -698,6 +1144,7 @@ func (b BlobStorageClient) PutPage(container, name string, startByte, endByte in
 	} else {
 		contentLength = int64(len(chunk))
 		data = bytes.NewReader(chunk)
+		b.setContentMD5(headers, chunk)
 	}
 	headers["Content-Length"] = fmt.Sprintf("%v", contentLength)
 
@@ -737,15 +1184,20 @@ func (b BlobStorageClient) GetPageRanges(container, name string) (GetPageRangesR
 // GetBlobURL method.) There is no SLA on blob copy and therefore this helper
 // method works faster on smaller files. See https://msdn.microsoft.com/en-us/library/azure/dd894037.aspx
 func (b BlobStorageClient) CopyBlob(container, name, sourceBlob string) error {
-	copyId, err := b.startBlobCopy(container, name, sourceBlob)
+	copyId, err := b.StartBlobCopy(container, name, sourceBlob)
 	if err != nil {
 		return err
 	}
 
-	return b.waitForBlobCopy(container, name, copyId)
+	return b.WaitForCopy(context.Background(), container, name, copyId, defaultCopyPollInterval)
 }
 
-func (b BlobStorageClient) startBlobCopy(container, name, sourceBlob string) (string, error) {
+// StartBlobCopy starts copying sourceBlob to the given blob and returns the
+// ID of the copy operation, without waiting for it to complete. sourceBlob is
+// the URL of the source blob, which for a private blob of another storage
+// account must carry a Shared Access Signature, as returned by
+// GetBlobSASURI. See https://msdn.microsoft.com/en-us/library/azure/dd894037.aspx
+func (b BlobStorageClient) StartBlobCopy(container, name, sourceBlob string) (string, error) {
 	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{})
 
 	headers := b.client.getStandardHeaders()
@@ -767,28 +1219,96 @@ func (b BlobStorageClient) startBlobCopy(container, name, sourceBlob string) (st
 	return copyId, nil
 }
 
-func (b BlobStorageClient) waitForBlobCopy(container, name, copyId string) error {
+// GetCopyStatus returns the status of the last copy operation to a blob.
+func (b BlobStorageClient) GetCopyStatus(container, name string) (*BlobCopyStatus, error) {
+	props, err := b.GetBlobProperties(container, name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BlobCopyStatus{
+		CopyId:            props.CopyId,
+		Status:            props.CopyStatus,
+		Source:            props.CopySource,
+		StatusDescription: props.CopyStatusDescription,
+		CompletionTime:    props.CopyCompletionTime,
+	}
+	// The progress is given as "<bytes copied>/<bytes total>".
+	if parts := strings.SplitN(props.CopyProgress, "/", 2); len(parts) == 2 {
+		if status.BytesCopied, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("storage: invalid blob copy progress %q", props.CopyProgress)
+		}
+		if status.BytesTotal, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("storage: invalid blob copy progress %q", props.CopyProgress)
+		}
+	}
+	return status, nil
+}
+
+// AbortCopy aborts the pending copy operation with the given ID, leaving the
+// destination blob empty. See
+// https://msdn.microsoft.com/en-us/library/azure/jj159098.aspx
+func (b BlobStorageClient) AbortCopy(container, name, copyId string) error {
+	params := url.Values{"comp": {"copy"}, "copyid": {copyId}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+	headers["x-ms-copy-action"] = "abort"
+
+	resp, err := b.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+// WaitForCopy polls the status of the copy operation with the given ID every
+// pollInterval, or every second if pollInterval is zero, until it completes,
+// and returns an error unless it succeeded. If ctx is done first, ctx.Err()
+// is returned and the copy goes on; it can be stopped with AbortCopy. Use
+// context.WithTimeout to bound the wait.
+func (b BlobStorageClient) WaitForCopy(ctx context.Context, container, name, copyId string, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		pollInterval = defaultCopyPollInterval
+	}
+
 	for {
-		props, err := b.GetBlobProperties(container, name)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status, err := b.GetCopyStatus(container, name)
 		if err != nil {
 			return err
 		}
 
-		if props.CopyId != copyId {
+		if status.CopyId != copyId {
 			return errBlobCopyIdMismatch
 		}
 
-		switch props.CopyStatus {
-		case blobCopyStatusSuccess:
+		switch status.Status {
+		case BlobCopyStatusSuccess:
 			return nil
-		case blobCopyStatusPending:
+		case BlobCopyStatusPending:
+			timer := time.NewTimer(pollInterval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
 			continue
-		case blobCopyStatusAborted:
+		case BlobCopyStatusAborted:
 			return errBlobCopyAborted
-		case blobCopyStatusFailed:
-			return fmt.Errorf("storage: blob copy failed. Id=%s Description=%s", props.CopyId, props.CopyStatusDescription)
+		case BlobCopyStatusFailed:
+			return fmt.Errorf("storage: blob copy failed. Id=%s Description=%s", status.CopyId, status.StatusDescription)
 		default:
-			return fmt.Errorf("storage: unhandled blob copy status: '%s'", props.CopyStatus)
+			return fmt.Errorf("storage: unhandled blob copy status: '%s'", status.Status)
 		}
 	}
 }
@@ -826,6 +1346,89 @@ func (b BlobStorageClient) deleteBlob(container, name string) (*storageResponse,
 	return b.client.exec(verb, uri, headers, nil)
 }
 
+// SnapshotBlob creates a read-only snapshot of a blob as it is now, and
+// returns the identifier of the snapshot. The snapshot gets the metadata of
+// the blob unless metadata is given. See
+// https://msdn.microsoft.com/en-us/library/azure/ee691971.aspx
+func (b BlobStorageClient) SnapshotBlob(container, name string, metadata map[string]string) (string, error) {
+	params := url.Values{"comp": {"snapshot"}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+	for k, v := range metadata {
+		headers[userDefinedMetadataHeaderPrefix+k] = v
+	}
+
+	resp, err := b.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusCreated {
+		return "", ErrNotCreated
+	}
+	snapshot := resp.headers.Get("x-ms-snapshot")
+	if snapshot == "" {
+		return "", errors.New("storage: got no snapshot identifier in the response of the Snapshot Blob call")
+	}
+	return snapshot, nil
+}
+
+// DeleteBlobSnapshot deletes the snapshot of a blob with the given
+// identifier, as returned by SnapshotBlob or listed by ListBlobs. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179413.aspx
+func (b BlobStorageClient) DeleteBlobSnapshot(container, name, snapshot string) error {
+	params := url.Values{"snapshot": {snapshot}}
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), params)
+	headers := b.client.getStandardHeaders()
+
+	resp, err := b.client.exec("DELETE", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusAccepted {
+		return ErrNotAccepted
+	}
+	return nil
+}
+
+// DeleteBlobWithSnapshots deletes a blob together with all of its
+// snapshots, which DeleteBlob fails to do for a blob that has snapshots.
+// See https://msdn.microsoft.com/en-us/library/azure/dd179413.aspx
+func (b BlobStorageClient) DeleteBlobWithSnapshots(container, name string) error {
+	return b.DeleteBlobWithOptions(container, name, DeleteBlobOptions{DeleteSnapshots: DeleteSnapshotsInclude})
+}
+
+// DeleteBlobWithOptions deletes a blob, its snapshots or both as options
+// say, only if the blob satisfies the conditions of options. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179413.aspx
+func (b BlobStorageClient) DeleteBlobWithOptions(container, name string, options DeleteBlobOptions) error {
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{})
+	headers := b.client.getStandardHeaders()
+	if options.DeleteSnapshots != "" {
+		headers["x-ms-delete-snapshots"] = string(options.DeleteSnapshots)
+	}
+	if options.LeaseID != "" {
+		headers["x-ms-lease-id"] = options.LeaseID
+	}
+	options.Conditions.addHeaders(headers)
+
+	resp, err := b.client.exec("DELETE", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusAccepted {
+		return ErrNotAccepted
+	}
+	return nil
+}
+
 // helper method to construct the path to a container given its name
 func pathForContainer(name string) string {
 	return fmt.Sprintf("/%s", name)
@@ -836,21 +1439,68 @@ func pathForBlob(container, name string) string {
 	return fmt.Sprintf("/%s/%s", container, name)
 }
 
+// SASOptions are the constraints of a Shared Access Signature. The expiry
+// time and permissions can be left out when they are given by the stored
+// access policy of the container referred to by Identifier. See
+// https://msdn.microsoft.com/en-us/library/azure/dn140255.aspx
+type SASOptions struct {
+	Start  time.Time
+	Expiry time.Time
+
+	// Permissions are the letters of the allowed operations, in the order
+	// "rwdl": read, write, delete and list (list applies to containers).
+	Permissions string
+
+	// Identifier is the ID of a stored access policy of the container, as
+	// set with SetContainerACL. Such signatures can be revoked by
+	// changing or removing the policy.
+	Identifier string
+}
+
 // GetBlobSASURI creates an URL to the specified blob which contains the Shared Access Signature
-// with specified permissions and expiration time. See https://msdn.microsoft.com/en-us/library/azure/ee395415.aspx
+// with specified permissions and expiration time. A zero expiry time is rejected with an error,
+// as the service does not accept a signature without an expiry time unless it refers to a stored
+// access policy; see GetBlobSASURIWithOptions. See https://msdn.microsoft.com/en-us/library/azure/ee395415.aspx
 func (b BlobStorageClient) GetBlobSASURI(container, name string, expiry time.Time, permissions string) (string, error) {
-	var (
-		signedPermissions = permissions
-		blobUrl           = b.GetBlobUrl(container, name)
-	)
-	canonicalizedResource, err := b.client.buildCanonicalizedResource(blobUrl)
+	return b.GetBlobSASURIWithOptions(container, name, SASOptions{Expiry: expiry, Permissions: permissions})
+}
+
+// GetBlobSASURIWithOptions creates an URL to the specified blob which
+// contains a Shared Access Signature with the given constraints.
+func (b BlobStorageClient) GetBlobSASURIWithOptions(container, name string, options SASOptions) (string, error) {
+	return b.getSASURI(b.GetBlobUrl(container, name), "b", options)
+}
+
+// GetContainerSASURI creates an URL to the specified container which
+// contains a Shared Access Signature with the given constraints, granting
+// access to all blobs of the container.
+func (b BlobStorageClient) GetContainerSASURI(container string, options SASOptions) (string, error) {
+	uri := b.client.getEndpoint(blobServiceName, pathForContainer(container), url.Values{})
+	return b.getSASURI(uri, "c", options)
+}
+
+func (b BlobStorageClient) getSASURI(resourceUrl, signedResource string, options SASOptions) (string, error) {
+	if options.Identifier == "" && options.Permissions == "" {
+		return "", errors.New("storage: a SAS needs permissions unless it refers to a stored access policy")
+	}
+	if options.Identifier == "" && options.Expiry.IsZero() {
+		return "", errors.New("storage: a SAS needs an expiry time unless it refers to a stored access policy")
+	}
+
+	canonicalizedResource, err := b.client.buildCanonicalizedResource(resourceUrl)
 	if err != nil {
 		return "", err
 	}
-	signedExpiry := expiry.Format(time.RFC3339)
-	signedResource := "b"
 
-	stringToSign, err := blobSASStringToSign(b.client.apiVersion, canonicalizedResource, signedExpiry, signedPermissions)
+	var signedStart, signedExpiry string
+	if !options.Start.IsZero() {
+		signedStart = options.Start.UTC().Format(time.RFC3339)
+	}
+	if !options.Expiry.IsZero() {
+		signedExpiry = options.Expiry.UTC().Format(time.RFC3339)
+	}
+
+	stringToSign, err := sasStringToSign(b.client.apiVersion, canonicalizedResource, signedStart, signedExpiry, options.Permissions, options.Identifier)
 	if err != nil {
 		return "", err
 	}
@@ -858,13 +1508,21 @@ func (b BlobStorageClient) GetBlobSASURI(container, name string, expiry time.Tim
 	sig := b.client.computeHmac256(stringToSign)
 	sasParams := url.Values{
 		"sv":  {b.client.apiVersion},
-		"se":  {signedExpiry},
 		"sr":  {signedResource},
-		"sp":  {signedPermissions},
 		"sig": {sig},
 	}
+	for k, v := range map[string]string{
+		"st": signedStart,
+		"se": signedExpiry,
+		"sp": options.Permissions,
+		"si": options.Identifier,
+	} {
+		if v != "" {
+			sasParams.Set(k, v)
+		}
+	}
 
-	sasUrl, err := url.Parse(blobUrl)
+	sasUrl, err := url.Parse(resourceUrl)
 	if err != nil {
 		return "", err
 	}
@@ -873,7 +1531,11 @@ func (b BlobStorageClient) GetBlobSASURI(container, name string, expiry time.Tim
 }
 
 func blobSASStringToSign(signedVersion, canonicalizedResource, signedExpiry, signedPermissions string) (string, error) {
-	var signedStart, signedIdentifier, rscc, rscd, rsce, rscl, rsct string
+	return sasStringToSign(signedVersion, canonicalizedResource, "", signedExpiry, signedPermissions, "")
+}
+
+func sasStringToSign(signedVersion, canonicalizedResource, signedStart, signedExpiry, signedPermissions, signedIdentifier string) (string, error) {
+	var rscc, rscd, rsce, rscl, rsct string
 
 	// reference: http://msdn.microsoft.com/en-us/library/azure/dn140255.aspx
 	if signedVersion >= "2013-08-15" {
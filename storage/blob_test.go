@@ -2,6 +2,7 @@ package storage
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -55,7 +56,7 @@ func TestGetBlobSASURI(t *testing.T) {
 		t.Fatal(err)
 	}
 	cli := api.GetBlobService()
-	expiry := time.Time{}
+	expiry := time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)
 
 	expectedParts := url.URL{
 		Scheme: "https",
@@ -63,10 +64,10 @@ func TestGetBlobSASURI(t *testing.T) {
 		Path:   "container/name",
 		RawQuery: url.Values{
 			"sv":  {"2013-08-15"},
-			"sig": {"/OXG7rWh08jYwtU03GzJM0DHZtidRGpC6g69rSGm3I0="},
+			"sig": {"A5l/1/7Y8qYtpDdI8a1n8k9LQPN+TvJOVDfchqlqy5A="},
 			"sr":  {"b"},
 			"sp":  {"r"},
-			"se":  {"0001-01-01T00:00:00Z"},
+			"se":  {"2015-01-02T00:00:00Z"},
 		}.Encode()}
 
 	u, err := cli.GetBlobSASURI("container", "name", expiry, "r")
@@ -103,6 +104,78 @@ func TestGetBlobSASURI(t *testing.T) {
 	}
 }
 
+func TestGetBlobSASURIRequiresExpiryOffline(t *testing.T) {
+	api, err := NewClient("foo", "YmFy", DefaultBaseUrl, "2013-08-15", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := api.GetBlobService()
+
+	if _, err := cli.GetBlobSASURI("container", "name", time.Time{}, "r"); err == nil {
+		t.Fatal("Expected a SAS without expiry time nor stored access policy to be rejected")
+	}
+	if _, err := cli.GetBlobSASURIWithOptions("container", "name", SASOptions{Identifier: "policy"}); err != nil {
+		t.Fatalf("Expected the expiry time of a stored access policy to be used, got %v", err)
+	}
+}
+
+func TestGetContainerSASURIOffline(t *testing.T) {
+	api, err := NewClient("foo", "YmFy", DefaultBaseUrl, "2013-08-15", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := api.GetBlobService()
+
+	u, err := cli.GetContainerSASURI("container", SASOptions{
+		Start:       time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+		Expiry:      time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC),
+		Permissions: "rl",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sasParts, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sasParts.Path != "/container" {
+		t.Errorf("Expected SAS URL of the container, got %s", u)
+	}
+
+	stringToSign, err := sasStringToSign("2013-08-15", "/foo/container", "2015-01-01T00:00:00Z", "2015-01-02T00:00:00Z", "rl", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := url.Values{
+		"sv":  {"2013-08-15"},
+		"sr":  {"c"},
+		"st":  {"2015-01-01T00:00:00Z"},
+		"se":  {"2015-01-02T00:00:00Z"},
+		"sp":  {"rl"},
+		"sig": {api.computeHmac256(stringToSign)},
+	}
+	if !reflect.DeepEqual(sasParts.Query(), expected) {
+		t.Fatalf("Expected SAS query %v, got %v", expected, sasParts.Query())
+	}
+
+	u, err = cli.GetBlobSASURIWithOptions("container", "name", SASOptions{Identifier: "readers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sasParts, err = url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := sasParts.Query()
+	if q.Get("si") != "readers" || q.Get("se") != "" || q.Get("sp") != "" {
+		t.Fatalf("Expected SAS referring to the stored access policy only, got %s", u)
+	}
+
+	if _, err := cli.GetBlobSASURIWithOptions("container", "name", SASOptions{Expiry: time.Now()}); err == nil {
+		t.Fatal("Expected SAS without permissions or policy to be rejected")
+	}
+}
+
 func TestBlobSASURICorrectness(t *testing.T) {
 	cli, err := getBlobClient()
 	if err != nil {
@@ -228,6 +301,131 @@ func TestListContainersPagination(t *testing.T) {
 	}
 }
 
+func TestListAllContainersFollowsMarkers(t *testing.T) {
+	pages := map[string]string{
+		"":     `<EnumerationResults><Containers><Container><Name>a</Name></Container></Containers><NextMarker>next</NextMarker></EnumerationResults>`,
+		"next": `<EnumerationResults><Containers><Container><Name>b</Name></Container></Containers><NextMarker/></EnumerationResults>`,
+	}
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prefix") != "vhd" {
+			t.Errorf("Expected the prefix to be sent with every page, got %s", r.URL.RawQuery)
+		}
+		writeOfflineResponse(w, http.StatusOK, pages[r.URL.Query().Get("marker")])
+	}).GetBlobService()
+
+	containers, err := cli.ListAllContainers(ListContainersParameters{Prefix: "vhd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 2 || containers[0].Name != "a" || containers[1].Name != "b" {
+		t.Fatalf("Expected containers a and b, got %+v", containers)
+	}
+}
+
+func TestContainerACLOffline(t *testing.T) {
+	var stored []byte
+	var publicAccess string
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vhds" || r.URL.Query().Get("comp") != "acl" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		switch r.Method {
+		case "PUT":
+			stored, _ = ioutil.ReadAll(r.Body)
+			publicAccess = r.Header.Get("x-ms-blob-public-access")
+			writeOfflineResponse(w, http.StatusOK, "")
+		case "GET":
+			w.Header().Set("x-ms-blob-public-access", publicAccess)
+			writeOfflineResponse(w, http.StatusOK, string(stored))
+		}
+	}).GetBlobService()
+
+	acl := ContainerACL{
+		PublicAccess: ContainerAccessTypeBlob,
+		SignedIdentifiers: []SignedIdentifier{{
+			Id: "readers",
+			AccessPolicy: AccessPolicy{
+				Start:      time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+				Expiry:     time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+				Permission: "r",
+			},
+		}},
+	}
+	if err := cli.SetContainerACL("vhds", acl); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(stored), "<Start>2015-01-01T00:00:00Z</Start>") {
+		t.Errorf("Unexpected access policy body: %s", stored)
+	}
+
+	out, err := cli.GetContainerACL("vhds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, acl) {
+		t.Fatalf("Expected %+v, got %+v", acl, out)
+	}
+
+	acl.SignedIdentifiers = make([]SignedIdentifier, MaxStoredAccessPolicies+1)
+	if err := cli.SetContainerACL("vhds", acl); err == nil {
+		t.Fatal("Expected too many stored access policies to be rejected")
+	}
+}
+
+func TestPutBlobAndGetBlobToOffline(t *testing.T) {
+	var stored []byte
+	var header http.Header
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifacts/build.zip" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		switch r.Method {
+		case "PUT":
+			stored, _ = ioutil.ReadAll(r.Body)
+			header = r.Header
+			writeOfflineResponse(w, http.StatusCreated, "")
+		case "GET":
+			if r.Header.Get("If-None-Match") == "etag" {
+				writeOfflineResponse(w, http.StatusNotModified, "")
+				return
+			}
+			writeOfflineResponse(w, http.StatusOK, string(stored))
+		}
+	}).GetBlobService()
+
+	err := cli.PutBlob("artifacts", "build.zip", strings.NewReader("payload"), PutBlobOptions{
+		ContentType: "application/zip",
+		Metadata:    map[string]string{"commit": "abc123"},
+		Conditions:  AccessConditions{IfNoneMatch: "*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{
+		"x-ms-blob-type":         string(BlobTypeBlock),
+		"x-ms-blob-content-type": "application/zip",
+		"x-ms-meta-commit":       "abc123",
+		"If-None-Match":          "*",
+	} {
+		if got := header.Get(k); got != v {
+			t.Errorf("Expected header %s to be %q, got %q", k, v, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	n, err := cli.GetBlobTo("artifacts", "build.zip", &buf, AccessConditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len("payload")) || buf.String() != "payload" {
+		t.Fatalf("Unexpected blob content (%d bytes): %q", n, buf.String())
+	}
+
+	if _, err := cli.GetBlobTo("artifacts", "build.zip", &buf, AccessConditions{IfNoneMatch: "etag"}); err != ErrNotModified {
+		t.Fatalf("Expected ErrNotModified, got %v", err)
+	}
+}
+
 func TestContainerExists(t *testing.T) {
 	cnt := randContainer()
 
@@ -518,6 +716,278 @@ func TestGetBlobProperties(t *testing.T) {
 	}
 }
 
+func TestListBlobsWithDelimiterOffline(t *testing.T) {
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("prefix") != "logs/" || q.Get("delimiter") != "/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		writeOfflineResponse(w, http.StatusOK, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults ContainerName="https://foo.blob.core.windows.net/artifacts">
+  <Prefix>logs/</Prefix>
+  <Delimiter>/</Delimiter>
+  <Blobs>
+    <Blob><Name>logs/build.log</Name></Blob>
+    <BlobPrefix><Name>logs/2015/</Name></BlobPrefix>
+    <BlobPrefix><Name>logs/2016/</Name></BlobPrefix>
+  </Blobs>
+  <NextMarker>marker</NextMarker>
+</EnumerationResults>`)
+	}).GetBlobService()
+
+	out, err := cli.ListBlobs("artifacts", ListBlobsParameters{Prefix: "logs/", Delimiter: "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Blobs) != 1 || out.Blobs[0].Name != "logs/build.log" {
+		t.Errorf("Unexpected blobs: %+v", out.Blobs)
+	}
+	if expected := []string{"logs/2015/", "logs/2016/"}; !reflect.DeepEqual(out.BlobPrefixes, expected) {
+		t.Errorf("Expected prefixes %v, got %v", expected, out.BlobPrefixes)
+	}
+	if out.Delimiter != "/" || out.NextMarker != "marker" {
+		t.Errorf("Unexpected delimiter %q or next marker %q", out.Delimiter, out.NextMarker)
+	}
+}
+
+func TestBlobPropertiesAndMetadataOffline(t *testing.T) {
+	header := http.Header{}
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		comp := r.URL.Query().Get("comp")
+		switch {
+		case r.Method == "PUT" && comp == "properties":
+			for _, k := range []string{"Content-Type", "Cache-Control", "Content-MD5"} {
+				header.Set(k, r.Header.Get("x-ms-blob-"+strings.ToLower(k)))
+			}
+		case r.Method == "PUT" && comp == "metadata":
+			for k, v := range r.Header {
+				if strings.HasPrefix(k, "X-Ms-Meta-") {
+					header[k] = v
+				}
+			}
+		case r.Method == "HEAD" && comp == "":
+		case r.Method == "GET" && comp == "metadata":
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(http.StatusOK)
+	}).GetBlobService()
+
+	if err := cli.SetBlobProperties("site", "index.html", BlobHeaders{
+		ContentType:  "text/html",
+		CacheControl: "max-age=3600",
+		ContentMD5:   "1B2M2Y8AsgTpgAmY7PhCfg==",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	props, err := cli.GetBlobProperties("site", "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props.ContentType != "text/html" || props.CacheControl != "max-age=3600" || props.ContentMD5 != "1B2M2Y8AsgTpgAmY7PhCfg==" {
+		t.Errorf("Unexpected blob properties: %+v", props)
+	}
+
+	metadata := map[string]string{"author": "ops", "build": "42"}
+	if err := cli.SetBlobMetadata("site", "index.html", metadata); err != nil {
+		t.Fatal(err)
+	}
+	out, err := cli.GetBlobMetadata("site", "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, metadata) {
+		t.Fatalf("Expected metadata %v, got %v", metadata, out)
+	}
+}
+
+func TestBlobSnapshotsOffline(t *testing.T) {
+	const snapshot = "2015-06-01T10:00:00.0000000Z"
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.Method == "PUT" && q.Get("comp") == "snapshot":
+			if r.Header.Get("x-ms-meta-reason") != "backup" {
+				t.Errorf("Expected snapshot metadata, got headers %v", r.Header)
+			}
+			w.Header().Set("x-ms-snapshot", snapshot)
+			writeOfflineResponse(w, http.StatusCreated, "")
+		case r.Method == "GET" && q.Get("comp") == "list" && q.Get("include") == "snapshots":
+			writeOfflineResponse(w, http.StatusOK, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults>
+  <Blobs>
+    <Blob><Name>disk.vhd</Name><Snapshot>`+snapshot+`</Snapshot></Blob>
+    <Blob><Name>disk.vhd</Name></Blob>
+  </Blobs>
+</EnumerationResults>`)
+		case r.Method == "DELETE" && q.Get("snapshot") == snapshot:
+			writeOfflineResponse(w, http.StatusAccepted, "")
+		case r.Method == "DELETE" && r.Header.Get("x-ms-delete-snapshots") == "include":
+			writeOfflineResponse(w, http.StatusAccepted, "")
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			writeOfflineResponse(w, http.StatusBadRequest, "")
+		}
+	}).GetBlobService()
+
+	out, err := cli.SnapshotBlob("vhds", "disk.vhd", map[string]string{"reason": "backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != snapshot {
+		t.Fatalf("Expected snapshot %q, got %q", snapshot, out)
+	}
+
+	list, err := cli.ListBlobs("vhds", ListBlobsParameters{Include: "snapshots"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Blobs) != 2 || list.Blobs[0].Snapshot != snapshot || list.Blobs[1].Snapshot != "" {
+		t.Fatalf("Unexpected blobs: %+v", list.Blobs)
+	}
+
+	if err := cli.DeleteBlobSnapshot("vhds", "disk.vhd", snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.DeleteBlobWithSnapshots("vhds", "disk.vhd"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlobCopyOffline(t *testing.T) {
+	const source = "https://other.blob.core.windows.net/images/os.vhd?sig=abc"
+	polls := 0
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Query().Get("comp") == "":
+			if r.Header.Get("x-ms-copy-source") != source {
+				t.Errorf("Unexpected copy source %q", r.Header.Get("x-ms-copy-source"))
+			}
+			w.Header().Set("x-ms-copy-id", "copy-1")
+			writeOfflineResponse(w, http.StatusAccepted, "")
+		case r.Method == "HEAD":
+			polls++
+			w.Header().Set("x-ms-copy-id", "copy-1")
+			if polls < 3 {
+				w.Header().Set("x-ms-copy-status", BlobCopyStatusPending)
+				w.Header().Set("x-ms-copy-progress", fmt.Sprintf("%d/2048", polls*512))
+			} else {
+				w.Header().Set("x-ms-copy-status", BlobCopyStatusSuccess)
+				w.Header().Set("x-ms-copy-progress", "2048/2048")
+			}
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "PUT" && r.URL.Query().Get("comp") == "copy":
+			if r.URL.Query().Get("copyid") != "copy-1" || r.Header.Get("x-ms-copy-action") != "abort" {
+				t.Errorf("Unexpected abort request %s %v", r.URL, r.Header)
+			}
+			writeOfflineResponse(w, http.StatusNoContent, "")
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	}).GetBlobService()
+
+	id, err := cli.StartBlobCopy("vhds", "os.vhd", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "copy-1" {
+		t.Fatalf("Expected copy ID copy-1, got %q", id)
+	}
+
+	status, err := cli.GetCopyStatus("vhds", "os.vhd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != BlobCopyStatusPending || status.BytesCopied != 512 || status.BytesTotal != 2048 {
+		t.Fatalf("Unexpected copy status: %+v", status)
+	}
+
+	if err := cli.WaitForCopy(context.Background(), "vhds", "os.vhd", id, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 {
+		t.Errorf("Expected copy status to be polled until success, got %d polls", polls)
+	}
+
+	if err := cli.WaitForCopy(context.Background(), "vhds", "os.vhd", "copy-2", time.Millisecond); err != errBlobCopyIdMismatch {
+		t.Errorf("Expected copy ID mismatch, got %v", err)
+	}
+	if err := cli.AbortCopy("vhds", "os.vhd", id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForCopyHonorsContextOffline(t *testing.T) {
+	polls := 0
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Header().Set("x-ms-copy-id", "copy-1")
+		w.Header().Set("x-ms-copy-status", BlobCopyStatusPending)
+		writeOfflineResponse(w, http.StatusOK, "")
+	}).GetBlobService()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cli.WaitForCopy(ctx, "vhds", "os.vhd", "copy-1", time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Without an interval, polls are a second apart rather than back to back.
+	polls = 0
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := cli.WaitForCopy(ctx, "vhds", "os.vhd", "copy-1", 0); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if polls != 1 {
+		t.Errorf("Expected the status to be polled once before the context was done, got %d polls", polls)
+	}
+}
+
+func TestDeleteBlobWithOptionsOffline(t *testing.T) {
+	var header http.Header
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/vhds/disk.vhd" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		header = r.Header
+		if r.Header.Get("If-Match") == "stale" {
+			writeOfflineResponse(w, http.StatusPreconditionFailed, "")
+			return
+		}
+		writeOfflineResponse(w, http.StatusAccepted, "")
+	}).GetBlobService()
+
+	err := cli.DeleteBlobWithOptions("vhds", "disk.vhd", DeleteBlobOptions{
+		DeleteSnapshots: DeleteSnapshotsOnly,
+		LeaseID:         "lease-1",
+		Conditions:      AccessConditions{IfMatch: "etag"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{
+		"x-ms-delete-snapshots": "only",
+		"x-ms-lease-id":         "lease-1",
+		"If-Match":              "etag",
+	} {
+		if got := header.Get(k); got != v {
+			t.Errorf("Expected header %s to be %q, got %q", k, v, got)
+		}
+	}
+
+	err = cli.DeleteBlobWithOptions("vhds", "disk.vhd", DeleteBlobOptions{
+		DeleteSnapshots: DeleteSnapshotsInclude,
+		Conditions:      AccessConditions{IfMatch: "stale"},
+	})
+	if err == nil {
+		t.Fatal("Expected delete of a changed blob to fail")
+	}
+}
+
 func TestListBlobsPagination(t *testing.T) {
 	cli, err := getBlobClient()
 	if err != nil {
@@ -909,6 +1379,22 @@ func TestPutPageBlob(t *testing.T) {
 	}
 }
 
+func TestPageAlignmentOffline(t *testing.T) {
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}).GetBlobService()
+
+	if err := cli.PutPageBlob("vhds", "disk.vhd", 1000); err == nil {
+		t.Error("Expected unaligned page blob size to be rejected")
+	}
+	if err := cli.PutPage("vhds", "disk.vhd", 100, 611, PageWriteTypeUpdate, make([]byte, 512)); err == nil {
+		t.Error("Expected unaligned page range to be rejected")
+	}
+	if err := cli.PutPage("vhds", "disk.vhd", 0, 1023, PageWriteTypeUpdate, make([]byte, 512)); err == nil {
+		t.Error("Expected page range not matching the chunk to be rejected")
+	}
+}
+
 func TestPutPagesUpdate(t *testing.T) {
 	cli, err := getBlobClient()
 	if err != nil {
@@ -1070,6 +1556,53 @@ func TestGetPageRanges(t *testing.T) {
 	}
 }
 
+func TestContentMD5Offline(t *testing.T) {
+	var blockListMD5 string
+	content := "0123456789"
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			body, _ := ioutil.ReadAll(r.Body)
+			if r.URL.Query().Get("comp") == "blocklist" {
+				blockListMD5 = r.Header.Get("x-ms-blob-content-md5")
+			} else if got := r.Header.Get("Content-MD5"); got != contentMD5(body) {
+				t.Errorf("Expected Content-MD5 %q for %s, got %q", contentMD5(body), r.URL, got)
+			}
+			writeOfflineResponse(w, http.StatusCreated, "")
+		case "GET":
+			w.Header().Set("Content-MD5", contentMD5([]byte("corrupt")))
+			writeOfflineResponse(w, http.StatusOK, content)
+		}
+	}).GetBlobService()
+
+	if err := cli.PutBlob("images", "small", strings.NewReader(content), PutBlobOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.putBlockBlob("images", "large", strings.NewReader(content), 4); err != nil {
+		t.Fatal(err)
+	}
+	if blockListMD5 != contentMD5([]byte(content)) {
+		t.Errorf("Expected MD5 of the whole blob on the block list, got %q", blockListMD5)
+	}
+
+	body, err := cli.GetBlob("images", "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if _, err := ioutil.ReadAll(body); err != ErrContentMD5Mismatch {
+		t.Fatalf("Expected ErrContentMD5Mismatch, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := cli.WithoutContentMD5().GetBlobTo("images", "small", &buf, AccessConditions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != content {
+		t.Fatalf("Expected %q, got %q", content, buf.String())
+	}
+}
+
 func deleteTestContainers(cli *BlobStorageClient) error {
 	for {
 		resp, err := cli.ListContainers(ListContainersParameters{Prefix: testContainerPrefix})
@@ -1089,6 +1622,12 @@ func deleteTestContainers(cli *BlobStorageClient) error {
 	return nil
 }
 
+func writeOfflineResponse(w http.ResponseWriter, statusCode int, body string) {
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	w.WriteHeader(statusCode)
+	w.Write([]byte(body))
+}
+
 func getBlobClient() (*BlobStorageClient, error) {
 	name := os.Getenv("ACCOUNT_NAME")
 	if name == "" {
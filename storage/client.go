@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -22,6 +23,8 @@ const (
 	blobServiceName  = "blob"
 	tableServiceName = "table"
 	queueServiceName = "queue"
+
+	userDefinedMetadataHeaderPrefix = "x-ms-meta-"
 )
 
 // StorageClient is the object that needs to be constructed
@@ -32,6 +35,14 @@ type StorageClient struct {
 	useHttps    bool
 	baseUrl     string
 	apiVersion  string
+
+	// endpoints overrides the base URL of the services it has, by service
+	// name.
+	endpoints map[string]string
+
+	// fallbackApiVersions are tried in turn when the service rejects
+	// apiVersion. See WithFallbackApiVersions.
+	fallbackApiVersions []string
 }
 
 type storageResponse struct {
@@ -50,6 +61,7 @@ type StorageServiceError struct {
 	QueryParameterName        string `xml:"QueryParameterName"`
 	QueryParameterValue       string `xml:"QueryParameterValue"`
 	Reason                    string `xml:"Reason"`
+	HeaderName                string `xml:"HeaderName"`
 	StatusCode                int
 	RequestId                 string
 }
@@ -87,7 +99,49 @@ func NewClient(accountName, accountKey, blobServiceBaseUrl, apiVersion string, u
 	}, nil
 }
 
+// NewClientFromBlobEndpoint constructs a StorageClient for the storage
+// account whose blob service is at blobEndpoint, such as the endpoint returned
+// by GetBlobEndpoint of package storageservice. Accounts of sovereign clouds
+// and custom environments are addressed through their own endpoints this way:
+// the base URL of the other services is derived from the host name of the
+// blob endpoint when it has the account.blob.base-url form. HTTPS is used if
+// the scheme of the endpoint is https.
+func NewClientFromBlobEndpoint(accountName, accountKey, blobEndpoint string) (StorageClient, error) {
+	u, err := url.Parse(strings.TrimSpace(blobEndpoint))
+	if err != nil || u.Host == "" {
+		return StorageClient{}, fmt.Errorf("azure: invalid blob endpoint %q", blobEndpoint)
+	}
+
+	baseUrl := DefaultBaseUrl
+	prefix := strings.ToLower(accountName + "." + blobServiceName + ".")
+	if strings.HasPrefix(strings.ToLower(u.Host), prefix) {
+		baseUrl = u.Host[len(prefix):]
+	}
+
+	c, err := NewClient(accountName, accountKey, baseUrl, DefaultApiVersion, u.Scheme == "https")
+	if err != nil {
+		return c, err
+	}
+
+	c.endpoints = map[string]string{blobServiceName: (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()}
+	return c, nil
+}
+
+// WithFallbackApiVersions returns a copy of the client that resends a
+// request rejected because the service does not support the x-ms-version
+// of the client, such as older emulators and on-premises deployments, with
+// each of versions in turn, newest first. Requests whose body cannot be
+// rewound are not resent.
+func (c StorageClient) WithFallbackApiVersions(versions ...string) StorageClient {
+	c.fallbackApiVersions = append([]string(nil), versions...)
+	return c
+}
+
 func (c StorageClient) getBaseUrl(service string) string {
+	if endpoint, ok := c.endpoints[service]; ok {
+		return endpoint
+	}
+
 	scheme := "http"
 	if c.useHttps {
 		scheme = "https"
@@ -120,7 +174,7 @@ func (c StorageClient) getEndpoint(service, path string, params url.Values) stri
 // GetBlobService returns a BlobStorageClient which can operate on the
 // blob service of the storage account.
 func (c StorageClient) GetBlobService() *BlobStorageClient {
-	return &BlobStorageClient{c}
+	return &BlobStorageClient{client: c}
 }
 
 func (c StorageClient) createAuthorizationHeader(canonicalizedString string) string {
@@ -229,10 +283,10 @@ func (c StorageClient) buildCanonicalizedString(verb string, headers map[string]
 		headers["Content-MD5"],
 		headers["Content-Type"],
 		headers["Date"],
-		headers["If-Modified-Singe"],
+		headers["If-Modified-Since"],
 		headers["If-Match"],
 		headers["If-None-Match"],
-		headers["If-Unmodified-Singe"],
+		headers["If-Unmodified-Since"],
 		headers["Range"],
 		c.buildCanonicalizedHeader(headers),
 		canonicalizedResource)
@@ -241,17 +295,55 @@ func (c StorageClient) buildCanonicalizedString(verb string, headers map[string]
 }
 
 func (c StorageClient) exec(verb, url string, headers map[string]string, body io.Reader) (*storageResponse, error) {
+	resp, err := c.execOnce(verb, url, headers, body)
+	for _, version := range c.fallbackApiVersions {
+		if !isVersionNotSupported(err) || !rewindBody(body) {
+			break
+		}
+		headers["x-ms-version"] = version
+		resp, err = c.execOnce(verb, url, headers, body)
+	}
+
+	return resp, err
+}
+
+// isVersionNotSupported reports whether err is the error of a request whose
+// x-ms-version the service does not support.
+func isVersionNotSupported(err error) bool {
+	storageErr, ok := err.(StorageServiceError)
+	return ok && storageErr.StatusCode == http.StatusBadRequest &&
+		storageErr.Code == "InvalidHeaderValue" && strings.EqualFold(storageErr.HeaderName, "x-ms-version")
+}
+
+// rewindBody prepares body to be sent again, returning false if it cannot be.
+func rewindBody(body io.Reader) bool {
+	if body == nil {
+		return true
+	}
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	return err == nil
+}
+
+func (c StorageClient) execOnce(verb, url string, headers map[string]string, body io.Reader) (*storageResponse, error) {
 	authHeader, err := c.getAuthorizationHeader(verb, url, headers)
 	if err != nil {
 		return nil, err
 	}
 	headers["Authorization"] = authHeader
 
+	return c.send(verb, url, headers, body)
+}
+
+// send sends a request that is already signed.
+func (c StorageClient) send(verb, url string, headers map[string]string, body io.Reader) (*storageResponse, error) {
+	req, err := http.NewRequest(verb, url, body)
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest(verb, url, body)
 	for k, v := range headers {
 		req.Header.Add(k, v)
 	}
@@ -272,6 +364,9 @@ func (c StorageClient) exec(verb, url string, headers map[string]string, body io
 		if len(respBody) == 0 {
 			// no error in response body
 			err = fmt.Errorf("storage: service returned without a response body (%s).", resp.Status)
+		} else if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+			// the table service responds with JSON to JSON requests
+			err = serviceErrFromJson(respBody, resp.StatusCode, resp.Header.Get("x-ms-request-id"))
 		} else {
 			// response contains storage service error object, unmarshal
 			storageErr, errIn := serviceErrFromXml(respBody, resp.StatusCode, resp.Header.Get("x-ms-request-id"))
@@ -312,6 +407,32 @@ func serviceErrFromXml(body []byte, statusCode int, requestId string) (StorageSe
 	return storageErr, nil
 }
 
+func serviceErrFromJson(body []byte, statusCode int, requestId string) error {
+	var odataErr struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message struct {
+				Value string `json:"value"`
+			} `json:"message"`
+		} `json:"odata.error"`
+	}
+	if err := json.Unmarshal(body, &odataErr); err != nil {
+		return err
+	}
+	return StorageServiceError{
+		Code:       odataErr.Error.Code,
+		Message:    odataErr.Error.Message.Value,
+		StatusCode: statusCode,
+		RequestId:  requestId,
+	}
+}
+
+// HTTPStatusCode returns the HTTP status of the error response, for the error
+// classification helpers of package management, such as IsNotFound.
+func (e StorageServiceError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
 func (e StorageServiceError) Error() string {
 	return fmt.Sprintf("storage: remote server returned error. StatusCode=%d, ErrorCode=%s, ErrorMessage=%s, RequestId=%s", e.StatusCode, e.Code, e.Message, e.RequestId)
 }
@@ -2,7 +2,12 @@ package storage
 
 import (
 	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -201,3 +206,76 @@ func Test_createAuthorizationHeader(t *testing.T) {
 		t.Fatalf("Wrong authorization header. Expected: '%s', Got:'%s'", expected, out)
 	}
 }
+
+func TestNewClientFromBlobEndpoint(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("bar"))
+	cli, err := NewClientFromBlobEndpoint("foo", key, "https://foo.blob.core.chinacloudapi.cn/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := cli.getBaseUrl(blobServiceName); out != "https://foo.blob.core.chinacloudapi.cn" {
+		t.Errorf("Wrong blob base url. Got: '%s'", out)
+	}
+	if out := cli.getBaseUrl(tableServiceName); out != "https://foo.table.core.chinacloudapi.cn" {
+		t.Errorf("Wrong table base url. Got: '%s'", out)
+	}
+
+	if _, err := NewClientFromBlobEndpoint("foo", key, "not an endpoint"); err == nil {
+		t.Error("Expected an invalid endpoint to be rejected")
+	}
+}
+
+func TestFallbackApiVersions(t *testing.T) {
+	var versions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := r.Header.Get("x-ms-version")
+		versions = append(versions, version)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey foo:") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if version != "2013-08-15" {
+			body := `<?xml version="1.0" encoding="utf-8"?><Error><Code>InvalidHeaderValue</Code><Message>The value for one of the HTTP headers is not in the correct format.</Message><HeaderName>x-ms-version</HeaderName><HeaderValue>` + version + `</HeaderValue></Error>`
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cli, err := NewClientFromBlobEndpoint("foo", base64.StdEncoding.EncodeToString([]byte("bar")), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.GetBlobService().CreateContainer("container", ""); err == nil {
+		t.Fatal("Expected the version to be rejected without fallback versions")
+	}
+
+	versions = nil
+	cli = cli.WithFallbackApiVersions("2013-08-15")
+	if err := cli.GetBlobService().CreateContainer("container", ""); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{DefaultApiVersion, "2013-08-15"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, versions)
+	}
+}
+
+// newOfflineClient returns a client whose requests to service are sent to a
+// test server running handler. The server is closed when the test ends.
+func newOfflineClient(t *testing.T, service string, handler http.HandlerFunc) StorageClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cli, err := NewBasicClient("foo", "YmFy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.endpoints = map[string]string{service: server.URL}
+	return cli
+}
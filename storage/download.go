@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+var errInvalidSeek = errors.New("storage: seek to a negative position")
+
+// maxRangeContentMD5Size is the size of the largest range the service
+// computes the MD5 hash of.
+const maxRangeContentMD5Size = 4 * 1024 * 1024
+
+// BlobReader reads a blob with ranged requests, allowing to process parts of
+// a large blob without downloading all of it. Each call to Read or ReadAt
+// makes one request, so small reads should be buffered, for example with
+// bufio. Reads fail once the blob changes after the reader is created, and
+// reads of up to 4 MB are validated against their MD5 hash.
+type BlobReader struct {
+	client    BlobStorageClient
+	container string
+	name      string
+	size      int64
+	etag      string
+	offset    int64
+}
+
+// NewBlobReader returns a reader of the current content of a blob.
+func (b BlobStorageClient) NewBlobReader(container, name string) (*BlobReader, error) {
+	props, err := b.GetBlobProperties(container, name)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobReader{
+		client:    b,
+		container: container,
+		name:      name,
+		size:      props.ContentLength,
+		etag:      props.Etag,
+	}, nil
+}
+
+// Size returns the size of the blob in bytes.
+func (r *BlobReader) Size() int64 {
+	return r.size
+}
+
+// Read implements io.Reader.
+func (r *BlobReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (r *BlobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return r.offset, fmt.Errorf("storage: invalid seek whence %d", whence)
+	}
+	if offset < 0 {
+		return r.offset, errInvalidSeek
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// ReadAt implements io.ReaderAt. It is safe for concurrent use.
+func (r *BlobReader) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errInvalidSeek
+	}
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	var err error
+	if remaining := r.size - offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+		err = io.EOF
+	}
+	n, rerr := r.client.readBlobRange(r.container, r.name, offset, p, r.etag)
+	if rerr != nil {
+		return n, rerr
+	}
+	return n, err
+}
+
+// readBlobRange reads len(p) bytes of a blob starting at offset into p. If
+// etag is given, the read fails once the blob changed.
+func (b BlobStorageClient) readBlobRange(container, name string, offset int64, p []byte, etag string) (int, error) {
+	uri := b.client.getEndpoint(blobServiceName, pathForBlob(container, name), url.Values{})
+
+	headers := b.client.getStandardHeaders()
+	headers["Range"] = fmt.Sprintf("bytes=%d-%d", offset, offset+int64(len(p))-1)
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+	if !b.skipContentMD5 && len(p) <= maxRangeContentMD5Size {
+		headers["x-ms-range-get-content-md5"] = "true"
+	}
+	resp, err := b.client.exec("GET", uri, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf(errUnexpectedStatus, http.StatusPartialContent, resp.statusCode)
+	}
+	n, err := io.ReadFull(resp.body, p)
+	if err != nil {
+		return n, err
+	}
+	if expected := resp.headers.Get("Content-MD5"); !b.skipContentMD5 && expected != "" && contentMD5(p) != expected {
+		return n, ErrContentMD5Mismatch
+	}
+	return n, nil
+}
+
+// DownloadOptions configures a download with DownloadBlob.
+type DownloadOptions struct {
+	// BlockSize is the size of the ranges the blob is downloaded in. Zero
+	// or less means MaxBlobBlockSize.
+	BlockSize int
+
+	// Workers is the number of ranges downloaded concurrently. Zero or
+	// less means DefaultUploadWorkers.
+	Workers int
+}
+
+// DownloadBlob downloads a blob into w, such as an *os.File, downloading
+// ranges of it concurrently, and returns the size of the blob. The first
+// failing range stops the download and its error is returned.
+func (b BlobStorageClient) DownloadBlob(container, name string, w io.WriterAt, options DownloadOptions) (int64, error) {
+	r, err := b.NewBlobReader(container, name)
+	if err != nil {
+		return 0, err
+	}
+
+	blockSize := int64(options.BlockSize)
+	if blockSize <= 0 {
+		blockSize = MaxBlobBlockSize
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultUploadWorkers
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	next := make(chan int64)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, blockSize)
+			for offset := range next {
+				n, err := r.ReadAt(buf, offset)
+				if err == io.EOF {
+					err = nil
+				}
+				if err == nil {
+					_, err = w.WriteAt(buf[:n], offset)
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for offset := int64(0); offset < r.size && !failed(); offset += blockSize {
+		next <- offset
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return r.size, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newOfflineBlobReaderClient(t *testing.T, content []byte, etag *string) *BlobStorageClient {
+	return newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/logs/build.log" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Header().Set("ETag", *etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}).GetBlobService()
+}
+
+func TestBlobReaderOffline(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	etag := `"v1"`
+	cli := newOfflineBlobReaderClient(t, content, &etag)
+
+	r, err := cli.NewBlobReader("logs", "build.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Size() != int64(len(content)) {
+		t.Fatalf("Expected size %d, got %d", len(content), r.Size())
+	}
+
+	if _, err := r.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "fghij" {
+		t.Fatalf("Expected the last 5 bytes, got %q", out)
+	}
+
+	p := make([]byte, 4)
+	if n, err := r.ReadAt(p, 10); err != nil || string(p[:n]) != "abcd" {
+		t.Fatalf("Expected abcd at offset 10, got %q (%v)", p[:n], err)
+	}
+	if n, err := r.ReadAt(p, 18); err != io.EOF || string(p[:n]) != "ij" {
+		t.Fatalf("Expected ij and io.EOF at offset 18, got %q (%v)", p[:n], err)
+	}
+
+	etag = `"v2"`
+	if _, err := r.ReadAt(p, 0); err == nil {
+		t.Fatal("Expected reading a changed blob to fail")
+	}
+}
+
+func TestDownloadBlobOffline(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	etag := `"v1"`
+	cli := newOfflineBlobReaderClient(t, content, &etag)
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f, err := os.Create(filepath.Join(dir, "build.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	n, err := cli.DownloadBlob("logs", "build.log", f, DownloadOptions{BlockSize: 64, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Fatalf("Expected %d bytes, got %d", len(content), n)
+	}
+	out, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, content) {
+		t.Fatal("Downloaded blob does not match its content")
+	}
+}
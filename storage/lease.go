@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// InfiniteLeaseDuration is the lease duration of a lease that lasts until it
+// is released or broken.
+const InfiniteLeaseDuration = -1
+
+const (
+	leaseActionAcquire = "acquire"
+	leaseActionRenew   = "renew"
+	leaseActionChange  = "change"
+	leaseActionRelease = "release"
+	leaseActionBreak   = "break"
+)
+
+// AcquireLease acquires a lease on a blob for duration seconds, between 15
+// and 60, or InfiniteLeaseDuration, and returns the ID of the lease.
+// proposedLeaseID may be given to choose the ID. See
+// https://msdn.microsoft.com/en-us/library/azure/ee691972.aspx
+func (b BlobStorageClient) AcquireLease(container, name string, duration int, proposedLeaseID string) (string, error) {
+	return b.acquireLease(pathForBlob(container, name), url.Values{}, duration, proposedLeaseID)
+}
+
+// RenewLease restarts the duration of the lease on a blob.
+func (b BlobStorageClient) RenewLease(container, name, leaseID string) error {
+	return b.renewLease(pathForBlob(container, name), url.Values{}, leaseID)
+}
+
+// ChangeLease changes the ID of the lease on a blob to proposedLeaseID, and
+// returns the new ID.
+func (b BlobStorageClient) ChangeLease(container, name, leaseID, proposedLeaseID string) (string, error) {
+	return b.changeLease(pathForBlob(container, name), url.Values{}, leaseID, proposedLeaseID)
+}
+
+// ReleaseLease releases the lease on a blob so that another client can
+// acquire it right away.
+func (b BlobStorageClient) ReleaseLease(container, name, leaseID string) error {
+	return b.releaseLease(pathForBlob(container, name), url.Values{}, leaseID)
+}
+
+// BreakLease breaks the lease on a blob, whatever its ID, and returns the
+// number of seconds until the lease is broken. The lease is broken once its
+// duration or breakPeriod seconds elapse, whichever is first; a negative
+// breakPeriod waits for the duration, or breaks an infinite lease right away.
+// Breaking the lease with a breakPeriod of 0 allows to delete a blob leased
+// by a client that is gone, such as the disk of a deleted virtual machine.
+func (b BlobStorageClient) BreakLease(container, name string, breakPeriod int) (int, error) {
+	return b.breakLease(pathForBlob(container, name), url.Values{}, breakPeriod)
+}
+
+// AcquireContainerLease acquires a lease on a container, as AcquireLease
+// does on a blob. A leased container can only be deleted with its lease ID.
+// See https://msdn.microsoft.com/en-us/library/azure/jj159103.aspx
+func (b BlobStorageClient) AcquireContainerLease(container string, duration int, proposedLeaseID string) (string, error) {
+	return b.acquireLease(pathForContainer(container), containerLeaseParams(), duration, proposedLeaseID)
+}
+
+// RenewContainerLease restarts the duration of the lease on a container.
+func (b BlobStorageClient) RenewContainerLease(container, leaseID string) error {
+	return b.renewLease(pathForContainer(container), containerLeaseParams(), leaseID)
+}
+
+// ChangeContainerLease changes the ID of the lease on a container to
+// proposedLeaseID, and returns the new ID.
+func (b BlobStorageClient) ChangeContainerLease(container, leaseID, proposedLeaseID string) (string, error) {
+	return b.changeLease(pathForContainer(container), containerLeaseParams(), leaseID, proposedLeaseID)
+}
+
+// ReleaseContainerLease releases the lease on a container.
+func (b BlobStorageClient) ReleaseContainerLease(container, leaseID string) error {
+	return b.releaseLease(pathForContainer(container), containerLeaseParams(), leaseID)
+}
+
+// BreakContainerLease breaks the lease on a container, as BreakLease does on
+// a blob.
+func (b BlobStorageClient) BreakContainerLease(container string, breakPeriod int) (int, error) {
+	return b.breakLease(pathForContainer(container), containerLeaseParams(), breakPeriod)
+}
+
+func containerLeaseParams() url.Values {
+	return url.Values{"restype": {"container"}}
+}
+
+func (b BlobStorageClient) acquireLease(path string, params url.Values, duration int, proposedLeaseID string) (string, error) {
+	headers := map[string]string{
+		"x-ms-lease-duration": strconv.Itoa(duration),
+	}
+	if proposedLeaseID != "" {
+		headers["x-ms-proposed-lease-id"] = proposedLeaseID
+	}
+	resp, err := b.lease(path, params, leaseActionAcquire, headers, http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+	return resp.Get("x-ms-lease-id"), nil
+}
+
+func (b BlobStorageClient) renewLease(path string, params url.Values, leaseID string) error {
+	_, err := b.lease(path, params, leaseActionRenew, map[string]string{"x-ms-lease-id": leaseID}, http.StatusOK)
+	return err
+}
+
+func (b BlobStorageClient) changeLease(path string, params url.Values, leaseID, proposedLeaseID string) (string, error) {
+	headers := map[string]string{
+		"x-ms-lease-id":          leaseID,
+		"x-ms-proposed-lease-id": proposedLeaseID,
+	}
+	resp, err := b.lease(path, params, leaseActionChange, headers, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+	return resp.Get("x-ms-lease-id"), nil
+}
+
+func (b BlobStorageClient) releaseLease(path string, params url.Values, leaseID string) error {
+	_, err := b.lease(path, params, leaseActionRelease, map[string]string{"x-ms-lease-id": leaseID}, http.StatusOK)
+	return err
+}
+
+func (b BlobStorageClient) breakLease(path string, params url.Values, breakPeriod int) (int, error) {
+	headers := map[string]string{}
+	if breakPeriod >= 0 {
+		headers["x-ms-lease-break-period"] = strconv.Itoa(breakPeriod)
+	}
+	resp, err := b.lease(path, params, leaseActionBreak, headers, http.StatusAccepted)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(resp.Get("x-ms-lease-time"))
+}
+
+// lease performs a Lease Blob or Lease Container call with the given action
+// and returns the headers of its response.
+func (b BlobStorageClient) lease(path string, params url.Values, action string, leaseHeaders map[string]string, expectedStatus int) (http.Header, error) {
+	uri := b.client.getEndpoint(blobServiceName, path, mergeParams(params, url.Values{"comp": {"lease"}}))
+
+	headers := b.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+	headers["x-ms-lease-action"] = action
+	for k, v := range leaseHeaders {
+		headers[k] = v
+	}
+
+	resp, err := b.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != expectedStatus {
+		return nil, fmt.Errorf(errUnexpectedStatus, expectedStatus, resp.statusCode)
+	}
+	return resp.headers, nil
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"net/http"
+	"testing"
+)
+
+// leaseServer serves the lease of a single blob or container.
+type leaseServer struct {
+	t       *testing.T
+	leaseID string
+	path    string
+	restype string
+}
+
+func (s *leaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if r.Method != "PUT" || r.URL.Path != s.path || q.Get("comp") != "lease" || q.Get("restype") != s.restype {
+		s.t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}
+
+	leaseID := r.Header.Get("x-ms-lease-id")
+	conflict := func() bool {
+		if leaseID != s.leaseID {
+			writeOfflineResponse(w, http.StatusConflict, "")
+			return true
+		}
+		return false
+	}
+	switch r.Header.Get("x-ms-lease-action") {
+	case "acquire":
+		if s.leaseID != "" {
+			writeOfflineResponse(w, http.StatusConflict, "")
+			return
+		}
+		s.leaseID = r.Header.Get("x-ms-proposed-lease-id")
+		w.Header().Set("x-ms-lease-id", s.leaseID)
+		writeOfflineResponse(w, http.StatusCreated, "")
+	case "renew":
+		if !conflict() {
+			writeOfflineResponse(w, http.StatusOK, "")
+		}
+	case "change":
+		if !conflict() {
+			s.leaseID = r.Header.Get("x-ms-proposed-lease-id")
+			w.Header().Set("x-ms-lease-id", s.leaseID)
+			writeOfflineResponse(w, http.StatusOK, "")
+		}
+	case "release":
+		if !conflict() {
+			s.leaseID = ""
+			writeOfflineResponse(w, http.StatusOK, "")
+		}
+	case "break":
+		if r.Header.Get("x-ms-lease-break-period") != "0" {
+			s.t.Errorf("Expected a break period of 0, got headers %v", r.Header)
+		}
+		s.leaseID = ""
+		w.Header().Set("x-ms-lease-time", "0")
+		writeOfflineResponse(w, http.StatusAccepted, "")
+	}
+}
+
+func TestBlobLeaseOffline(t *testing.T) {
+	cli := newOfflineClient(t, blobServiceName, (&leaseServer{t: t, path: "/vhds/disk.vhd"}).ServeHTTP).GetBlobService()
+
+	id, err := cli.AcquireLease("vhds", "disk.vhd", InfiniteLeaseDuration, "lease-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "lease-1" {
+		t.Fatalf("Expected lease ID lease-1, got %q", id)
+	}
+	if _, err := cli.AcquireLease("vhds", "disk.vhd", 60, ""); err == nil {
+		t.Fatal("Expected acquiring a leased blob to fail")
+	}
+	if err := cli.RenewLease("vhds", "disk.vhd", id); err != nil {
+		t.Fatal(err)
+	}
+	if id, err = cli.ChangeLease("vhds", "disk.vhd", id, "lease-2"); err != nil {
+		t.Fatal(err)
+	}
+	if id != "lease-2" {
+		t.Fatalf("Expected lease ID lease-2, got %q", id)
+	}
+	if err := cli.ReleaseLease("vhds", "disk.vhd", "lease-1"); err == nil {
+		t.Fatal("Expected releasing with an old lease ID to fail")
+	}
+	if err := cli.ReleaseLease("vhds", "disk.vhd", id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.AcquireLease("vhds", "disk.vhd", 15, "lease-3"); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := cli.BreakLease("vhds", "disk.vhd", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Fatalf("Expected lease to be broken right away, got %d seconds", remaining)
+	}
+}
+
+func TestContainerLeaseOffline(t *testing.T) {
+	cli := newOfflineClient(t, blobServiceName, (&leaseServer{t: t, path: "/vhds", restype: "container"}).ServeHTTP).GetBlobService()
+
+	id, err := cli.AcquireContainerLease("vhds", 30, "lease-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.RenewContainerLease("vhds", id); err != nil {
+		t.Fatal(err)
+	}
+	if id, err = cli.ChangeContainerLease("vhds", id, "lease-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.ReleaseContainerLease("vhds", id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.AcquireContainerLease("vhds", 30, "lease-3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.BreakContainerLease("vhds", 0); err != nil {
+		t.Fatal(err)
+	}
+}
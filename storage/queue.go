@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// QueueServiceClient contains operations for the queue service of a
+// storage account, which stores messages in queues for asynchronous
+// processing.
+type QueueServiceClient struct {
+	client StorageClient
+}
+
+// QueueMessage is a message of a queue. MessageText is the content of the
+// message as stored by the service; messages with binary content should be
+// base64 encoded by the caller.
+type QueueMessage struct {
+	MessageId       string `xml:"MessageId"`
+	InsertionTime   string `xml:"InsertionTime"`
+	ExpirationTime  string `xml:"ExpirationTime"`
+	PopReceipt      string `xml:"PopReceipt"`
+	TimeNextVisible string `xml:"TimeNextVisible"`
+	DequeueCount    int    `xml:"DequeueCount"`
+	MessageText     string `xml:"MessageText"`
+}
+
+// QueueMessagesList contains the response fields from the GetMessages and
+// PeekMessages calls.
+type QueueMessagesList struct {
+	XMLName  xml.Name       `xml:"QueueMessagesList"`
+	Messages []QueueMessage `xml:"QueueMessage"`
+}
+
+// PutMessageOptions configures a message put with PutMessage.
+type PutMessageOptions struct {
+	// VisibilityTimeout is the time the message is invisible after it is
+	// put. Zero means the message is visible at once.
+	VisibilityTimeout time.Duration
+
+	// MessageTTL is the time the message is kept in the queue. Zero means
+	// the default of the service, 7 days.
+	MessageTTL time.Duration
+}
+
+// GetMessagesOptions configures the messages retrieved with GetMessages.
+type GetMessagesOptions struct {
+	// NumOfMessages is the number of messages retrieved, up to 32. Zero
+	// means a single message.
+	NumOfMessages int
+
+	// VisibilityTimeout is the time the messages are invisible to other
+	// consumers after they are retrieved. Zero means the default of the
+	// service, 30 seconds.
+	VisibilityTimeout time.Duration
+}
+
+var validQueueName = regexp.MustCompile("^[a-z0-9](-?[a-z0-9])+$")
+
+// GetQueueService returns a QueueServiceClient which can operate on the
+// queue service of the storage account.
+func (c StorageClient) GetQueueService() *QueueServiceClient {
+	return &QueueServiceClient{client: c}
+}
+
+// CreateQueue creates a queue with the given name. Queue names are 3 to 63
+// lowercase letters, numbers and hyphens, beginning and ending with a letter
+// or a number, without consecutive hyphens. Creating a queue that already
+// exists without metadata succeeds. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179342.aspx
+func (q QueueServiceClient) CreateQueue(name string) error {
+	if len(name) < 3 || len(name) > 63 || !validQueueName.MatchString(name) {
+		return fmt.Errorf("storage: invalid queue name %q", name)
+	}
+
+	uri := q.client.getEndpoint(queueServiceName, pathForQueue(name), url.Values{})
+	headers := q.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+
+	resp, err := q.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusCreated && resp.statusCode != http.StatusNoContent {
+		return ErrNotCreated
+	}
+	return nil
+}
+
+// DeleteQueue deletes the queue with the given name and all of its
+// messages. See https://msdn.microsoft.com/en-us/library/azure/dd179436.aspx
+func (q QueueServiceClient) DeleteQueue(name string) error {
+	uri := q.client.getEndpoint(queueServiceName, pathForQueue(name), url.Values{})
+	headers := q.client.getStandardHeaders()
+
+	resp, err := q.client.exec("DELETE", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+// GetApproximateMessageCount returns the approximate number of messages of
+// the queue with the given name, which is not lower than the actual number.
+// See https://msdn.microsoft.com/en-us/library/azure/dd179384.aspx
+func (q QueueServiceClient) GetApproximateMessageCount(name string) (int, error) {
+	uri := q.client.getEndpoint(queueServiceName, pathForQueue(name), url.Values{"comp": {"metadata"}})
+	headers := q.client.getStandardHeaders()
+
+	resp, err := q.client.exec("GET", uri, headers, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return 0, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	return strconv.Atoi(resp.headers.Get("x-ms-approximate-messages-count"))
+}
+
+// PutMessage adds a message with the given text to the back of a queue. The
+// text, encoded as UTF-8 and escaped as XML, can be up to 64 KB. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179346.aspx
+func (q QueueServiceClient) PutMessage(queue, text string, options PutMessageOptions) error {
+	params := url.Values{}
+	if options.VisibilityTimeout > 0 {
+		params.Set("visibilitytimeout", durationSeconds(options.VisibilityTimeout))
+	}
+	if options.MessageTTL > 0 {
+		params.Set("messagettl", durationSeconds(options.MessageTTL))
+	}
+	uri := q.client.getEndpoint(queueServiceName, pathForQueueMessages(queue), params)
+
+	body, err := xml.Marshal(struct {
+		XMLName     xml.Name `xml:"QueueMessage"`
+		MessageText string   `xml:"MessageText"`
+	}{MessageText: text})
+	if err != nil {
+		return err
+	}
+	headers := q.client.getStandardHeaders()
+	headers["Content-Length"] = strconv.Itoa(len(body))
+
+	resp, err := q.client.exec("POST", uri, headers, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusCreated {
+		return ErrNotCreated
+	}
+	return nil
+}
+
+// GetMessages retrieves messages from the front of a queue and makes them
+// invisible to other consumers for the visibility timeout. A message should
+// be deleted with DeleteMessage once processed, or it becomes visible again.
+// An empty queue returns no messages. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179474.aspx
+func (q QueueServiceClient) GetMessages(queue string, options GetMessagesOptions) ([]QueueMessage, error) {
+	params := url.Values{}
+	if options.NumOfMessages > 0 {
+		params.Set("numofmessages", strconv.Itoa(options.NumOfMessages))
+	}
+	if options.VisibilityTimeout > 0 {
+		params.Set("visibilitytimeout", durationSeconds(options.VisibilityTimeout))
+	}
+	return q.getMessages(queue, params)
+}
+
+// PeekMessages returns up to numOfMessages messages from the front of a
+// queue without changing their visibility. Peeked messages have no pop
+// receipt and cannot be deleted or updated. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179472.aspx
+func (q QueueServiceClient) PeekMessages(queue string, numOfMessages int) ([]QueueMessage, error) {
+	params := url.Values{"peekonly": {"true"}}
+	if numOfMessages > 0 {
+		params.Set("numofmessages", strconv.Itoa(numOfMessages))
+	}
+	return q.getMessages(queue, params)
+}
+
+func (q QueueServiceClient) getMessages(queue string, params url.Values) ([]QueueMessage, error) {
+	uri := q.client.getEndpoint(queueServiceName, pathForQueueMessages(queue), params)
+	headers := q.client.getStandardHeaders()
+
+	resp, err := q.client.exec("GET", uri, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return nil, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+	var out QueueMessagesList
+	if err := xmlUnmarshal(resp.body, &out); err != nil {
+		return nil, err
+	}
+	return out.Messages, nil
+}
+
+// UpdateMessageVisibility makes a retrieved message invisible for
+// visibilityTimeout from now, extending or shortening the time it is being
+// processed, and sets the new pop receipt and next visible time of message,
+// which are required to delete or update it again. See
+// https://msdn.microsoft.com/en-us/library/azure/hh452234.aspx
+func (q QueueServiceClient) UpdateMessageVisibility(queue string, message *QueueMessage, visibilityTimeout time.Duration) error {
+	params := url.Values{
+		"popreceipt":        {message.PopReceipt},
+		"visibilitytimeout": {durationSeconds(visibilityTimeout)},
+	}
+	uri := q.client.getEndpoint(queueServiceName, pathForQueueMessage(queue, message.MessageId), params)
+	headers := q.client.getStandardHeaders()
+	headers["Content-Length"] = "0"
+
+	resp, err := q.client.exec("PUT", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	message.PopReceipt = resp.headers.Get("x-ms-popreceipt")
+	message.TimeNextVisible = resp.headers.Get("x-ms-time-next-visible")
+	return nil
+}
+
+// DeleteMessage deletes a retrieved message from a queue, given its pop
+// receipt from the latest retrieval or update. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179347.aspx
+func (q QueueServiceClient) DeleteMessage(queue, messageId, popReceipt string) error {
+	uri := q.client.getEndpoint(queueServiceName, pathForQueueMessage(queue, messageId), url.Values{"popreceipt": {popReceipt}})
+	headers := q.client.getStandardHeaders()
+
+	resp, err := q.client.exec("DELETE", uri, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+func pathForQueue(name string) string {
+	return fmt.Sprintf("/%s", name)
+}
+
+func pathForQueueMessages(queue string) string {
+	return fmt.Sprintf("/%s/messages", queue)
+}
+
+func pathForQueueMessage(queue, messageId string) string {
+	return fmt.Sprintf("/%s/messages/%s", queue, messageId)
+}
+
+// durationSeconds returns d as the whole number of seconds the queue service
+// expects in timeouts.
+func durationSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeQueueServer stores the messages of queues in memory, ignoring
+// visibility timeouts other than zero, which makes a message visible again.
+type fakeQueueServer struct {
+	t        *testing.T
+	queues   map[string][]*QueueMessage
+	visible  map[string]bool
+	requests []string
+	nextId   int
+}
+
+func newFakeQueueServer(t *testing.T) *fakeQueueServer {
+	return &fakeQueueServer{t: t, queues: map[string][]*QueueMessage{}, visible: map[string]bool{}}
+}
+
+func (s *fakeQueueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey foo:") {
+		s.t.Errorf("Expected request signed with Shared Key, got %q", r.Header.Get("Authorization"))
+	}
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	queue, ok := s.queues[parts[0]]
+	if !ok && !(r.Method == "PUT" && len(parts) == 1) {
+		writeOfflineResponse(w, http.StatusNotFound, "<?xml version=\"1.0\" encoding=\"utf-8\"?><Error><Code>QueueNotFound</Code><Message>The specified queue does not exist.</Message></Error>")
+		return
+	}
+	query := r.URL.Query()
+
+	switch {
+	case len(parts) == 1 && r.Method == "PUT":
+		s.queues[parts[0]] = nil
+		writeOfflineResponse(w, http.StatusCreated, "")
+	case len(parts) == 1 && r.Method == "DELETE":
+		delete(s.queues, parts[0])
+		writeOfflineResponse(w, http.StatusNoContent, "")
+	case len(parts) == 1 && query.Get("comp") == "metadata":
+		w.Header().Set("x-ms-approximate-messages-count", strconv.Itoa(len(queue)))
+		writeOfflineResponse(w, http.StatusOK, "")
+	case len(parts) == 2 && r.Method == "POST":
+		var in QueueMessage
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := xml.Unmarshal(body, &in); err != nil {
+			s.t.Errorf("Invalid message body %q: %v", body, err)
+		}
+		s.nextId++
+		message := &QueueMessage{MessageId: strconv.Itoa(s.nextId), MessageText: in.MessageText}
+		s.visible[message.MessageId] = query.Get("visibilitytimeout") == ""
+		s.queues[parts[0]] = append(queue, message)
+		writeOfflineResponse(w, http.StatusCreated, "")
+	case len(parts) == 2 && r.Method == "GET":
+		n := 1
+		if query.Get("numofmessages") != "" {
+			n, _ = strconv.Atoi(query.Get("numofmessages"))
+		}
+		var out QueueMessagesList
+		for _, message := range queue {
+			if len(out.Messages) == n {
+				break
+			}
+			if !s.visible[message.MessageId] {
+				continue
+			}
+			if query.Get("peekonly") != "true" {
+				message.DequeueCount++
+				message.PopReceipt = fmt.Sprintf("receipt-%d", message.DequeueCount)
+				s.visible[message.MessageId] = false
+			}
+			out.Messages = append(out.Messages, *message)
+		}
+		body, _ := xml.Marshal(out)
+		writeOfflineResponse(w, http.StatusOK, string(body))
+	case len(parts) == 3:
+		var message *QueueMessage
+		i := 0
+		for ; i < len(queue); i++ {
+			if queue[i].MessageId == parts[2] {
+				message = queue[i]
+				break
+			}
+		}
+		if message == nil || message.PopReceipt != query.Get("popreceipt") {
+			writeOfflineResponse(w, http.StatusBadRequest, "<?xml version=\"1.0\" encoding=\"utf-8\"?><Error><Code>PopReceiptMismatch</Code><Message>The specified pop receipt did not match.</Message></Error>")
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			message.PopReceipt += "-updated"
+			s.visible[message.MessageId] = query.Get("visibilitytimeout") == "0"
+			w.Header().Set("x-ms-popreceipt", message.PopReceipt)
+			w.Header().Set("x-ms-time-next-visible", "Mon, 01 Jun 2015 10:00:00 GMT")
+		case "DELETE":
+			s.queues[parts[0]] = append(queue[:i], queue[i+1:]...)
+		}
+		writeOfflineResponse(w, http.StatusNoContent, "")
+	default:
+		s.t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func TestQueueMessagesOffline(t *testing.T) {
+	server := newFakeQueueServer(t)
+	cli := newOfflineClient(t, queueServiceName, server.ServeHTTP).GetQueueService()
+
+	if err := cli.CreateQueue("jobs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PutMessage("jobs", "first <job>", PutMessageOptions{MessageTTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.PutMessage("jobs", "second", PutMessageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := cli.GetApproximateMessageCount("jobs"); err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Fatalf("Expected 2 messages, got %d", count)
+	}
+
+	peeked, err := cli.PeekMessages("jobs", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peeked) != 2 || peeked[0].MessageText != "first <job>" || peeked[0].PopReceipt != "" {
+		t.Fatalf("Unexpected peeked messages: %+v", peeked)
+	}
+
+	messages, err := cli.GetMessages("jobs", GetMessagesOptions{VisibilityTimeout: 90 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].MessageText != "first <job>" || messages[0].DequeueCount != 1 {
+		t.Fatalf("Unexpected messages: %+v", messages)
+	}
+	message := messages[0]
+
+	staleReceipt := message.PopReceipt
+	if err := cli.UpdateMessageVisibility("jobs", &message, 0); err != nil {
+		t.Fatal(err)
+	}
+	if message.PopReceipt == staleReceipt || message.TimeNextVisible == "" {
+		t.Fatalf("Expected pop receipt and next visible time to be updated, got %+v", message)
+	}
+	if err := cli.DeleteMessage("jobs", message.MessageId, staleReceipt); err == nil {
+		t.Fatal("Expected delete with a stale pop receipt to fail")
+	} else if storageErr, ok := err.(StorageServiceError); !ok || storageErr.Code != "PopReceiptMismatch" {
+		t.Fatalf("Expected PopReceiptMismatch error, got %#v", err)
+	}
+
+	if messages, err = cli.GetMessages("jobs", GetMessagesOptions{NumOfMessages: 32}); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].DequeueCount != 2 {
+		t.Fatalf("Expected both messages visible, got %+v", messages)
+	}
+	for _, message := range messages {
+		if err := cli.DeleteMessage("jobs", message.MessageId, message.PopReceipt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count, err := cli.GetApproximateMessageCount("jobs"); err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Fatalf("Expected no messages, got %d", count)
+	}
+
+	if err := cli.DeleteQueue("jobs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetMessages("jobs", GetMessagesOptions{}); err == nil {
+		t.Fatal("Expected deleted queue not to be found")
+	}
+
+	expected := []string{
+		"POST /jobs/messages?messagettl=3600",
+		"GET /jobs/messages?visibilitytimeout=90",
+		"PUT /jobs/messages/1?popreceipt=receipt-1&visibilitytimeout=0",
+	}
+	for _, request := range expected {
+		if !strings.Contains(strings.Join(server.requests, "\n"), request) {
+			t.Errorf("Expected request %q, got %q", request, server.requests)
+		}
+	}
+}
+
+func TestCreateQueueValidatesNameOffline(t *testing.T) {
+	cli := newOfflineClient(t, queueServiceName, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}).GetQueueService()
+	for _, name := range []string{"ab", "Jobs", "-jobs", "jobs-", "my--jobs", strings.Repeat("a", 64)} {
+		if err := cli.CreateQueue(name); err == nil {
+			t.Errorf("Expected queue name %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableServiceClient contains operations for the table service of a
+// storage account, which stores entities addressed by a partition key and a
+// row key in tables. It exchanges entities as JSON without metadata.
+type TableServiceClient struct {
+	client StorageClient
+}
+
+// TableEntity is an entity of a table. Properties are the properties of the
+// entity other than its keys and timestamp.
+//
+// Properties read from the service are of the JSON types: strings, float64
+// numbers and booleans, and Int64 and DateTime properties are strings.
+// Properties written to the service may also be int64 and time.Time values,
+// which are stored as Int64 and DateTime properties.
+type TableEntity struct {
+	PartitionKey string
+	RowKey       string
+	Timestamp    time.Time
+	Properties   map[string]interface{}
+
+	// ETag is the version of the entity, which is set when the entity is
+	// read or written and required by the service to update or delete
+	// the entity. An empty ETag updates or deletes the entity whatever
+	// its version.
+	ETag string
+}
+
+const (
+	tableJSONContentType           = "application/json"
+	tableJSONAccept                = "application/json;odata=nometadata"
+	tableJSONAcceptMinimalMetadata = "application/json;odata=minimalmetadata"
+	tableDataVersion               = "3.0;NetFx"
+
+	odataTypeSuffix = "@odata.type"
+	edmInt64        = "Edm.Int64"
+	edmDateTime     = "Edm.DateTime"
+)
+
+var (
+	validTableName = regexp.MustCompile("^[A-Za-z][A-Za-z0-9]{2,62}$")
+
+	errEntityKeysNotSpecified = errors.New("storage: entity partition key and row key must be specified")
+)
+
+// GetTableService returns a TableServiceClient which can operate on the
+// table service of the storage account.
+func (c StorageClient) GetTableService() *TableServiceClient {
+	return &TableServiceClient{client: c}
+}
+
+// CreateTable creates a table with the given name. Table names are 3 to 63
+// alphanumeric characters, beginning with a letter. See
+// https://msdn.microsoft.com/en-us/library/azure/dd135729.aspx
+func (t TableServiceClient) CreateTable(name string) error {
+	if !validTableName.MatchString(name) {
+		return fmt.Errorf("storage: invalid table name %q", name)
+	}
+	body, err := json.Marshal(map[string]string{"TableName": name})
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.exec("POST", "/Tables", nil, t.headers(body), body)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+// DeleteTable deletes the table with the given name and all of its
+// entities. See https://msdn.microsoft.com/en-us/library/azure/dd179387.aspx
+func (t TableServiceClient) DeleteTable(name string) error {
+	resp, err := t.exec("DELETE", fmt.Sprintf("/Tables('%s')", name), nil, t.headers(nil), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+// InsertEntity inserts entity into a table, failing if the table already
+// has an entity with the same keys, and sets the ETag of entity. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179433.aspx
+func (t TableServiceClient) InsertEntity(table string, entity *TableEntity) error {
+	body, err := entity.marshal()
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.exec("POST", "/"+table, nil, t.headers(body), body)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	entity.ETag = resp.headers.Get("ETag")
+	return nil
+}
+
+// GetEntity returns the entity of a table with the given keys. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179421.aspx
+func (t TableServiceClient) GetEntity(table, partitionKey, rowKey string) (*TableEntity, error) {
+	resp, err := t.exec("GET", entityPath(table, partitionKey, rowKey), nil, t.headers(nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return nil, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+
+	var properties map[string]interface{}
+	if err := json.NewDecoder(resp.body).Decode(&properties); err != nil {
+		return nil, err
+	}
+	entity, err := entityFromProperties(properties)
+	if err != nil {
+		return nil, err
+	}
+	entity.ETag = resp.headers.Get("ETag")
+	return entity, nil
+}
+
+// ReplaceEntity replaces the entity of a table with the keys of entity by
+// entity, removing the properties entity does not have, and sets the ETag
+// of entity. See https://msdn.microsoft.com/en-us/library/azure/dd179427.aspx
+func (t TableServiceClient) ReplaceEntity(table string, entity *TableEntity) error {
+	return t.updateEntity("PUT", table, entity)
+}
+
+// MergeEntity updates the entity of a table with the keys of entity with
+// the properties of entity, keeping the properties entity does not have, and
+// sets the ETag of entity. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179392.aspx
+func (t TableServiceClient) MergeEntity(table string, entity *TableEntity) error {
+	return t.updateEntity("MERGE", table, entity)
+}
+
+func (t TableServiceClient) updateEntity(verb, table string, entity *TableEntity) error {
+	body, err := entity.marshal()
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.exec(verb, entityPath(table, entity.PartitionKey, entity.RowKey), nil, t.conditionalHeaders(body, entity.ETag), body)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	entity.ETag = resp.headers.Get("ETag")
+	return nil
+}
+
+// DeleteEntity deletes the entity of a table with the given keys if its
+// version is etag, or whatever its version if etag is empty. See
+// https://msdn.microsoft.com/en-us/library/azure/dd135727.aspx
+func (t TableServiceClient) DeleteEntity(table, partitionKey, rowKey, etag string) error {
+	resp, err := t.exec("DELETE", entityPath(table, partitionKey, rowKey), nil, t.conditionalHeaders(nil, etag), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusNoContent {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusNoContent, resp.statusCode)
+	}
+	return nil
+}
+
+// exec sends a request to the table service signed with Shared Key Lite.
+func (t TableServiceClient) exec(verb, path string, params url.Values, headers map[string]string, body []byte) (*storageResponse, error) {
+	uri := t.client.getEndpoint(tableServiceName, path, url.Values{})
+	if len(params) > 0 {
+		// OData expects spaces in the query encoded as %20 rather than +.
+		uri += "?" + strings.Replace(params.Encode(), "+", "%20", -1)
+	}
+
+	canonicalizedResource, err := t.client.buildTableCanonicalizedResource(uri)
+	if err != nil {
+		return nil, err
+	}
+	stringToSign := headers["x-ms-date"] + "\n" + canonicalizedResource
+	headers["Authorization"] = fmt.Sprintf("SharedKeyLite %s:%s", t.client.accountName, t.client.computeHmac256(stringToSign))
+
+	return t.client.send(verb, uri, headers, bytes.NewReader(body))
+}
+
+// headers returns the headers of a table service request with the given
+// JSON body.
+func (t TableServiceClient) headers(body []byte) map[string]string {
+	headers := map[string]string{
+		"x-ms-date":             currentTimeRfc1123Formatted(),
+		"x-ms-version":          t.client.apiVersion,
+		"Accept":                tableJSONAccept,
+		"DataServiceVersion":    tableDataVersion,
+		"MaxDataServiceVersion": tableDataVersion,
+		"Content-Length":        strconv.Itoa(len(body)),
+	}
+	if body != nil {
+		headers["Content-Type"] = tableJSONContentType
+		headers["Prefer"] = "return-no-content"
+	}
+	return headers
+}
+
+// conditionalHeaders returns the headers of a table service request updating
+// or deleting an entity only if its version is etag, or whatever its version
+// if etag is empty.
+func (t TableServiceClient) conditionalHeaders(body []byte, etag string) map[string]string {
+	headers := t.headers(body)
+	if etag == "" {
+		etag = "*"
+	}
+	headers["If-Match"] = etag
+	return headers
+}
+
+// buildTableCanonicalizedResource returns the canonicalized resource of a
+// table service request for Shared Key Lite, which unlike the blob service
+// only includes the comp parameter of the query. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179428.aspx
+func (c StorageClient) buildTableCanonicalizedResource(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	cr := "/" + c.accountName + u.EscapedPath()
+	if comp := u.Query().Get("comp"); comp != "" {
+		cr += "?comp=" + comp
+	}
+	return cr, nil
+}
+
+// entityPath returns the path of the entity of a table with the given keys.
+func entityPath(table, partitionKey, rowKey string) string {
+	quote := func(key string) string {
+		return "'" + strings.Replace(key, "'", "''", -1) + "'"
+	}
+	return fmt.Sprintf("/%s(PartitionKey=%s,RowKey=%s)", table, quote(partitionKey), quote(rowKey))
+}
+
+// marshal returns the JSON representation of the entity, annotating the
+// types of properties JSON cannot represent.
+func (e *TableEntity) marshal() ([]byte, error) {
+	if e.PartitionKey == "" || e.RowKey == "" {
+		return nil, errEntityKeysNotSpecified
+	}
+
+	out := map[string]interface{}{
+		"PartitionKey": e.PartitionKey,
+		"RowKey":       e.RowKey,
+	}
+	for k, v := range e.Properties {
+		switch k {
+		case "PartitionKey", "RowKey", "Timestamp":
+			return nil, fmt.Errorf("storage: entity property %s is reserved", k)
+		}
+		switch v := v.(type) {
+		case int64:
+			out[k] = strconv.FormatInt(v, 10)
+			out[k+odataTypeSuffix] = edmInt64
+		case time.Time:
+			out[k] = v.UTC().Format(time.RFC3339Nano)
+			out[k+odataTypeSuffix] = edmDateTime
+		default:
+			out[k] = v
+		}
+	}
+	return json.Marshal(out)
+}
+
+func entityFromProperties(properties map[string]interface{}) (*TableEntity, error) {
+	entity := &TableEntity{Properties: map[string]interface{}{}}
+	for k, v := range properties {
+		switch k {
+		case "PartitionKey":
+			entity.PartitionKey, _ = v.(string)
+		case "RowKey":
+			entity.RowKey, _ = v.(string)
+		case "Timestamp":
+			s, _ := v.(string)
+			timestamp, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, fmt.Errorf("storage: invalid entity timestamp %q", s)
+			}
+			entity.Timestamp = timestamp
+		default:
+			if strings.HasPrefix(k, "odata.") || strings.HasSuffix(k, odataTypeSuffix) {
+				continue
+			}
+			entity.Properties[k] = v
+		}
+	}
+	return entity, nil
+}
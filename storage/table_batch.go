@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MaxBatchOperations is the maximum number of operations of a TableBatch.
+const MaxBatchOperations = 100
+
+var (
+	errEmptyBatch          = errors.New("storage: batch has no operations")
+	errBatchTooLarge       = fmt.Errorf("storage: batch has more than %d operations", MaxBatchOperations)
+	errBatchPartitionKeys  = errors.New("storage: all entities of a batch must have the same partition key")
+	errBatchDuplicateRow   = errors.New("storage: an entity can only be the target of one operation of a batch")
+	errBatchUnexpectedBody = errors.New("storage: unexpected batch response")
+)
+
+// TableBatch is an entity group transaction: a group of operations on
+// entities of a table with the same partition key, which either all succeed
+// or all fail. See
+// https://msdn.microsoft.com/en-us/library/azure/dd894038.aspx
+type TableBatch struct {
+	client     TableServiceClient
+	table      string
+	operations []batchOperation
+}
+
+type batchOperation struct {
+	verb         string
+	partitionKey string
+	rowKey       string
+	etag         string
+	entity       *TableEntity
+}
+
+// NewBatch returns an empty batch of operations on entities of a table.
+func (t TableServiceClient) NewBatch(table string) *TableBatch {
+	return &TableBatch{client: t, table: table}
+}
+
+// InsertEntity adds the insert of entity to the batch, as InsertEntity does.
+func (b *TableBatch) InsertEntity(entity *TableEntity) {
+	b.operations = append(b.operations, batchOperation{verb: "POST", partitionKey: entity.PartitionKey, rowKey: entity.RowKey, entity: entity})
+}
+
+// ReplaceEntity adds the replace of the entity with the keys of entity to
+// the batch, as ReplaceEntity does.
+func (b *TableBatch) ReplaceEntity(entity *TableEntity) {
+	b.operations = append(b.operations, batchOperation{verb: "PUT", partitionKey: entity.PartitionKey, rowKey: entity.RowKey, etag: entity.ETag, entity: entity})
+}
+
+// MergeEntity adds the merge of entity into the entity with its keys to the
+// batch, as MergeEntity does.
+func (b *TableBatch) MergeEntity(entity *TableEntity) {
+	b.operations = append(b.operations, batchOperation{verb: "MERGE", partitionKey: entity.PartitionKey, rowKey: entity.RowKey, etag: entity.ETag, entity: entity})
+}
+
+// DeleteEntity adds the delete of the entity with the given keys to the
+// batch, as DeleteEntity does.
+func (b *TableBatch) DeleteEntity(partitionKey, rowKey, etag string) {
+	b.operations = append(b.operations, batchOperation{verb: "DELETE", partitionKey: partitionKey, rowKey: rowKey, etag: etag})
+}
+
+// Execute performs the operations of the batch as one transaction, and sets
+// the ETags of the entities written. If an operation fails, none of the
+// operations is performed and the error of the failing operation is
+// returned, whose message starts with the index of the operation.
+func (b *TableBatch) Execute() error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	batchBoundary := "batch_" + newBoundary()
+	body, err := b.marshal(batchBoundary, "changeset_"+newBoundary())
+	if err != nil {
+		return err
+	}
+
+	headers := b.client.headers(body)
+	headers["Content-Type"] = "multipart/mixed; boundary=" + batchBoundary
+	delete(headers, "Prefer")
+	resp, err := b.client.exec("POST", "/$batch", nil, headers, body)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusAccepted {
+		return fmt.Errorf(errUnexpectedStatus, http.StatusAccepted, resp.statusCode)
+	}
+	return b.unmarshal(resp)
+}
+
+func (b *TableBatch) validate() error {
+	if len(b.operations) == 0 {
+		return errEmptyBatch
+	}
+	if len(b.operations) > MaxBatchOperations {
+		return errBatchTooLarge
+	}
+	rows := map[string]bool{}
+	for _, op := range b.operations {
+		if op.partitionKey == "" || op.rowKey == "" {
+			return errEntityKeysNotSpecified
+		}
+		if op.partitionKey != b.operations[0].partitionKey {
+			return errBatchPartitionKeys
+		}
+		if rows[op.rowKey] {
+			return errBatchDuplicateRow
+		}
+		rows[op.rowKey] = true
+	}
+	return nil
+}
+
+// marshal returns the multipart body of the batch request, with one
+// changeset holding all operations.
+func (b *TableBatch) marshal(batchBoundary, changesetBoundary string) ([]byte, error) {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "--%s\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n", batchBoundary, changesetBoundary)
+
+	for _, op := range b.operations {
+		path := entityPath(b.table, op.partitionKey, op.rowKey)
+		if op.verb == "POST" {
+			path = "/" + b.table
+		}
+
+		var entity []byte
+		if op.entity != nil {
+			var err error
+			if entity, err = op.entity.marshal(); err != nil {
+				return nil, err
+			}
+		}
+
+		fmt.Fprintf(&body, "--%s\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n", changesetBoundary)
+		fmt.Fprintf(&body, "%s %s HTTP/1.1\r\n", op.verb, b.client.client.getEndpoint(tableServiceName, path, url.Values{}))
+		fmt.Fprintf(&body, "Accept: %s\r\nDataServiceVersion: %s\r\n", tableJSONAccept, tableDataVersion)
+		if op.verb != "POST" {
+			etag := op.etag
+			if etag == "" {
+				etag = "*"
+			}
+			fmt.Fprintf(&body, "If-Match: %s\r\n", etag)
+		}
+		if entity != nil {
+			fmt.Fprintf(&body, "Content-Type: %s\r\nPrefer: return-no-content\r\nContent-Length: %d\r\n", tableJSONContentType, len(entity))
+		}
+		body.WriteString("\r\n")
+		body.Write(entity)
+		body.WriteString("\r\n")
+	}
+
+	fmt.Fprintf(&body, "--%s--\r\n--%s--\r\n", changesetBoundary, batchBoundary)
+	return body.Bytes(), nil
+}
+
+// unmarshal reads the responses to the operations of the batch from the
+// multipart body of the batch response, which holds a single error response
+// if an operation failed.
+func (b *TableBatch) unmarshal(resp *storageResponse) error {
+	batch, err := multipartReader(resp.headers.Get("Content-Type"), resp.body)
+	if err != nil {
+		return err
+	}
+	part, err := batch.NextPart()
+	if err != nil {
+		return err
+	}
+	changeset, err := multipartReader(part.Header.Get("Content-Type"), part)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		part, err := changeset.NextPart()
+		if err == io.EOF {
+			if i != len(b.operations) {
+				return errBatchUnexpectedBody
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		opResp, err := http.ReadResponse(bufio.NewReader(part), nil)
+		if err != nil {
+			return err
+		}
+		if opResp.StatusCode >= 400 {
+			body, err := ioutil.ReadAll(opResp.Body)
+			if err != nil {
+				return err
+			}
+			return serviceErrFromJson(body, opResp.StatusCode, resp.headers.Get("x-ms-request-id"))
+		}
+		if i >= len(b.operations) {
+			return errBatchUnexpectedBody
+		}
+		if entity := b.operations[i].entity; entity != nil {
+			entity.ETag = opResp.Header.Get("ETag")
+		}
+	}
+}
+
+func multipartReader(contentType string, body io.Reader) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, errBatchUnexpectedBody
+	}
+	return multipart.NewReader(body, params["boundary"]), nil
+}
+
+func newBoundary() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// really should not be happening
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
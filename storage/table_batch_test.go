@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// serveBatch reads the operations of a batch request and responds with the
+// status returned by respond for each of them, or with the first error status.
+func serveBatch(t *testing.T, respond func(i int, r *http.Request) int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/$batch" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		batch, err := multipartReader(r.Header.Get("Content-Type"), r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part, err := batch.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		changeset, err := multipartReader(part.Header.Get("Content-Type"), part)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var responses bytes.Buffer
+		for i := 0; ; i++ {
+			part, err := changeset.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			opReq, err := http.ReadRequest(bufio.NewReader(part))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ioutil.ReadAll(opReq.Body)
+
+			status := respond(i, opReq)
+			if status >= 400 {
+				// A failed batch only holds the response of the failing operation.
+				body := fmt.Sprintf(`{"odata.error":{"code":"EntityAlreadyExists","message":{"value":"%d:The specified entity already exists."}}}`, i)
+				responses.Reset()
+				fmt.Fprintf(&responses, "--changesetresponse\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+				fmt.Fprintf(&responses, "HTTP/1.1 %d %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s\r\n", status, http.StatusText(status), len(body), body)
+				break
+			}
+			fmt.Fprintf(&responses, "--changesetresponse\r\nContent-Type: application/http\r\nContent-Transfer-Encoding: binary\r\n\r\n")
+			fmt.Fprintf(&responses, "HTTP/1.1 %d %s\r\nETag: W/\"%d\"\r\nContent-Length: 0\r\n\r\n\r\n", status, http.StatusText(status), i)
+		}
+
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batchresponse")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "--batchresponse\r\nContent-Type: multipart/mixed; boundary=changesetresponse\r\n\r\n")
+		w.Write(responses.Bytes())
+		fmt.Fprintf(w, "--changesetresponse--\r\n--batchresponse--\r\n")
+	}
+}
+
+func TestTableBatchOffline(t *testing.T) {
+	var requests []string
+	cli := newOfflineClient(t, tableServiceName, serveBatch(t, func(i int, r *http.Request) int {
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, r.Header.Get("If-Match")))
+		return http.StatusNoContent
+	})).GetTableService()
+
+	inserted := &TableEntity{PartitionKey: "p", RowKey: "r1", Properties: map[string]interface{}{"Status": "new"}}
+	merged := &TableEntity{PartitionKey: "p", RowKey: "r2", ETag: `W/"7"`}
+	batch := cli.NewBatch("state")
+	batch.InsertEntity(inserted)
+	batch.MergeEntity(merged)
+	batch.DeleteEntity("p", "r3", "")
+	if err := batch.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"POST /state ",
+		`MERGE /state(PartitionKey='p',RowKey='r2') W/"7"`,
+		"DELETE /state(PartitionKey='p',RowKey='r3') *",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Fatalf("Expected operations %q, got %q", expected, requests)
+	}
+	if inserted.ETag != `W/"0"` || merged.ETag != `W/"1"` {
+		t.Errorf("Expected ETags to be set, got %q and %q", inserted.ETag, merged.ETag)
+	}
+}
+
+func TestTableBatchFailureOffline(t *testing.T) {
+	cli := newOfflineClient(t, tableServiceName, serveBatch(t, func(i int, r *http.Request) int {
+		if i == 1 {
+			return http.StatusConflict
+		}
+		return http.StatusNoContent
+	})).GetTableService()
+
+	batch := cli.NewBatch("state")
+	batch.InsertEntity(&TableEntity{PartitionKey: "p", RowKey: "r1"})
+	batch.InsertEntity(&TableEntity{PartitionKey: "p", RowKey: "r2"})
+	err := batch.Execute()
+	storageErr, ok := err.(StorageServiceError)
+	if !ok || storageErr.StatusCode != http.StatusConflict || storageErr.Code != "EntityAlreadyExists" {
+		t.Fatalf("Expected EntityAlreadyExists error, got %#v", err)
+	}
+}
+
+func TestTableBatchValidationOffline(t *testing.T) {
+	cli := newOfflineClient(t, tableServiceName, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}).GetTableService()
+
+	if err := cli.NewBatch("state").Execute(); err != errEmptyBatch {
+		t.Errorf("Expected errEmptyBatch, got %v", err)
+	}
+
+	batch := cli.NewBatch("state")
+	batch.DeleteEntity("p1", "r", "")
+	batch.DeleteEntity("p2", "r", "")
+	if err := batch.Execute(); err != errBatchPartitionKeys {
+		t.Errorf("Expected errBatchPartitionKeys, got %v", err)
+	}
+
+	batch = cli.NewBatch("state")
+	batch.DeleteEntity("p", "r", "")
+	batch.DeleteEntity("p", "r", "")
+	if err := batch.Execute(); err != errBatchDuplicateRow {
+		t.Errorf("Expected errBatchDuplicateRow, got %v", err)
+	}
+
+	batch = cli.NewBatch("state")
+	for i := 0; i <= MaxBatchOperations; i++ {
+		batch.DeleteEntity("p", fmt.Sprint(i), "")
+	}
+	if err := batch.Execute(); err != errBatchTooLarge {
+		t.Errorf("Expected errBatchTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableQuery selects the entities of a table returned by QueryEntities.
+// Filter is an OData filter expression, which can be built with FilterEq and
+// the other filter helpers. Select limits the properties returned, and Top
+// the number of entities returned per page.
+type TableQuery struct {
+	Filter string
+	Select []string
+	Top    int
+}
+
+// ContinuationToken marks where the next page of the entities of a query
+// starts, as returned by QueryEntities.
+type ContinuationToken struct {
+	NextPartitionKey string
+	NextRowKey       string
+}
+
+// QueryEntities returns a page of the entities of a table selected by query,
+// starting at next or at the first entity if next is nil, and the token of
+// the next page, which is nil after the last page. A page may be empty even
+// if there are further pages. See
+// https://msdn.microsoft.com/en-us/library/azure/dd179421.aspx
+func (t TableServiceClient) QueryEntities(table string, query TableQuery, next *ContinuationToken) ([]TableEntity, *ContinuationToken, error) {
+	params := url.Values{}
+	if query.Filter != "" {
+		params.Set("$filter", query.Filter)
+	}
+	if len(query.Select) > 0 {
+		params.Set("$select", strings.Join(query.Select, ","))
+	}
+	if query.Top > 0 {
+		params.Set("$top", strconv.Itoa(query.Top))
+	}
+	if next != nil {
+		params.Set("NextPartitionKey", next.NextPartitionKey)
+		if next.NextRowKey != "" {
+			params.Set("NextRowKey", next.NextRowKey)
+		}
+	}
+
+	// Unlike responses without metadata, responses with minimal metadata
+	// carry the ETags of the entities.
+	headers := t.headers(nil)
+	headers["Accept"] = tableJSONAcceptMinimalMetadata
+	resp, err := t.exec("GET", "/"+table+"()", params, headers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.body.Close()
+
+	if resp.statusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf(errUnexpectedStatus, http.StatusOK, resp.statusCode)
+	}
+
+	var out struct {
+		Value []map[string]interface{} `json:"value"`
+	}
+	if err := json.NewDecoder(resp.body).Decode(&out); err != nil {
+		return nil, nil, err
+	}
+	entities := make([]TableEntity, 0, len(out.Value))
+	for _, properties := range out.Value {
+		entity, err := entityFromProperties(properties)
+		if err != nil {
+			return nil, nil, err
+		}
+		if etag, ok := properties["odata.etag"].(string); ok {
+			entity.ETag = etag
+		}
+		entities = append(entities, *entity)
+	}
+
+	var token *ContinuationToken
+	if nextPartitionKey := resp.headers.Get("x-ms-continuation-NextPartitionKey"); nextPartitionKey != "" {
+		token = &ContinuationToken{
+			NextPartitionKey: nextPartitionKey,
+			NextRowKey:       resp.headers.Get("x-ms-continuation-NextRowKey"),
+		}
+	}
+	return entities, token, nil
+}
+
+// EntityIterator iterates over all entities of a table selected by a query,
+// following continuation tokens. Use it as:
+//
+//	it := tableService.NewEntityIterator("table", query)
+//	for it.Next() {
+//		entity := it.Entity()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type EntityIterator struct {
+	client   TableServiceClient
+	table    string
+	query    TableQuery
+	next     *ContinuationToken
+	page     []TableEntity
+	entity   *TableEntity
+	err      error
+	lastPage bool
+}
+
+// NewEntityIterator returns an iterator over the entities of a table
+// selected by query, which queries pages of entities as it goes.
+func (t TableServiceClient) NewEntityIterator(table string, query TableQuery) *EntityIterator {
+	return &EntityIterator{client: t, table: table, query: query}
+}
+
+// Next advances the iterator to the next entity, and returns false once
+// there are no more entities or a query fails.
+func (it *EntityIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || it.lastPage {
+			it.entity = nil
+			return false
+		}
+		it.page, it.next, it.err = it.client.QueryEntities(it.table, it.query, it.next)
+		it.lastPage = it.next == nil
+	}
+	it.entity = &it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// Entity returns the current entity of the iterator.
+func (it *EntityIterator) Entity() *TableEntity {
+	return it.entity
+}
+
+// Err returns the error of the query that stopped the iterator, if any.
+func (it *EntityIterator) Err() error {
+	return it.err
+}
+
+// FilterEq returns the OData filter expression comparing a property for
+// equality with value, quoted according to its type. Values of types other
+// than strings, integers, float64, bools and time.Time are compared as their
+// string representation.
+func FilterEq(property string, value interface{}) string {
+	return filterCompare(property, "eq", value)
+}
+
+// FilterNe returns the OData filter expression comparing a property for
+// inequality with value.
+func FilterNe(property string, value interface{}) string {
+	return filterCompare(property, "ne", value)
+}
+
+// FilterGt returns the OData filter expression selecting a property greater
+// than value.
+func FilterGt(property string, value interface{}) string {
+	return filterCompare(property, "gt", value)
+}
+
+// FilterGe returns the OData filter expression selecting a property greater
+// than or equal to value.
+func FilterGe(property string, value interface{}) string {
+	return filterCompare(property, "ge", value)
+}
+
+// FilterLt returns the OData filter expression selecting a property less
+// than value.
+func FilterLt(property string, value interface{}) string {
+	return filterCompare(property, "lt", value)
+}
+
+// FilterLe returns the OData filter expression selecting a property less
+// than or equal to value.
+func FilterLe(property string, value interface{}) string {
+	return filterCompare(property, "le", value)
+}
+
+// FilterAnd returns the OData filter expression selecting the entities
+// selected by all filters.
+func FilterAnd(filters ...string) string {
+	return filterCombine("and", filters)
+}
+
+// FilterOr returns the OData filter expression selecting the entities
+// selected by any of filters.
+func FilterOr(filters ...string) string {
+	return filterCombine("or", filters)
+}
+
+// FilterNot returns the OData filter expression selecting the entities not
+// selected by filter.
+func FilterNot(filter string) string {
+	return fmt.Sprintf("not (%s)", filter)
+}
+
+func filterCompare(property, operator string, value interface{}) string {
+	return fmt.Sprintf("%s %s %s", property, operator, filterLiteral(value))
+}
+
+func filterCombine(operator string, filters []string) string {
+	parts := make([]string, len(filters))
+	for i, filter := range filters {
+		parts[i] = "(" + filter + ")"
+	}
+	return strings.Join(parts, " "+operator+" ")
+}
+
+// filterLiteral returns the OData literal of value.
+func filterLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10) + "L"
+	case float64:
+		// A literal without a decimal point would be an Int32.
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return "datetime'" + v.UTC().Format(time.RFC3339Nano) + "'"
+	default:
+		return filterLiteral(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryEntitiesFollowsContinuationOffline(t *testing.T) {
+	pages := map[string]struct {
+		next     string
+		entities []map[string]interface{}
+	}{
+		"": {"p2", []map[string]interface{}{
+			{"PartitionKey": "p1", "RowKey": "r1", "odata.etag": `W/"1"`, "Status": "done"},
+		}},
+		"p2": {"p3", nil},
+		"p3": {"", []map[string]interface{}{
+			{"PartitionKey": "p3", "RowKey": "r1", "odata.etag": `W/"2"`, "Status": "done"},
+		}},
+	}
+	cli := newOfflineClient(t, tableServiceName, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deployments()" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		q := r.URL.Query()
+		if q.Get("$filter") != "Status eq 'done'" || q.Get("$select") != "Status" || q.Get("$top") != "1" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		if strings.Contains(r.URL.RawQuery, "+") {
+			t.Errorf("Expected spaces in the query encoded as %%20, got %s", r.URL.RawQuery)
+		}
+		page := pages[q.Get("NextPartitionKey")]
+		if page.next != "" {
+			w.Header().Set("x-ms-continuation-NextPartitionKey", page.next)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": page.entities})
+	}).GetTableService()
+
+	query := TableQuery{Filter: FilterEq("Status", "done"), Select: []string{"Status"}, Top: 1}
+	it := cli.NewEntityIterator("deployments", query)
+	var keys []string
+	for it.Next() {
+		keys = append(keys, it.Entity().PartitionKey)
+		if it.Entity().ETag == "" {
+			t.Errorf("Expected ETag of entity %+v", it.Entity())
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "p1" || keys[1] != "p3" {
+		t.Fatalf("Expected entities of p1 and p3, got %v", keys)
+	}
+}
+
+func TestFilterExpressionsOffline(t *testing.T) {
+	for _, tc := range []struct {
+		filter, expected string
+	}{
+		{FilterEq("Name", "o'brien"), "Name eq 'o''brien'"},
+		{FilterNe("Count", 3), "Count ne 3"},
+		{FilterGt("Size", int64(5)), "Size gt 5L"},
+		{FilterGe("Ratio", 2.0), "Ratio ge 2.0"},
+		{FilterLt("Active", true), "Active lt true"},
+		{FilterLe("Created", time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)), "Created le datetime'2015-06-01T00:00:00Z'"},
+		{
+			FilterAnd(FilterEq("PartitionKey", "p"), FilterNot(FilterOr(FilterEq("A", 1), FilterEq("B", 2)))),
+			"(PartitionKey eq 'p') and (not ((A eq 1) or (B eq 2)))",
+		},
+	} {
+		if tc.filter != tc.expected {
+			t.Errorf("Expected filter %q, got %q", tc.expected, tc.filter)
+		}
+	}
+}
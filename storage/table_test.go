@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTableServer stores the entities of tables in memory, keyed by their
+// path, and checks that requests are signed with Shared Key Lite.
+type fakeTableServer struct {
+	t        *testing.T
+	client   StorageClient
+	tables   map[string]bool
+	entities map[string]map[string]interface{}
+	version  int
+}
+
+func newFakeTableServer(t *testing.T) *fakeTableServer {
+	cli, err := NewBasicClient("foo", "YmFy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeTableServer{
+		t:        t,
+		client:   cli,
+		tables:   map[string]bool{},
+		entities: map[string]map[string]interface{}{},
+	}
+}
+
+func (s *fakeTableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stringToSign := r.Header.Get("x-ms-date") + "\n/foo" + r.URL.EscapedPath()
+	if expected := "SharedKeyLite foo:" + s.client.computeHmac256(stringToSign); r.Header.Get("Authorization") != expected {
+		s.t.Errorf("Expected Authorization %q, got %q", expected, r.Header.Get("Authorization"))
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	var properties map[string]interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &properties); err != nil {
+			s.t.Errorf("Invalid JSON body %q: %v", body, err)
+		}
+	}
+
+	path := r.URL.Path
+	writeError := func(status int, code string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"odata.error":{"code":%q,"message":{"lang":"en-US","value":"failed"}}}`, code)
+	}
+	etag := func() string {
+		s.version++
+		return fmt.Sprintf(`W/"%d"`, s.version)
+	}
+
+	switch {
+	case r.Method == "POST" && path == "/Tables":
+		s.tables[properties["TableName"].(string)] = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/Tables("):
+		delete(s.tables, strings.Trim(strings.TrimPrefix(path, "/Tables"), "()'"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "POST":
+		if !s.tables[strings.TrimPrefix(path, "/")] {
+			writeError(http.StatusNotFound, "TableNotFound")
+			return
+		}
+		key := entityPath(strings.TrimPrefix(path, "/"), properties["PartitionKey"].(string), properties["RowKey"].(string))
+		if _, ok := s.entities[key]; ok {
+			writeError(http.StatusConflict, "EntityAlreadyExists")
+			return
+		}
+		properties["odata.etag"] = etag()
+		s.entities[key] = properties
+		w.Header().Set("ETag", properties["odata.etag"].(string))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		entity, ok := s.entities[path]
+		if !ok {
+			writeError(http.StatusNotFound, "ResourceNotFound")
+			return
+		}
+		if r.Method != "GET" {
+			if match := r.Header.Get("If-Match"); match != "*" && match != entity["odata.etag"] {
+				writeError(http.StatusPreconditionFailed, "UpdateConditionNotSatisfied")
+				return
+			}
+		}
+		switch r.Method {
+		case "GET":
+			entity["Timestamp"] = "2015-06-01T10:00:00.1234567Z"
+			w.Header().Set("ETag", entity["odata.etag"].(string))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(entity)
+			return
+		case "PUT":
+			s.entities[path] = properties
+		case "MERGE":
+			for k, v := range properties {
+				entity[k] = v
+			}
+		case "DELETE":
+			delete(s.entities, path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		s.entities[path]["odata.etag"] = etag()
+		w.Header().Set("ETag", s.entities[path]["odata.etag"].(string))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestTableEntitiesOffline(t *testing.T) {
+	server := newFakeTableServer(t)
+	cli := newOfflineClient(t, tableServiceName, server.ServeHTTP).GetTableService()
+
+	if err := cli.CreateTable("state"); err != nil {
+		t.Fatal(err)
+	}
+
+	entity := &TableEntity{
+		PartitionKey: "deployments",
+		RowKey:       "o'brien",
+		Properties: map[string]interface{}{
+			"Status":  "running",
+			"Retries": int64(3),
+			"Started": time.Date(2015, 6, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	if err := cli.InsertEntity("state", entity); err != nil {
+		t.Fatal(err)
+	}
+	if entity.ETag == "" {
+		t.Fatal("Expected ETag to be set on insert")
+	}
+	stored := server.entities["/state(PartitionKey='deployments',RowKey='o''brien')"]
+	if stored["Retries@odata.type"] != "Edm.Int64" || stored["Started@odata.type"] != "Edm.DateTime" {
+		t.Errorf("Expected Int64 and DateTime type annotations, got %v", stored)
+	}
+	if err := cli.InsertEntity("state", entity); err == nil || !strings.Contains(err.Error(), "EntityAlreadyExists") {
+		t.Fatalf("Expected EntityAlreadyExists error, got %v", err)
+	}
+
+	out, err := cli.GetEntity("state", "deployments", "o'brien")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.PartitionKey != "deployments" || out.RowKey != "o'brien" || out.ETag != entity.ETag {
+		t.Fatalf("Unexpected entity: %+v", out)
+	}
+	if out.Properties["Status"] != "running" || out.Properties["Retries"] != "3" {
+		t.Errorf("Unexpected properties: %v", out.Properties)
+	}
+	if out.Timestamp.IsZero() {
+		t.Error("Expected entity timestamp to be set")
+	}
+
+	staleETag := entity.ETag
+	entity.Properties = map[string]interface{}{"Status": "done"}
+	if err := cli.MergeEntity("state", entity); err != nil {
+		t.Fatal(err)
+	}
+	if out, err = cli.GetEntity("state", "deployments", "o'brien"); err != nil {
+		t.Fatal(err)
+	}
+	if out.Properties["Status"] != "done" || out.Properties["Retries"] != "3" {
+		t.Errorf("Expected merge to keep other properties, got %v", out.Properties)
+	}
+
+	if err := cli.ReplaceEntity("state", &TableEntity{PartitionKey: "deployments", RowKey: "o'brien", ETag: staleETag}); err == nil {
+		t.Fatal("Expected replace with a stale ETag to fail")
+	}
+	if err := cli.ReplaceEntity("state", entity); err != nil {
+		t.Fatal(err)
+	}
+	if out, err = cli.GetEntity("state", "deployments", "o'brien"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := out.Properties["Retries"]; ok {
+		t.Errorf("Expected replace to remove other properties, got %v", out.Properties)
+	}
+
+	if err := cli.DeleteEntity("state", "deployments", "o'brien", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetEntity("state", "deployments", "o'brien"); err == nil {
+		t.Fatal("Expected deleted entity not to be found")
+	} else if storageErr, ok := err.(StorageServiceError); !ok || storageErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected StorageServiceError with status 404, got %#v", err)
+	}
+
+	if err := cli.DeleteTable("state"); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.tables) != 0 {
+		t.Errorf("Expected table to be deleted, got %v", server.tables)
+	}
+}
+
+func TestCreateTableValidatesNameOffline(t *testing.T) {
+	cli := newOfflineClient(t, tableServiceName, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+	}).GetTableService()
+	for _, name := range []string{"ab", "1table", "my-table"} {
+		if err := cli.CreateTable(name); err == nil {
+			t.Errorf("Expected table name %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// DefaultUploadWorkers is the number of blocks UploadBlockBlob uploads
+	// concurrently unless UploadOptions says otherwise.
+	DefaultUploadWorkers = 4
+
+	// MaxBlobBlocks is the maximum number of committed blocks of a block
+	// blob.
+	MaxBlobBlocks = 50000
+)
+
+// UploadOptions configures an upload with UploadBlockBlob.
+type UploadOptions struct {
+	// BlockSize is the size of the blocks the blob is split into. Zero or
+	// anything above MaxBlobBlockSize means MaxBlobBlockSize.
+	BlockSize int
+
+	// Workers is the number of blocks uploaded concurrently. Zero or less
+	// means DefaultUploadWorkers.
+	Workers int
+
+	// Blob sets the properties, metadata and access conditions of the
+	// blob, which are applied when the block list is committed.
+	Blob PutBlobOptions
+}
+
+// UploadBlockBlob uploads size bytes of blob into a block blob, splitting it
+// into blocks that are uploaded concurrently and then committed as the block
+// list of the blob. A blob fitting into one block is uploaded with a single
+// Put Blob request instead. The first failing block stops the upload and its
+// error is returned; blocks already uploaded are left uncommitted. Each worker
+// reads its block into memory before sending it, and a blob shorter than size
+// fails the upload with io.ErrUnexpectedEOF. The MD5 hash of every block is
+// validated, but unlike with PutBlob no MD5 hash of the whole blob is stored.
+func (b BlobStorageClient) UploadBlockBlob(container, name string, blob io.ReaderAt, size int64, options UploadOptions) error {
+	blockSize := options.BlockSize
+	if blockSize <= 0 || blockSize > MaxBlobBlockSize {
+		blockSize = MaxBlobBlockSize
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultUploadWorkers
+	}
+
+	if size <= int64(blockSize) {
+		chunk := make([]byte, size)
+		if err := readFullAt(blob, chunk, 0); err != nil {
+			return err
+		}
+		return b.putSingleBlockBlob(container, name, chunk, options.Blob.headers())
+	}
+
+	blockCount := int((size + int64(blockSize) - 1) / int64(blockSize))
+	if blockCount > MaxBlobBlocks {
+		return fmt.Errorf("storage: blob of %d bytes needs %d blocks of %d bytes, more than the maximum of %d", size, blockCount, blockSize, MaxBlobBlocks)
+	}
+
+	blocks := make([]Block, blockCount)
+	for i := range blocks {
+		blocks[i] = Block{blockId(i), BlockStatusLatest}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	next := make(chan int)
+	if workers > blockCount {
+		workers = blockCount
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, blockSize)
+			for i := range next {
+				offset := int64(i) * int64(blockSize)
+				length := size - offset
+				if length > int64(blockSize) {
+					length = int64(blockSize)
+				}
+				chunk := buf[:length]
+				err := readFullAt(blob, chunk, offset)
+				if err == nil {
+					err = b.PutBlock(container, name, blocks[i].Id, chunk)
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < blockCount && !failed(); i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return b.putBlockList(container, name, blocks, options.Blob.headers())
+}
+
+// readFullAt reads len(p) bytes of r at offset into p. It fails with
+// io.ErrUnexpectedEOF if r ends before, so that no partially read data is
+// uploaded.
+func readFullAt(r io.ReaderAt, p []byte, offset int64) error {
+	n, err := r.ReadAt(p, offset)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// blockId returns the ID of the block with the given number, which is of the
+// same length for all blocks of a blob as the service requires.
+func blockId(blockNum int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%011d", blockNum)))
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUploadBlockBlobOffline(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		blocks    = map[string][]byte{}
+		committed []byte
+		header    http.Header
+	)
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Query().Get("comp") {
+		case "block":
+			blocks[r.URL.Query().Get("blockid")] = body
+		case "blocklist":
+			var list struct {
+				Latest []string
+			}
+			if err := xml.Unmarshal(body, &list); err != nil {
+				t.Error(err)
+			}
+			committed = nil
+			for _, id := range list.Latest {
+				committed = append(committed, blocks[id]...)
+			}
+			header = r.Header
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+		writeOfflineResponse(w, http.StatusCreated, "")
+	}).GetBlobService()
+
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	err := cli.UploadBlockBlob("images", "disk.vhd", bytes.NewReader(data), int64(len(data)), UploadOptions{
+		BlockSize: 5,
+		Workers:   3,
+		Blob:      PutBlobOptions{ContentType: "application/octet-stream"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 8 {
+		t.Errorf("Expected 8 blocks, got %d", len(blocks))
+	}
+	if !bytes.Equal(committed, data) {
+		t.Fatalf("Expected committed blob %q, got %q", data, committed)
+	}
+	if got := header.Get("x-ms-blob-content-type"); got != "application/octet-stream" {
+		t.Errorf("Expected content type on block list commit, got %q", got)
+	}
+}
+
+func TestUploadBlockBlobStopsOnErrorOffline(t *testing.T) {
+	var mu sync.Mutex
+	var committed bool
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Query().Get("comp") == "blocklist" {
+			committed = true
+		}
+		writeOfflineResponse(w, http.StatusInternalServerError, "")
+	}).GetBlobService()
+
+	data := make([]byte, 64)
+	err := cli.UploadBlockBlob("images", "disk.vhd", bytes.NewReader(data), int64(len(data)), UploadOptions{BlockSize: 8})
+	if err == nil {
+		t.Fatal("Expected failing block upload to fail the upload")
+	}
+	if committed {
+		t.Fatal("Expected block list not to be committed")
+	}
+}
+
+func TestUploadBlockBlobShortReaderOffline(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	cli := newOfflineClient(t, blobServiceName, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.RawQuery)
+		writeOfflineResponse(w, http.StatusCreated, "")
+	}).GetBlobService()
+
+	// The reader ends 4 bytes into the last block of the announced size.
+	data := bytes.Repeat([]byte("x"), 28)
+	err := cli.UploadBlockBlob("images", "disk.vhd", bytes.NewReader(data), 32, UploadOptions{BlockSize: 8, Workers: 1})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	for _, request := range requests {
+		if strings.Contains(request, "comp=blocklist") {
+			t.Fatalf("Expected block list not to be committed, got requests %q", requests)
+		}
+	}
+
+	requests = nil
+	err = cli.UploadBlockBlob("images", "small.txt", bytes.NewReader(data[:4]), 8, UploadOptions{})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF for a single block, got %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("Expected nothing to be uploaded, got requests %q", requests)
+	}
+}
@@ -2,10 +2,12 @@ package storage
 
 import (
 	"crypto/hmac"
+	"crypto/md5"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -61,3 +63,39 @@ func xmlUnmarshal(body io.ReadCloser, v interface{}) error {
 	defer body.Close()
 	return xml.Unmarshal(data, v)
 }
+
+// contentMD5 returns the base64 encoded MD5 hash of data, as in the
+// Content-MD5 header.
+func contentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		out[k] = v
+	}
+	return out
+}
+
+// md5VerifyingReader reads a response body, returning ErrContentMD5Mismatch
+// instead of io.EOF if the body does not match the expected Content-MD5.
+type md5VerifyingReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (r *md5VerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && base64.StdEncoding.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		err = ErrContentMD5Mismatch
+	}
+	return n, err
+}
+
+func (r *md5VerifyingReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+const (
+	vhdFooterSize    = 512
+	vhdCookie        = "conectix"
+	vhdDiskTypeFixed = 2
+
+	// vhdChunkSize is the size of the parts of a VHD that UploadVHD reads
+	// and writes at a time.
+	vhdChunkSize = MaxBlobPageSize
+)
+
+var (
+	ErrVHDNotFixed      = errors.New("storage: only fixed size VHDs can be uploaded to page blobs")
+	ErrVHDInvalidFooter = errors.New("storage: VHD footer is invalid")
+)
+
+// UploadVHDOptions configures an upload with UploadVHD.
+type UploadVHDOptions struct {
+	// Workers is the number of chunks uploaded concurrently. Zero or less
+	// means DefaultUploadWorkers.
+	Workers int
+
+	// Resume continues an interrupted upload to an existing page blob of
+	// the same size, skipping the pages already written to it. Without
+	// Resume, an existing blob is overwritten.
+	Resume bool
+
+	// Progress, if set, is called after each chunk with the number of
+	// bytes processed so far, including empty and already uploaded pages
+	// that were skipped, and the size of the VHD.
+	Progress func(processed, total int64)
+}
+
+// UploadVHD uploads a fixed size VHD of size bytes into a page blob, as
+// required for the OS and data disks of virtual machines. The VHD footer is
+// validated before anything is uploaded, and pages of the VHD that contain
+// only zeros are not uploaded since they read as zeros from a new page blob.
+func (b BlobStorageClient) UploadVHD(container, name string, vhd io.ReaderAt, size int64, options UploadVHDOptions) error {
+	if err := validateVHDFooter(vhd, size); err != nil {
+		return err
+	}
+
+	var uploaded []PageRange
+	resumed := false
+	if options.Resume {
+		exists, err := b.BlobExists(container, name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			props, err := b.GetBlobProperties(container, name)
+			if err != nil {
+				return err
+			}
+			if props.BlobType != BlobTypePage || props.ContentLength != size {
+				return fmt.Errorf("storage: cannot resume upload to %s/%s: it is not a page blob of %d bytes", container, name, size)
+			}
+			ranges, err := b.GetPageRanges(container, name)
+			if err != nil {
+				return err
+			}
+			uploaded = ranges.PageList
+			resumed = true
+		}
+	}
+	if !resumed {
+		if err := b.PutPageBlob(container, name, size); err != nil {
+			return err
+		}
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultUploadWorkers
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		processed int64
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	next := make(chan int64)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chunk := make([]byte, vhdChunkSize)
+			for offset := range next {
+				n, err := b.uploadVHDChunk(container, name, vhd, size, offset, chunk, uploaded)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				processed += n
+				if err == nil && options.Progress != nil {
+					options.Progress(processed, size)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for offset := int64(0); offset < size && !failed(); offset += vhdChunkSize {
+		next <- offset
+	}
+	close(next)
+	wg.Wait()
+
+	return firstErr
+}
+
+// uploadVHDChunk uploads the non-empty pages of the chunk of vhd starting at
+// offset, reading it into buf, and returns the size of the chunk.
+func (b BlobStorageClient) uploadVHDChunk(container, name string, vhd io.ReaderAt, size, offset int64, buf []byte, uploaded []PageRange) (int64, error) {
+	length := size - offset
+	if length > int64(len(buf)) {
+		length = int64(len(buf))
+	}
+	chunk := buf[:length]
+	if err := readFullAt(vhd, chunk, offset); err != nil {
+		return 0, err
+	}
+
+	for _, r := range nonEmptyPageRanges(chunk) {
+		start, end := offset+r.Start, offset+r.End
+		if pageRangesCover(uploaded, start, end) {
+			continue
+		}
+		if err := b.PutPage(container, name, start, end, PageWriteTypeUpdate, chunk[r.Start:r.End+1]); err != nil {
+			return 0, err
+		}
+	}
+	return length, nil
+}
+
+// nonEmptyPageRanges returns the ranges of consecutive pages of chunk that
+// contain anything other than zeros, relative to the start of chunk.
+func nonEmptyPageRanges(chunk []byte) []PageRange {
+	var (
+		ranges  []PageRange
+		current *PageRange
+	)
+	empty := make([]byte, pageSize)
+	for start := 0; start < len(chunk); start += pageSize {
+		end := start + pageSize
+		if end > len(chunk) {
+			end = len(chunk)
+		}
+		if bytes.Equal(chunk[start:end], empty[:end-start]) {
+			current = nil
+			continue
+		}
+		if current == nil {
+			ranges = append(ranges, PageRange{Start: int64(start)})
+			current = &ranges[len(ranges)-1]
+		}
+		current.End = int64(end - 1)
+	}
+	return ranges
+}
+
+// pageRangesCover returns whether the bytes from start to end are within
+// one of ranges, which are sorted and do not overlap as returned by
+// GetPageRanges.
+func pageRangesCover(ranges []PageRange, start, end int64) bool {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].End >= start })
+	return i < len(ranges) && ranges[i].Start <= start && ranges[i].End >= end
+}
+
+// validateVHDFooter checks that the last 512 bytes of vhd are the footer of
+// a fixed size VHD of size bytes with a valid checksum. See the Virtual Hard
+// Disk Image Format Specification.
+func validateVHDFooter(vhd io.ReaderAt, size int64) error {
+	if size < vhdFooterSize || size%pageSize != 0 {
+		return fmt.Errorf("storage: VHD size (%d bytes) must be a multiple of %d bytes", size, pageSize)
+	}
+
+	footer := make([]byte, vhdFooterSize)
+	if err := readFullAt(vhd, footer, size-vhdFooterSize); err != nil {
+		return err
+	}
+	if string(footer[0:8]) != vhdCookie {
+		return ErrVHDInvalidFooter
+	}
+
+	checksum := binary.BigEndian.Uint32(footer[64:68])
+	var sum uint32
+	for i, c := range footer {
+		if i < 64 || i >= 68 {
+			sum += uint32(c)
+		}
+	}
+	if ^sum != checksum {
+		return ErrVHDInvalidFooter
+	}
+
+	if binary.BigEndian.Uint32(footer[60:64]) != vhdDiskTypeFixed {
+		return ErrVHDNotFixed
+	}
+	if currentSize := binary.BigEndian.Uint64(footer[48:56]); int64(currentSize) != size-vhdFooterSize {
+		return fmt.Errorf("storage: VHD footer gives a disk size of %d bytes but the VHD has %d bytes of data", currentSize, size-vhdFooterSize)
+	}
+	return nil
+}
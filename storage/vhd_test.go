@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fixedVHD returns a fixed size VHD with the given data and a valid footer.
+func fixedVHD(data []byte) []byte {
+	footer := make([]byte, vhdFooterSize)
+	copy(footer, vhdCookie)
+	binary.BigEndian.PutUint32(footer[12:16], 0x00010000)
+	binary.BigEndian.PutUint64(footer[40:48], uint64(len(data)))
+	binary.BigEndian.PutUint64(footer[48:56], uint64(len(data)))
+	binary.BigEndian.PutUint32(footer[60:64], vhdDiskTypeFixed)
+	var sum uint32
+	for _, c := range footer {
+		sum += uint32(c)
+	}
+	binary.BigEndian.PutUint32(footer[64:68], ^sum)
+	return append(data, footer...)
+}
+
+// pageBlobServer serves a single page blob, recording the ranges written.
+type pageBlobServer struct {
+	mu      sync.Mutex
+	exists  bool
+	size    int64
+	written []string
+	ranges  []PageRange
+}
+
+func (s *pageBlobServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case r.Method == "HEAD":
+			if !s.exists {
+				writeOfflineResponse(w, http.StatusNotFound, "")
+				return
+			}
+			w.Header().Set("x-ms-blob-type", string(BlobTypePage))
+			w.Header().Set("Content-Length", fmt.Sprint(s.size))
+			w.WriteHeader(http.StatusOK)
+		case r.Method == "PUT" && r.URL.Query().Get("comp") == "":
+			s.exists = true
+			fmt.Sscan(r.Header.Get("x-ms-blob-content-length"), &s.size)
+			writeOfflineResponse(w, http.StatusCreated, "")
+		case r.Method == "PUT" && r.URL.Query().Get("comp") == "page":
+			ioutil.ReadAll(r.Body)
+			s.written = append(s.written, r.Header.Get("x-ms-range"))
+			writeOfflineResponse(w, http.StatusCreated, "")
+		case r.Method == "GET" && r.URL.Query().Get("comp") == "pagelist":
+			var body bytes.Buffer
+			body.WriteString("<PageList>")
+			for _, pr := range s.ranges {
+				fmt.Fprintf(&body, "<PageRange><Start>%d</Start><End>%d</End></PageRange>", pr.Start, pr.End)
+			}
+			body.WriteString("</PageList>")
+			writeOfflineResponse(w, http.StatusOK, body.String())
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+		}
+	}
+}
+
+func TestUploadVHDSkipsEmptyPagesOffline(t *testing.T) {
+	server := &pageBlobServer{}
+	cli := newOfflineClient(t, blobServiceName, server.handle(t)).GetBlobService()
+
+	data := make([]byte, 4*pageSize)
+	copy(data[0:], strings.Repeat("a", pageSize))
+	copy(data[3*pageSize:], "b")
+	vhd := fixedVHD(data)
+
+	var progress []int64
+	err := cli.UploadVHD("vhds", "disk.vhd", bytes.NewReader(vhd), int64(len(vhd)), UploadVHDOptions{
+		Progress: func(processed, total int64) {
+			if total != int64(len(vhd)) {
+				t.Errorf("Expected total of %d bytes, got %d", len(vhd), total)
+			}
+			progress = append(progress, processed)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(server.written)
+	expected := []string{"bytes=0-511", "bytes=1536-2559"}
+	if strings.Join(server.written, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected pages %v to be written, got %v", expected, server.written)
+	}
+	if server.size != int64(len(vhd)) {
+		t.Errorf("Expected page blob of %d bytes, got %d", len(vhd), server.size)
+	}
+	if len(progress) == 0 || progress[len(progress)-1] != int64(len(vhd)) {
+		t.Errorf("Expected progress to reach %d bytes, got %v", len(vhd), progress)
+	}
+}
+
+func TestUploadVHDResumeOffline(t *testing.T) {
+	data := make([]byte, 4*pageSize)
+	copy(data, strings.Repeat("a", 2*pageSize))
+	vhd := fixedVHD(data)
+
+	server := &pageBlobServer{
+		exists: true,
+		size:   int64(len(vhd)),
+		ranges: []PageRange{{Start: 0, End: 2*pageSize - 1}},
+	}
+	cli := newOfflineClient(t, blobServiceName, server.handle(t)).GetBlobService()
+
+	if err := cli.UploadVHD("vhds", "disk.vhd", bytes.NewReader(vhd), int64(len(vhd)), UploadVHDOptions{Resume: true}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"bytes=2048-2559"}; strings.Join(server.written, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected pages %v to be written, got %v", expected, server.written)
+	}
+}
+
+func TestValidateVHDFooterOffline(t *testing.T) {
+	vhd := fixedVHD(make([]byte, 2*pageSize))
+	if err := validateVHDFooter(bytes.NewReader(vhd), int64(len(vhd))); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := append([]byte{}, vhd...)
+	corrupt[len(corrupt)-vhdFooterSize+20]++
+	if err := validateVHDFooter(bytes.NewReader(corrupt), int64(len(corrupt))); err != ErrVHDInvalidFooter {
+		t.Errorf("Expected ErrVHDInvalidFooter for a bad checksum, got %v", err)
+	}
+
+	dynamic := fixedVHD(make([]byte, 2*pageSize))
+	footer := dynamic[len(dynamic)-vhdFooterSize:]
+	binary.BigEndian.PutUint32(footer[60:64], 3)
+	binary.BigEndian.PutUint32(footer[64:68], binary.BigEndian.Uint32(footer[64:68])-1)
+	if err := validateVHDFooter(bytes.NewReader(dynamic), int64(len(dynamic))); err != ErrVHDNotFixed {
+		t.Errorf("Expected ErrVHDNotFixed, got %v", err)
+	}
+
+	if err := validateVHDFooter(bytes.NewReader(vhd[:1000]), 1000); err == nil {
+		t.Error("Expected unaligned VHD to be rejected")
+	}
+
+	if err := validateVHDFooter(bytes.NewReader(vhd[:2*pageSize]), int64(len(vhd))); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for a VHD shorter than its size, got %v", err)
+	}
+}